@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"go-progira/internal/application/bot/clients"
 	"go-progira/internal/application/bot/processing"
+	"go-progira/internal/tracing"
 	"go-progira/pkg"
 	"go-progira/pkg/config"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -18,23 +23,97 @@ func main() {
 		return
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "bot", appConfig.OTLPEndpoint)
+	if err != nil {
+		slog.Error("Failed to set up tracing",
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush spans",
+				slog.String("error", err.Error()))
+		}
+	}()
+
 	tgClient := clients.NewTelegramClient("https", appConfig.TgBotHost, appConfig.TgAPIToken)
 	slog.Info("Telegram client created",
 		slog.String("host", appConfig.TgBotHost))
 
-	scrapClient := clients.NewScrapperClient("http", appConfig.ScrapperHost)
+	usesGRPC := appConfig.ScrapperAPI == config.TransportGRPC ||
+		appConfig.BotTransport == config.TransportGRPC || appConfig.BotFallbackTransport == config.TransportGRPC
+
+	var grpcClient *clients.GRPCScrapperClient
+
+	if usesGRPC {
+		grpcClient, err = clients.NewGRPCScrapperClient(appConfig.ScrapperGRPCHost, appConfig.ServiceSecret)
+		if err != nil {
+			slog.Error("Failed to create scrapper gRPC client",
+				slog.String("error", err.Error()))
+
+			return
+		}
+
+		defer grpcClient.Close()
+	}
+
+	var scrapClient clients.HTTPScrapperClient
+
+	if appConfig.ScrapperAPI == config.TransportGRPC {
+		scrapClient = grpcClient
+	} else {
+		httpClient := clients.NewScrapperClient("http", appConfig.ScrapperHost, appConfig.ServiceSecret)
+		scrapClient = &httpClient
+	}
+
 	slog.Info("Scrapper client created",
-		slog.String("host", appConfig.ScrapperHost))
+		slog.String("api", appConfig.ScrapperAPI),
+		slog.String("host", appConfig.ScrapperHost),
+		slog.String("grpc host", appConfig.ScrapperGRPCHost))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	server := processing.NewServer(&tgClient)
+	manager := processing.NewManager(&tgClient, scrapClient)
+
+	server.Probes.AddCheck("telegram", manager.Polls.Check(appConfig.TgPollStaleAfter))
+	server.Probes.AddCheck("scrapper", scrapClient.Ping)
 
 	slog.Info("Bot server created")
 
 	server.Start(&appConfig)
 
-	manager := processing.NewManager(&tgClient, &scrapClient)
+	// Updates may come over Kafka either always or only while the scrapper falls back to it.
+	if appConfig.BotTransport == config.TransportKafka || appConfig.BotFallbackTransport == config.TransportKafka {
+		consumer := processing.NewUpdatesConsumer(&appConfig, server.DeliverUpdate)
+		defer consumer.Close()
+
+		slog.Info("Consuming updates",
+			slog.String("topic", appConfig.KafkaUpdatesTopic),
+			slog.String("group", appConfig.KafkaConsumerGroup))
+
+		go consumer.Run(ctx)
+	}
+
+	// Updates may be pushed over gRPC either always or only while the scrapper falls back to it.
+	if appConfig.BotTransport == config.TransportGRPC || appConfig.BotFallbackTransport == config.TransportGRPC {
+		go processing.NewUpdatesStream(grpcClient, server.DeliverUpdate).Run(ctx)
+	}
 
 	slog.Info("Manager created")
 
-	manager.Start()
+	manager.Start(ctx)
+
+	slog.Info("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Bot was not stopped cleanly",
+			slog.String("error", err.Error()))
+	}
 }
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"go-progira/internal/application/scrapper"
 	repository "go-progira/internal/repository/sql_database"
+	"go-progira/internal/tracing"
 	"go-progira/pkg"
 	"go-progira/pkg/config"
 	"log/slog"
@@ -21,6 +23,21 @@ func main() {
 		return
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "scrapper", appConfig.OTLPEndpoint)
+	if err != nil {
+		slog.Error("Failed to set up tracing",
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush spans",
+				slog.String("error", err.Error()))
+		}
+	}()
+
 	storage, err := repository.NewLinkService(appConfig.LinkService, appConfig.DatabaseURL)
 	if err != nil {
 		slog.Error(err.Error())
@@ -28,16 +45,39 @@ func main() {
 		return
 	}
 
-	botClient := scrapper.NewBotClient("http", appConfig.BotHost, "/updates")
+	updates := scrapper.NewGRPCBotClient(appConfig.BotRequestTimeout)
+
+	botClient, err := scrapper.NewBotTransport(&appConfig, updates)
+	if err != nil {
+		slog.Error(err.Error(),
+			slog.String("transport", appConfig.BotTransport))
+
+		return
+	}
+
 	scr := scrapper.NewServer(storage, botClient)
+	scr.Updates = updates
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	slog.Info("Going to start scrapper server",
-		slog.Int("Batch", appConfig.Batch))
+		slog.Int("Batch", appConfig.Batch),
+		slog.String("bot transport", appConfig.BotTransport))
 	scr.Start(&appConfig)
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	select {
+	case <-ctx.Done():
+	case <-scr.Errors():
+	}
 
 	slog.Info("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := scr.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Scrapper was not stopped cleanly",
+			slog.String("error", err.Error()))
+	}
 }
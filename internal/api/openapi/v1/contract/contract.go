@@ -0,0 +1,96 @@
+// Package contract checks that HTTP handlers accept and answer what the OpenAPI spec describes.
+package contract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// registerDecoders makes OPML files checked like the other text files the API sends.
+var registerDecoders sync.Once
+
+// Validator serves requests with a handler and validates both the request and the response against the spec.
+type Validator struct {
+	router routers.Router
+}
+
+// NewValidator loads the spec at path. Servers of the spec are dropped, so requests to any host match.
+func NewValidator(path string) (*Validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading spec: %w", err)
+	}
+
+	doc.Servers = nil
+
+	registerDecoders.Do(func() {
+		openapi3filter.RegisterBodyDecoder("text/x-opml", openapi3filter.FileBodyDecoder)
+	})
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("building router: %w", err)
+	}
+
+	return &Validator{router: router}, nil
+}
+
+// Serve validates req, serves it with handler and validates the response.
+// The recorded response is returned even if it doesn't match the spec.
+func (v *Validator) Serve(handler http.Handler, req *http.Request) (*httptest.ResponseRecorder, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s is not in the spec: %w", req.Method, req.URL.Path, err)
+	}
+
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	errRequest := openapi3filter.ValidateRequest(req.Context(), input)
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	errResponse := openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options:                options,
+	})
+
+	switch {
+	case errRequest != nil:
+		return rec, fmt.Errorf("request doesn't match the spec: %w", errRequest)
+	case errResponse != nil:
+		return rec, fmt.Errorf("response %d doesn't match the spec: %w", rec.Code, errResponse)
+	default:
+		return rec, nil
+	}
+}
@@ -0,0 +1,684 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: api/proto/v1/service.proto
+
+package protov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ScrapperService_RegisterChat_FullMethodName  = "/api.proto.v1.ScrapperService/RegisterChat"
+	ScrapperService_DeleteChat_FullMethodName    = "/api.proto.v1.ScrapperService/DeleteChat"
+	ScrapperService_ListLinks_FullMethodName     = "/api.proto.v1.ScrapperService/ListLinks"
+	ScrapperService_AddLink_FullMethodName       = "/api.proto.v1.ScrapperService/AddLink"
+	ScrapperService_RemoveLink_FullMethodName    = "/api.proto.v1.ScrapperService/RemoveLink"
+	ScrapperService_RefreshLinks_FullMethodName  = "/api.proto.v1.ScrapperService/RefreshLinks"
+	ScrapperService_EnableLink_FullMethodName    = "/api.proto.v1.ScrapperService/EnableLink"
+	ScrapperService_UpdateLink_FullMethodName    = "/api.proto.v1.ScrapperService/UpdateLink"
+	ScrapperService_ExportLinks_FullMethodName   = "/api.proto.v1.ScrapperService/ExportLinks"
+	ScrapperService_ImportLinks_FullMethodName   = "/api.proto.v1.ScrapperService/ImportLinks"
+	ScrapperService_DeleteTag_FullMethodName     = "/api.proto.v1.ScrapperService/DeleteTag"
+	ScrapperService_ListTags_FullMethodName      = "/api.proto.v1.ScrapperService/ListTags"
+	ScrapperService_RenameTag_FullMethodName     = "/api.proto.v1.ScrapperService/RenameTag"
+	ScrapperService_MergeTags_FullMethodName     = "/api.proto.v1.ScrapperService/MergeTags"
+	ScrapperService_StreamUpdates_FullMethodName = "/api.proto.v1.ScrapperService/StreamUpdates"
+)
+
+// ScrapperServiceClient is the client API for ScrapperService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ScrapperService tracks links of Telegram chats and pushes their updates to the bot.
+//
+// Calls are signed like the HTTP requests: x-timestamp metadata holds the unix time the call
+// was made at and x-signature the hex HMAC-SHA256 of the timestamp, full method name and,
+// for unary calls, the deterministically marshaled request. Errors carry a google.rpc.ErrorInfo
+// with the same codes the HTTP API uses.
+type ScrapperServiceClient interface {
+	RegisterChat(ctx context.Context, in *RegisterChatRequest, opts ...grpc.CallOption) (*RegisterChatResponse, error)
+	DeleteChat(ctx context.Context, in *DeleteChatRequest, opts ...grpc.CallOption) (*DeleteChatResponse, error)
+	// ListLinks returns the links of the chat, only the ones with any of the tags if they are set.
+	ListLinks(ctx context.Context, in *ListLinksRequest, opts ...grpc.CallOption) (*ListLinksResponse, error)
+	AddLink(ctx context.Context, in *AddLinkRequest, opts ...grpc.CallOption) (*AddLinkResponse, error)
+	RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*RemoveLinkResponse, error)
+	// RefreshLinks checks a link of the chat right now, or all its links if link is empty.
+	RefreshLinks(ctx context.Context, in *RefreshLinksRequest, opts ...grpc.CallOption) (*RefreshLinksResponse, error)
+	// EnableLink makes a broken link of the chat tracked again.
+	EnableLink(ctx context.Context, in *EnableLinkRequest, opts ...grpc.CallOption) (*EnableLinkResponse, error)
+	// UpdateLink adds and removes tags and filters of a tracked link, keeping its history.
+	UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*UpdateLinkResponse, error)
+	// ExportLinks encodes the links of the chat with their tags and filters as a file.
+	ExportLinks(ctx context.Context, in *ExportLinksRequest, opts ...grpc.CallOption) (*ExportLinksResponse, error)
+	// ImportLinks adds the links of a file made by ExportLinks and reports what happened to every row.
+	ImportLinks(ctx context.Context, in *ImportLinksRequest, opts ...grpc.CallOption) (*ImportLinksResponse, error)
+	// DeleteTag removes the tag from every link of the chat.
+	DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*DeleteTagResponse, error)
+	// ListTags returns the tags of the chat with the number of their links, the most used first.
+	ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error)
+	// RenameTag gives the tag a name the chat doesn't use yet.
+	RenameTag(ctx context.Context, in *RenameTagRequest, opts ...grpc.CallOption) (*RenameTagResponse, error)
+	// MergeTags moves the links of the tag to another tag of the chat.
+	MergeTags(ctx context.Context, in *MergeTagsRequest, opts ...grpc.CallOption) (*MergeTagsResponse, error)
+	// StreamUpdates is opened by the bot. The scrapper pushes link updates over it
+	// and the bot answers every update with the result of its delivery.
+	StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamUpdatesRequest, StreamUpdatesResponse], error)
+}
+
+type scrapperServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScrapperServiceClient(cc grpc.ClientConnInterface) ScrapperServiceClient {
+	return &scrapperServiceClient{cc}
+}
+
+func (c *scrapperServiceClient) RegisterChat(ctx context.Context, in *RegisterChatRequest, opts ...grpc.CallOption) (*RegisterChatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterChatResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_RegisterChat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) DeleteChat(ctx context.Context, in *DeleteChatRequest, opts ...grpc.CallOption) (*DeleteChatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteChatResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_DeleteChat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) ListLinks(ctx context.Context, in *ListLinksRequest, opts ...grpc.CallOption) (*ListLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLinksResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_ListLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) AddLink(ctx context.Context, in *AddLinkRequest, opts ...grpc.CallOption) (*AddLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddLinkResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_AddLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*RemoveLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveLinkResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_RemoveLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) RefreshLinks(ctx context.Context, in *RefreshLinksRequest, opts ...grpc.CallOption) (*RefreshLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshLinksResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_RefreshLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) EnableLink(ctx context.Context, in *EnableLinkRequest, opts ...grpc.CallOption) (*EnableLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnableLinkResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_EnableLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*UpdateLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLinkResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_UpdateLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) ExportLinks(ctx context.Context, in *ExportLinksRequest, opts ...grpc.CallOption) (*ExportLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportLinksResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_ExportLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) ImportLinks(ctx context.Context, in *ImportLinksRequest, opts ...grpc.CallOption) (*ImportLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportLinksResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_ImportLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*DeleteTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTagResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_DeleteTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTagsResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_ListTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) RenameTag(ctx context.Context, in *RenameTagRequest, opts ...grpc.CallOption) (*RenameTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenameTagResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_RenameTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) MergeTags(ctx context.Context, in *MergeTagsRequest, opts ...grpc.CallOption) (*MergeTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MergeTagsResponse)
+	err := c.cc.Invoke(ctx, ScrapperService_MergeTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scrapperServiceClient) StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamUpdatesRequest, StreamUpdatesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ScrapperService_ServiceDesc.Streams[0], ScrapperService_StreamUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUpdatesRequest, StreamUpdatesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ScrapperService_StreamUpdatesClient = grpc.BidiStreamingClient[StreamUpdatesRequest, StreamUpdatesResponse]
+
+// ScrapperServiceServer is the server API for ScrapperService service.
+// All implementations should embed UnimplementedScrapperServiceServer
+// for forward compatibility.
+//
+// ScrapperService tracks links of Telegram chats and pushes their updates to the bot.
+//
+// Calls are signed like the HTTP requests: x-timestamp metadata holds the unix time the call
+// was made at and x-signature the hex HMAC-SHA256 of the timestamp, full method name and,
+// for unary calls, the deterministically marshaled request. Errors carry a google.rpc.ErrorInfo
+// with the same codes the HTTP API uses.
+type ScrapperServiceServer interface {
+	RegisterChat(context.Context, *RegisterChatRequest) (*RegisterChatResponse, error)
+	DeleteChat(context.Context, *DeleteChatRequest) (*DeleteChatResponse, error)
+	// ListLinks returns the links of the chat, only the ones with any of the tags if they are set.
+	ListLinks(context.Context, *ListLinksRequest) (*ListLinksResponse, error)
+	AddLink(context.Context, *AddLinkRequest) (*AddLinkResponse, error)
+	RemoveLink(context.Context, *RemoveLinkRequest) (*RemoveLinkResponse, error)
+	// RefreshLinks checks a link of the chat right now, or all its links if link is empty.
+	RefreshLinks(context.Context, *RefreshLinksRequest) (*RefreshLinksResponse, error)
+	// EnableLink makes a broken link of the chat tracked again.
+	EnableLink(context.Context, *EnableLinkRequest) (*EnableLinkResponse, error)
+	// UpdateLink adds and removes tags and filters of a tracked link, keeping its history.
+	UpdateLink(context.Context, *UpdateLinkRequest) (*UpdateLinkResponse, error)
+	// ExportLinks encodes the links of the chat with their tags and filters as a file.
+	ExportLinks(context.Context, *ExportLinksRequest) (*ExportLinksResponse, error)
+	// ImportLinks adds the links of a file made by ExportLinks and reports what happened to every row.
+	ImportLinks(context.Context, *ImportLinksRequest) (*ImportLinksResponse, error)
+	// DeleteTag removes the tag from every link of the chat.
+	DeleteTag(context.Context, *DeleteTagRequest) (*DeleteTagResponse, error)
+	// ListTags returns the tags of the chat with the number of their links, the most used first.
+	ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error)
+	// RenameTag gives the tag a name the chat doesn't use yet.
+	RenameTag(context.Context, *RenameTagRequest) (*RenameTagResponse, error)
+	// MergeTags moves the links of the tag to another tag of the chat.
+	MergeTags(context.Context, *MergeTagsRequest) (*MergeTagsResponse, error)
+	// StreamUpdates is opened by the bot. The scrapper pushes link updates over it
+	// and the bot answers every update with the result of its delivery.
+	StreamUpdates(grpc.BidiStreamingServer[StreamUpdatesRequest, StreamUpdatesResponse]) error
+}
+
+// UnimplementedScrapperServiceServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedScrapperServiceServer struct{}
+
+func (UnimplementedScrapperServiceServer) RegisterChat(context.Context, *RegisterChatRequest) (*RegisterChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterChat not implemented")
+}
+func (UnimplementedScrapperServiceServer) DeleteChat(context.Context, *DeleteChatRequest) (*DeleteChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChat not implemented")
+}
+func (UnimplementedScrapperServiceServer) ListLinks(context.Context, *ListLinksRequest) (*ListLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLinks not implemented")
+}
+func (UnimplementedScrapperServiceServer) AddLink(context.Context, *AddLinkRequest) (*AddLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddLink not implemented")
+}
+func (UnimplementedScrapperServiceServer) RemoveLink(context.Context, *RemoveLinkRequest) (*RemoveLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveLink not implemented")
+}
+func (UnimplementedScrapperServiceServer) RefreshLinks(context.Context, *RefreshLinksRequest) (*RefreshLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshLinks not implemented")
+}
+func (UnimplementedScrapperServiceServer) EnableLink(context.Context, *EnableLinkRequest) (*EnableLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableLink not implemented")
+}
+func (UnimplementedScrapperServiceServer) UpdateLink(context.Context, *UpdateLinkRequest) (*UpdateLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLink not implemented")
+}
+func (UnimplementedScrapperServiceServer) ExportLinks(context.Context, *ExportLinksRequest) (*ExportLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportLinks not implemented")
+}
+func (UnimplementedScrapperServiceServer) ImportLinks(context.Context, *ImportLinksRequest) (*ImportLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportLinks not implemented")
+}
+func (UnimplementedScrapperServiceServer) DeleteTag(context.Context, *DeleteTagRequest) (*DeleteTagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTag not implemented")
+}
+func (UnimplementedScrapperServiceServer) ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTags not implemented")
+}
+func (UnimplementedScrapperServiceServer) RenameTag(context.Context, *RenameTagRequest) (*RenameTagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameTag not implemented")
+}
+func (UnimplementedScrapperServiceServer) MergeTags(context.Context, *MergeTagsRequest) (*MergeTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeTags not implemented")
+}
+func (UnimplementedScrapperServiceServer) StreamUpdates(grpc.BidiStreamingServer[StreamUpdatesRequest, StreamUpdatesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedScrapperServiceServer) testEmbeddedByValue() {}
+
+// UnsafeScrapperServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScrapperServiceServer will
+// result in compilation errors.
+type UnsafeScrapperServiceServer interface {
+	mustEmbedUnimplementedScrapperServiceServer()
+}
+
+func RegisterScrapperServiceServer(s grpc.ServiceRegistrar, srv ScrapperServiceServer) {
+	// If the following call pancis, it indicates UnimplementedScrapperServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ScrapperService_ServiceDesc, srv)
+}
+
+func _ScrapperService_RegisterChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).RegisterChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_RegisterChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).RegisterChat(ctx, req.(*RegisterChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_DeleteChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).DeleteChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_DeleteChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).DeleteChat(ctx, req.(*DeleteChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_ListLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).ListLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_ListLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).ListLinks(ctx, req.(*ListLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_AddLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).AddLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_AddLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).AddLink(ctx, req.(*AddLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_RemoveLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).RemoveLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_RemoveLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).RemoveLink(ctx, req.(*RemoveLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_RefreshLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).RefreshLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_RefreshLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).RefreshLinks(ctx, req.(*RefreshLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_EnableLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).EnableLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_EnableLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).EnableLink(ctx, req.(*EnableLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_UpdateLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).UpdateLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_UpdateLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).UpdateLink(ctx, req.(*UpdateLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_ExportLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).ExportLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_ExportLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).ExportLinks(ctx, req.(*ExportLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_ImportLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).ImportLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_ImportLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).ImportLinks(ctx, req.(*ImportLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_DeleteTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).DeleteTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_DeleteTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).DeleteTag(ctx, req.(*DeleteTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_ListTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).ListTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_ListTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).ListTags(ctx, req.(*ListTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_RenameTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).RenameTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_RenameTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).RenameTag(ctx, req.(*RenameTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_MergeTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScrapperServiceServer).MergeTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScrapperService_MergeTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScrapperServiceServer).MergeTags(ctx, req.(*MergeTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScrapperService_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ScrapperServiceServer).StreamUpdates(&grpc.GenericServerStream[StreamUpdatesRequest, StreamUpdatesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ScrapperService_StreamUpdatesServer = grpc.BidiStreamingServer[StreamUpdatesRequest, StreamUpdatesResponse]
+
+// ScrapperService_ServiceDesc is the grpc.ServiceDesc for ScrapperService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ScrapperService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.proto.v1.ScrapperService",
+	HandlerType: (*ScrapperServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterChat",
+			Handler:    _ScrapperService_RegisterChat_Handler,
+		},
+		{
+			MethodName: "DeleteChat",
+			Handler:    _ScrapperService_DeleteChat_Handler,
+		},
+		{
+			MethodName: "ListLinks",
+			Handler:    _ScrapperService_ListLinks_Handler,
+		},
+		{
+			MethodName: "AddLink",
+			Handler:    _ScrapperService_AddLink_Handler,
+		},
+		{
+			MethodName: "RemoveLink",
+			Handler:    _ScrapperService_RemoveLink_Handler,
+		},
+		{
+			MethodName: "RefreshLinks",
+			Handler:    _ScrapperService_RefreshLinks_Handler,
+		},
+		{
+			MethodName: "EnableLink",
+			Handler:    _ScrapperService_EnableLink_Handler,
+		},
+		{
+			MethodName: "UpdateLink",
+			Handler:    _ScrapperService_UpdateLink_Handler,
+		},
+		{
+			MethodName: "ExportLinks",
+			Handler:    _ScrapperService_ExportLinks_Handler,
+		},
+		{
+			MethodName: "ImportLinks",
+			Handler:    _ScrapperService_ImportLinks_Handler,
+		},
+		{
+			MethodName: "DeleteTag",
+			Handler:    _ScrapperService_DeleteTag_Handler,
+		},
+		{
+			MethodName: "ListTags",
+			Handler:    _ScrapperService_ListTags_Handler,
+		},
+		{
+			MethodName: "RenameTag",
+			Handler:    _ScrapperService_RenameTag_Handler,
+		},
+		{
+			MethodName: "MergeTags",
+			Handler:    _ScrapperService_MergeTags_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _ScrapperService_StreamUpdates_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/proto/v1/service.proto",
+}
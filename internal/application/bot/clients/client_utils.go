@@ -2,20 +2,34 @@ package clients
 
 import (
 	"bytes"
+	"context"
+	"go-progira/internal/tracing"
 	"go-progira/pkg/e"
 	"log/slog"
 	"net/http"
 	"net/url"
 )
 
-func DoRequest(client http.Client, method, scheme, host, path string, q url.Values, body []byte, isJSON bool) (*http.Response, error) {
+func DoRequest(ctx context.Context, client http.Client, method, scheme, host, path string, q url.Values, body []byte,
+	isJSON bool) (*http.Response, error) {
+	var contentType string
+	if isJSON {
+		contentType = "application/json"
+	}
+
+	return doRequest(ctx, client, method, scheme, host, path, q, body, contentType)
+}
+
+// doRequest is DoRequest with any content type of the body, an empty one is not sent.
+func doRequest(ctx context.Context, client http.Client, method, scheme, host, path string, q url.Values, body []byte,
+	contentType string) (*http.Response, error) {
 	u := url.URL{
 		Scheme: scheme,
 		Host:   host,
 		Path:   path,
 	}
 
-	req, errMakeReq := http.NewRequest(method, u.String(), bytes.NewBuffer(body))
+	req, errMakeReq := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewBuffer(body))
 	if errMakeReq != nil {
 		slog.Error(
 			e.ErrMakeRequest.Error(),
@@ -28,13 +42,16 @@ func DoRequest(client http.Client, method, scheme, host, path string, q url.Valu
 		return nil, e.ErrMakeRequest
 	}
 
-	if isJSON {
-		req.Header.Set("Content-Type", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	req.URL.RawQuery = q.Encode()
 
+	req, span := tracing.StartRequest(req, method+" "+host)
+
 	resp, errDoReq := client.Do(req)
+	tracing.FinishRequest(span, resp, errDoReq)
 	if errDoReq != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
@@ -1,6 +1,7 @@
 package clients_test
 
 import (
+	"context"
 	"go-progira/internal/application/bot/clients"
 	"go-progira/pkg/e"
 	"io"
@@ -33,7 +34,7 @@ func TestDoRequest_Success(t *testing.T) {
 
 	parsedURL, _ := url.Parse(server.URL)
 
-	resp, err := clients.DoRequest(client, http.MethodGet, parsedURL.Scheme, parsedURL.Host, "/",
+	resp, err := clients.DoRequest(context.Background(), client, http.MethodGet, parsedURL.Scheme, parsedURL.Host, "/",
 		nil, nil, false)
 	assert.NoError(t, err)
 
@@ -49,7 +50,7 @@ func TestDoRequest_Success(t *testing.T) {
 func TestDoRequest_BadURL(t *testing.T) {
 	client := http.Client{}
 
-	resp, err := clients.DoRequest(client, http.MethodGet, "http", ":", "/",
+	resp, err := clients.DoRequest(context.Background(), client, http.MethodGet, "http", ":", "/",
 		nil, nil, false)
 	assert.Error(t, err)
 
@@ -70,7 +71,7 @@ func TestDoRequest_ClientError(t *testing.T) {
 		},
 	}
 
-	resp, err := clients.DoRequest(brokenClient, http.MethodGet, "http", "localhost", "/",
+	resp, err := clients.DoRequest(context.Background(), brokenClient, http.MethodGet, "http", "localhost", "/",
 		nil, nil, false)
 	assert.Error(t, err)
 
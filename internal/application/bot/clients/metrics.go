@@ -0,0 +1,24 @@
+package clients
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var messagesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "bot_telegram_messages_sent_total",
+	Help: "Messages sent to Telegram, by result: ok or failed.",
+}, []string{"result"})
+
+// countSent counts a message as ok only when Telegram accepted it, err is returned as is.
+func countSent(err error) error {
+	if err != nil {
+		messagesSent.WithLabelValues("failed").Inc()
+
+		return err
+	}
+
+	messagesSent.WithLabelValues("ok").Inc()
+
+	return nil
+}
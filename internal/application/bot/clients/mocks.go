@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"go-progira/internal/domain/types/scrappertypes"
 	"go-progira/internal/domain/types/telegramtypes"
 
@@ -11,63 +12,155 @@ type MockTgClient struct {
 	mock.Mock
 }
 
-func (m *MockTgClient) Updates(offset, limit int) ([]byte, error) {
+func (m *MockTgClient) Updates(_ context.Context, offset, limit int) ([]byte, error) {
 	args := m.Called(offset, limit)
 
 	return args.Get(0).([]byte), args.Error(1)
 }
 
-func (m *MockTgClient) SendMessage(id int, msg string) error {
+func (m *MockTgClient) SendMessage(_ context.Context, id int, msg string) error {
 	args := m.Called(id, msg)
 
 	return args.Error(0)
 }
 
-func (m *MockTgClient) SetBotCommands(commands []telegramtypes.BotCommand) error {
+func (m *MockTgClient) SetBotCommands(_ context.Context, commands []telegramtypes.BotCommand) error {
 	args := m.Called(commands)
 
 	return args.Error(0)
 }
 
+func (m *MockTgClient) SendKeyboard(_ context.Context, id int, msg string, keyboard telegramtypes.InlineKeyboardMarkup) error {
+	args := m.Called(id, msg, keyboard)
+
+	return args.Error(0)
+}
+
+func (m *MockTgClient) EditMessage(_ context.Context, id, messageID int, msg string,
+	keyboard telegramtypes.InlineKeyboardMarkup) error {
+	args := m.Called(id, messageID, msg, keyboard)
+
+	return args.Error(0)
+}
+
+func (m *MockTgClient) AnswerCallback(_ context.Context, callbackID, text string) error {
+	args := m.Called(callbackID, text)
+
+	return args.Error(0)
+}
+
+func (m *MockTgClient) SendDocument(_ context.Context, chatID int, fileName string, data []byte, caption string) error {
+	args := m.Called(chatID, fileName, data, caption)
+
+	return args.Error(0)
+}
+
+func (m *MockTgClient) DownloadFile(_ context.Context, fileID string) ([]byte, error) {
+	args := m.Called(fileID)
+
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 type MockScrapClient struct {
 	mock.Mock
 }
 
-func (m *MockScrapClient) RegisterChat(chatID int64) {
+func (m *MockScrapClient) RegisterChat(_ context.Context, chatID int64) {
 	m.Called(chatID)
 }
 
-func (m *MockScrapClient) DeleteChat(chatID int64) {
+func (m *MockScrapClient) DeleteChat(_ context.Context, chatID int64) {
 	m.Called(chatID)
 }
 
-func (m *MockScrapClient) AddLink(chatID int64, request scrappertypes.AddLinkRequest) error {
+func (m *MockScrapClient) AddLink(_ context.Context, chatID int64, request scrappertypes.AddLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
 	args := m.Called(chatID, request)
 
-	return args.Error(0)
+	return args.Get(0).(*scrappertypes.LinkResponse), args.Error(1)
 }
 
-func (m *MockScrapClient) GetLinks(chatID int64) (*scrappertypes.ListLinksResponse, error) {
-	args := m.Called(chatID)
+func (m *MockScrapClient) GetLinks(_ context.Context, chatID int64, request scrappertypes.GetLinksRequest) (
+	*scrappertypes.ListLinksResponse, error) {
+	args := m.Called(chatID, request)
 
 	return args.Get(0).(*scrappertypes.ListLinksResponse), args.Error(1)
 }
 
-func (m *MockScrapClient) RemoveLink(chatID int64, request scrappertypes.RemoveLinkRequest) error {
+func (m *MockScrapClient) RemoveLink(_ context.Context, chatID int64, request scrappertypes.RemoveLinkRequest) error {
 	args := m.Called(chatID, request)
 
 	return args.Error(0)
 }
 
-func (m *MockScrapClient) GetLinksByTag(chatID int64, request scrappertypes.GetLinksByTagsRequest) (
+func (m *MockScrapClient) GetLinksByTag(_ context.Context, chatID int64, request scrappertypes.GetLinksByTagsRequest) (
 	*scrappertypes.ListLinksResponse, error) {
 	args := m.Called(chatID, request)
 
 	return args.Get(0).(*scrappertypes.ListLinksResponse), args.Error(1)
 }
 
-func (m *MockScrapClient) DeleteTag(chatID int64, request scrappertypes.DeleteTagRequest) error {
+func (m *MockScrapClient) DeleteTag(_ context.Context, chatID int64, request scrappertypes.DeleteTagRequest) error {
 	args := m.Called(chatID, request)
 
 	return args.Error(0)
 }
+
+func (m *MockScrapClient) GetTags(_ context.Context, chatID int64) (*scrappertypes.ListTagsResponse, error) {
+	args := m.Called(chatID)
+
+	return args.Get(0).(*scrappertypes.ListTagsResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) RenameTag(_ context.Context, chatID int64, request scrappertypes.RenameTagRequest) (
+	*scrappertypes.TagResponse, error) {
+	args := m.Called(chatID, request)
+
+	return args.Get(0).(*scrappertypes.TagResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) MergeTags(_ context.Context, chatID int64, request scrappertypes.MergeTagsRequest) (
+	*scrappertypes.TagResponse, error) {
+	args := m.Called(chatID, request)
+
+	return args.Get(0).(*scrappertypes.TagResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) RefreshLinks(_ context.Context, chatID int64, request scrappertypes.RefreshRequest) (
+	*scrappertypes.RefreshResponse, error) {
+	args := m.Called(chatID, request)
+
+	return args.Get(0).(*scrappertypes.RefreshResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) EnableLink(_ context.Context, chatID int64, request scrappertypes.EnableLinkRequest) error {
+	args := m.Called(chatID, request)
+
+	return args.Error(0)
+}
+
+func (m *MockScrapClient) UpdateLink(_ context.Context, chatID int64, request scrappertypes.UpdateLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
+	args := m.Called(chatID, request)
+
+	return args.Get(0).(*scrappertypes.LinkResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) ExportLinks(_ context.Context, chatID int64, format string) ([]byte, error) {
+	args := m.Called(chatID, format)
+
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockScrapClient) ImportLinks(_ context.Context, chatID int64, request scrappertypes.ImportRequest) (
+	*scrappertypes.ImportResponse, error) {
+	args := m.Called(chatID, request)
+
+	return args.Get(0).(*scrappertypes.ImportResponse), args.Error(1)
+}
+
+func (m *MockScrapClient) Ping(_ context.Context) error {
+	args := m.Called()
+
+	return args.Error(0)
+}
@@ -1,9 +1,13 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
+	"go-progira/internal/auth"
 	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/tracing"
 	"go-progira/pkg/e"
 	"log/slog"
 	"net/http"
@@ -11,285 +15,430 @@ import (
 )
 
 type HTTPScrapperClient interface {
-	RegisterChat(id int64)
-	DeleteChat(id int64)
-	AddLink(chatID int64, request scrappertypes.AddLinkRequest) error
-	GetLinks(chatID int64) (*scrappertypes.ListLinksResponse, error)
-	RemoveLink(chatID int64, request scrappertypes.RemoveLinkRequest) error
-	GetLinksByTag(chatID int64, request scrappertypes.GetLinksByTagsRequest) (*scrappertypes.ListLinksResponse, error)
-	DeleteTag(chatID int64, request scrappertypes.DeleteTagRequest) error
+	RegisterChat(ctx context.Context, id int64)
+	DeleteChat(ctx context.Context, id int64)
+	AddLink(ctx context.Context, chatID int64, request scrappertypes.AddLinkRequest) (*scrappertypes.LinkResponse, error)
+	GetLinks(ctx context.Context, chatID int64, request scrappertypes.GetLinksRequest) (*scrappertypes.ListLinksResponse, error)
+	RemoveLink(ctx context.Context, chatID int64, request scrappertypes.RemoveLinkRequest) error
+	GetLinksByTag(ctx context.Context, chatID int64, request scrappertypes.GetLinksByTagsRequest) (*scrappertypes.ListLinksResponse, error)
+	DeleteTag(ctx context.Context, chatID int64, request scrappertypes.DeleteTagRequest) error
+	GetTags(ctx context.Context, chatID int64) (*scrappertypes.ListTagsResponse, error)
+	RenameTag(ctx context.Context, chatID int64, request scrappertypes.RenameTagRequest) (*scrappertypes.TagResponse, error)
+	MergeTags(ctx context.Context, chatID int64, request scrappertypes.MergeTagsRequest) (*scrappertypes.TagResponse, error)
+	RefreshLinks(ctx context.Context, chatID int64, request scrappertypes.RefreshRequest) (*scrappertypes.RefreshResponse, error)
+	EnableLink(ctx context.Context, chatID int64, request scrappertypes.EnableLinkRequest) error
+	UpdateLink(ctx context.Context, chatID int64, request scrappertypes.UpdateLinkRequest) (*scrappertypes.LinkResponse, error)
+	ExportLinks(ctx context.Context, chatID int64, format string) ([]byte, error)
+	ImportLinks(ctx context.Context, chatID int64, request scrappertypes.ImportRequest) (*scrappertypes.ImportResponse, error)
+	Ping(ctx context.Context) error
 }
 
+// ScrapperClient calls the scrapper with the client generated from its OpenAPI spec.
 type ScrapperClient struct {
+	api    *scrapperapi.ClientWithResponses
 	client http.Client
 	scheme string
 	host   string
 }
 
-func NewScrapperClient(scheme, host string) ScrapperClient {
+// NewScrapperClient returns the client that signs its requests with secret.
+func NewScrapperClient(scheme, host, secret string) ScrapperClient {
+	client := http.Client{Transport: auth.NewTransport(secret)}
+
 	return ScrapperClient{
-		client: http.Client{},
+		api: &scrapperapi.ClientWithResponses{ClientInterface: &scrapperapi.Client{
+			Server: scheme + "://" + host + "/",
+			Client: tracedDoer{client: &client},
+		}},
+		client: client,
 		scheme: scheme,
 		host:   host,
 	}
 }
 
-func (c *ScrapperClient) RegisterChat(id int64) {
-	c.doWithChat(http.MethodPost, id, "error registering chat: %s")
+// tracedDoer starts a client span for every request to the scrapper.
+type tracedDoer struct {
+	client *http.Client
 }
 
-func (c *ScrapperClient) DeleteChat(id int64) {
-	c.doWithChat(http.MethodDelete, id, "error deleting chat: %s")
+func (d tracedDoer) Do(req *http.Request) (*http.Response, error) {
+	req, span := tracing.StartRequest(req, req.Method+" "+req.URL.Host)
+
+	resp, err := d.client.Do(req)
+	tracing.FinishRequest(span, resp, err)
+
+	return resp, err
 }
 
-func (c *ScrapperClient) doWithChat(method string, id int64, debugMes string) {
-	u := fmt.Sprintf("/tg-chat/%d", id)
+func (c *ScrapperClient) RegisterChat(ctx context.Context, id int64) {
+	response, err := c.api.RegisterChatWithResponse(ctx, id)
+	logChatError("error registering chat", response, err)
+}
 
-	response, err := DoRequest(c.client, method, "http", c.host, u, url.Values{}, nil, false)
+func (c *ScrapperClient) DeleteChat(ctx context.Context, id int64) {
+	response, err := c.api.DeleteChatWithResponse(ctx, id)
+	logChatError("error deleting chat", response, err)
+}
+
+func logChatError(message string, response interface{ StatusCode() int }, err error) {
 	if err != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("message", debugMes),
+			slog.String("message", message),
+			slog.String("error", err.Error()),
 		)
-	}
 
-	if response == nil {
 		return
 	}
 
+	if response.StatusCode() != http.StatusOK {
+		slog.Error(message,
+			slog.Int("status code", response.StatusCode()))
+	}
+}
+
+// Ping asks the scrapper for liveness rather than readiness, so that an unready database
+// of the scrapper does not take the bot out of rotation too.
+func (c *ScrapperClient) Ping(ctx context.Context) error {
+	response, err := DoRequest(ctx, c.client, http.MethodGet, c.scheme, c.host, "/healthz", url.Values{}, nil, false)
+	if err != nil {
+		return err
+	}
+
 	errClose := response.Body.Close()
 	if errClose != nil {
 		slog.Error("Error closing response body" + errClose.Error())
 	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: scrapper answered %d", e.ErrUnhealthy, response.StatusCode)
+	}
+
+	return nil
 }
 
-func (c *ScrapperClient) GetLinks(chatID int64) (*scrappertypes.ListLinksResponse, error) {
-	u := "/links"
+func (c *ScrapperClient) GetLinks(ctx context.Context, chatID int64, request scrappertypes.GetLinksRequest) (
+	*scrappertypes.ListLinksResponse, error) {
+	params := &scrapperapi.GetLinksParams{TgChatId: chatID}
+	if request.Limit != 0 {
+		params.Limit = &request.Limit
+	}
 
-	q := url.Values{}
-	q.Add("Tg-Chat-Id", fmt.Sprintf("%d", chatID))
+	if request.Sort != "" {
+		sort := scrapperapi.GetLinksParamsSort(request.Sort)
+		params.Sort = &sort
+	}
 
-	response, errDoReq := DoRequest(c.client, http.MethodGet, c.scheme, c.host, u, q, nil, false)
-	if errDoReq != nil {
+	if request.Order != "" {
+		order := scrapperapi.GetLinksParamsOrder(request.Order)
+		params.Order = &order
+	}
+
+	if request.Cursor != "" {
+		params.Cursor = &request.Cursor
+	}
+
+	response, err := c.api.GetLinksWithResponse(ctx, params)
+	if err != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("error", errDoReq.Error()),
+			slog.String("error", err.Error()),
 		)
 
 		return nil, e.ErrDoRequest
 	}
 
-	var listResp scrappertypes.ListLinksResponse
-	if errDecode := json.NewDecoder(response.Body).Decode(&listResp); errDecode != nil {
-		slog.Error(
-			e.ErrDecodeJSONBody.Error(),
-			slog.String("error", errDecode.Error()),
-		)
+	return listResponse(response.StatusCode(), response.JSON200, response.Body)
+}
 
-		return nil, e.ErrDecodeJSONBody
+func (c *ScrapperClient) GetLinksByTag(ctx context.Context, chatID int64, request scrappertypes.GetLinksByTagsRequest) (
+	*scrappertypes.ListLinksResponse, error) {
+	params := &scrapperapi.GetLinksByTagsParams{TgChatId: chatID}
+	if len(request.Tags) != 0 {
+		params.Tag = &request.Tags
 	}
 
-	errClose := response.Body.Close()
-	if errClose != nil {
-		slog.Error("Error closing response body" + errClose.Error())
+	response, err := c.api.GetLinksByTagsWithResponse(ctx, params)
+	if err != nil {
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-		return nil, errClose
+		return nil, e.ErrDoRequest
 	}
 
-	return &listResp, nil
+	return listResponse(response.StatusCode(), response.JSON200, response.Body)
 }
 
-func (c *ScrapperClient) GetLinksByTag(chatID int64, request scrappertypes.GetLinksByTagsRequest) (
-	*scrappertypes.ListLinksResponse, error) {
-	u := "/tags"
-
-	q := url.Values{}
-	q.Add("Tg-Chat-Id", fmt.Sprintf("%d", chatID))
+func listResponse(status int, list *scrappertypes.ListLinksResponse, body []byte) (*scrappertypes.ListLinksResponse, error) {
+	switch {
+	case status != http.StatusOK:
+		return nil, apiError(body, e.ErrAPI)
+	case list == nil:
+		return nil, e.ErrDecodeJSONBody
+	default:
+		return list, nil
+	}
+}
 
-	body, err := json.Marshal(request)
+func (c *ScrapperClient) DeleteTag(ctx context.Context, chatID int64, request scrappertypes.DeleteTagRequest) error {
+	response, err := c.api.DeleteTagWithResponse(ctx, &scrapperapi.DeleteTagParams{TgChatId: chatID}, request)
 	if err != nil {
 		slog.Error(
-			e.ErrMarshalJSON.Error(),
-			slog.String("error", err.Error()))
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
+
+		return e.ErrDoRequest
+	}
 
-		return nil, e.ErrMarshalJSON
+	if response.StatusCode() != http.StatusOK {
+		return apiError(response.Body, e.ErrDeleteTag)
 	}
 
-	response, errDoReq := DoRequest(c.client, http.MethodGet, c.scheme, c.host, u, q, body, false)
-	if errDoReq != nil {
+	return nil
+}
+
+// GetTags returns the tags of the chat with the number of their links.
+func (c *ScrapperClient) GetTags(ctx context.Context, chatID int64) (*scrappertypes.ListTagsResponse, error) {
+	response, err := c.api.GetTagsWithResponse(ctx, &scrapperapi.GetTagsParams{TgChatId: chatID})
+	if err != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("error", errDoReq.Error()),
+			slog.String("error", err.Error()),
 		)
 
-		return nil, e.ErrDoRequest
+		return nil, e.ErrGetTags
 	}
 
-	var listResp scrappertypes.ListLinksResponse
-	if errDecode := json.NewDecoder(response.Body).Decode(&listResp); errDecode != nil {
-		slog.Error(
-			e.ErrDecodeJSONBody.Error(),
-			slog.String("error", errDecode.Error()),
-		)
-
+	switch {
+	case response.StatusCode() != http.StatusOK:
+		return nil, apiError(response.Body, e.ErrGetTags)
+	case response.JSON200 == nil:
 		return nil, e.ErrDecodeJSONBody
+	default:
+		return response.JSON200, nil
 	}
+}
 
-	errClose := response.Body.Close()
-	if errClose != nil {
-		slog.Error("Error closing response body" + errClose.Error())
+func (c *ScrapperClient) RenameTag(ctx context.Context, chatID int64, request scrappertypes.RenameTagRequest) (
+	*scrappertypes.TagResponse, error) {
+	response, err := c.api.RenameTagWithResponse(ctx, &scrapperapi.RenameTagParams{TgChatId: chatID}, request)
+	if err != nil {
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-		return &listResp, errClose
+		return nil, e.ErrRenameTag
 	}
 
-	return &listResp, nil
+	return tagResponse(response.StatusCode(), response.JSON200, response.Body)
 }
 
-func (c *ScrapperClient) DeleteTag(chatID int64, request scrappertypes.DeleteTagRequest) error {
-	u := "/tags"
-
-	q := url.Values{}
-	q.Add("Tg-Chat-Id", fmt.Sprintf("%d", chatID))
-
-	body, err := json.Marshal(request)
+func (c *ScrapperClient) MergeTags(ctx context.Context, chatID int64, request scrappertypes.MergeTagsRequest) (
+	*scrappertypes.TagResponse, error) {
+	response, err := c.api.MergeTagsWithResponse(ctx, &scrapperapi.MergeTagsParams{TgChatId: chatID}, request)
 	if err != nil {
 		slog.Error(
-			e.ErrMarshalJSON.Error(),
-			slog.String("error", err.Error()))
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-		return e.ErrMarshalJSON
+		return nil, e.ErrRenameTag
 	}
 
-	response, errDoReq := DoRequest(c.client, http.MethodDelete, c.scheme, c.host, u, q, body, false)
-	if errDoReq != nil {
+	return tagResponse(response.StatusCode(), response.JSON200, response.Body)
+}
+
+func tagResponse(status int, tag *scrappertypes.TagResponse, body []byte) (*scrappertypes.TagResponse, error) {
+	switch {
+	case status != http.StatusOK:
+		return nil, apiError(body, e.ErrRenameTag)
+	case tag == nil:
+		return nil, e.ErrDecodeJSONBody
+	default:
+		return tag, nil
+	}
+}
+
+func (c *ScrapperClient) AddLink(ctx context.Context, chatID int64, request scrappertypes.AddLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
+	response, err := c.api.AddLinkWithResponse(ctx, &scrapperapi.AddLinkParams{TgChatId: chatID}, request)
+	if err != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("error", errDoReq.Error()),
+			slog.String("error", err.Error()),
 		)
 
-		return e.ErrDoRequest
+		return nil, e.ErrAddLink
 	}
 
-	errClose := response.Body.Close()
-	if errClose != nil {
-		slog.Error("Error closing response body" + errClose.Error())
+	if response.StatusCode() != http.StatusOK {
+		return nil, apiError(response.Body, e.ErrAddLink)
+	}
 
-		return errClose
+	if response.JSON200 == nil {
+		return &scrappertypes.LinkResponse{URL: request.Link}, nil
 	}
 
-	if response.StatusCode != http.StatusOK {
-		if response.StatusCode == http.StatusNotFound {
-			return e.ErrTagNotFound
-		} else if response.StatusCode == http.StatusInternalServerError {
-			return e.ErrDeleteTag
-		}
+	return response.JSON200, nil
+}
+
+func (c *ScrapperClient) UpdateLink(ctx context.Context, chatID int64, request scrappertypes.UpdateLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
+	response, err := c.api.UpdateLinkWithResponse(ctx, &scrapperapi.UpdateLinkParams{TgChatId: chatID}, request)
+	if err != nil {
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-		slog.Error("Unknown error while deleting tag: ",
-			slog.Int("status code", response.StatusCode))
+		return nil, e.ErrUpdateLink
 	}
 
-	return nil
+	switch {
+	case response.StatusCode() != http.StatusOK:
+		return nil, apiError(response.Body, e.ErrUpdateLink)
+	case response.JSON200 == nil:
+		return nil, e.ErrDecodeJSONBody
+	default:
+		return response.JSON200, nil
+	}
 }
 
-func (c *ScrapperClient) AddLink(chatID int64, request scrappertypes.AddLinkRequest) error {
-	body, err := json.Marshal(request)
+// ExportLinks returns the file with the links of the chat.
+func (c *ScrapperClient) ExportLinks(ctx context.Context, chatID int64, format string) ([]byte, error) {
+	fileFormat := scrapperapi.ExportLinksParamsFormat(format)
+
+	response, err := c.api.ExportLinksWithResponse(ctx, &scrapperapi.ExportLinksParams{TgChatId: chatID, Format: &fileFormat})
 	if err != nil {
 		slog.Error(
-			e.ErrMarshalJSON.Error(),
-			slog.String("error", err.Error()))
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-		return e.ErrMarshalJSON
+		return nil, e.ErrExport
 	}
 
-	response, err := c.doWithLink(http.MethodPost, chatID, body)
+	if response.StatusCode() != http.StatusOK {
+		return nil, apiError(response.Body, e.ErrExport)
+	}
+
+	return response.Body, nil
+}
+
+func (c *ScrapperClient) ImportLinks(ctx context.Context, chatID int64, request scrappertypes.ImportRequest) (
+	*scrappertypes.ImportResponse, error) {
+	response, err := c.api.ImportLinksWithResponse(ctx, &scrapperapi.ImportLinksParams{TgChatId: chatID}, request)
 	if err != nil {
-		return e.ErrAddLink
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
+
+		return nil, e.ErrImport
 	}
 
-	if response == nil {
-		return nil
+	switch {
+	case response.StatusCode() != http.StatusOK:
+		return nil, apiError(response.Body, e.ErrImport)
+	case response.JSON200 == nil:
+		return nil, e.ErrDecodeJSONBody
+	default:
+		return response.JSON200, nil
 	}
+}
 
-	errClose := response.Body.Close()
-	if errClose != nil {
-		slog.Error("Error closing response body" + errClose.Error())
+// codeErrors maps the codes of scrapper's errors to sentinel errors.
+var codeErrors = map[string]error{
+	scrappertypes.CodeInvalidRequest:      e.ErrInvalidRequest,
+	scrappertypes.CodeInvalidLink:         e.ErrWrongURLFormat,
+	scrappertypes.CodeProviderUnavailable: e.ErrProviderUnavailable,
+	scrappertypes.CodeResourceNotFound:    e.ErrResourceNotFound,
+	scrappertypes.CodeResourcePrivate:     e.ErrResourcePrivate,
+	scrappertypes.CodeRateLimited:         e.ErrRateLimited,
+	scrappertypes.CodeRefreshTooOften:     e.ErrRefreshTooOften,
+	scrappertypes.CodeUnauthorized:        e.ErrUnauthorized,
+	scrappertypes.CodeChatNotFound:        e.ErrChatNotFound,
+	scrappertypes.CodeChatAlreadyExists:   e.ErrChatAlreadyExists,
+	scrappertypes.CodeLinkNotFound:        e.ErrLinkNotFound,
+	scrappertypes.CodeLinkAlreadyExists:   e.ErrLinkAlreadyExists,
+	scrappertypes.CodeTagNotFound:         e.ErrTagNotFound,
+	scrappertypes.CodeTagAlreadyExists:    e.ErrTagAlreadyExists,
+}
 
-		return errClose
+// apiError maps the code of scrapper's APIErrorResponse to a sentinel error, falling back to fallback
+// for internal errors and the answers that are not an APIErrorResponse.
+func apiError(body []byte, fallback error) error {
+	var apiErr scrappertypes.APIErrorResponse
+	if errDecode := json.Unmarshal(body, &apiErr); errDecode != nil {
+		return fallback
 	}
 
-	if response.StatusCode != http.StatusOK {
-		if response.StatusCode == http.StatusConflict {
-			return e.ErrLinkAlreadyExists
-		}
-
-		return e.ErrAddLink
+	if err, ok := codeErrors[apiErr.Code]; ok {
+		return err
 	}
 
-	return nil
+	return fallback
 }
 
-func (c *ScrapperClient) RemoveLink(chatID int64, request scrappertypes.RemoveLinkRequest) error {
-	body, err := json.Marshal(request)
+func (c *ScrapperClient) RemoveLink(ctx context.Context, chatID int64, request scrappertypes.RemoveLinkRequest) error {
+	response, err := c.api.RemoveLinkWithResponse(ctx, &scrapperapi.RemoveLinkParams{TgChatId: chatID}, request)
 	if err != nil {
 		slog.Error(
-			e.ErrMarshalJSON.Error(),
-			slog.String("error", err.Error()))
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
+
+		return e.ErrDeleteLink
+	}
 
-		return e.ErrMarshalJSON
+	if response.StatusCode() != http.StatusOK {
+		return apiError(response.Body, e.ErrDeleteLink)
 	}
 
-	response, err := c.doWithLink(http.MethodDelete, chatID, body)
+	return nil
+}
 
+func (c *ScrapperClient) RefreshLinks(ctx context.Context, chatID int64, request scrappertypes.RefreshRequest) (
+	*scrappertypes.RefreshResponse, error) {
+	response, err := c.api.RefreshLinksWithResponse(ctx, &scrapperapi.RefreshLinksParams{TgChatId: chatID}, request)
 	if err != nil {
-		return e.ErrDeleteLink
-	}
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("error", err.Error()),
+		)
 
-	if response == nil {
-		return nil
+		return nil, e.ErrRefresh
 	}
 
-	errClose := response.Body.Close()
-	if errClose != nil {
-		slog.Error("Error closing response body" + errClose.Error())
+	if response.StatusCode() != http.StatusOK {
+		return nil, apiError(response.Body, e.ErrRefresh)
 	}
 
-	if response.StatusCode != http.StatusOK {
-		if response.StatusCode == http.StatusNotFound {
-			return e.ErrLinkNotFound
-		} else if response.StatusCode == http.StatusInternalServerError {
-			return e.ErrDeleteLink
-		}
+	if response.JSON200 == nil {
+		return nil, e.ErrDecodeJSONBody
 	}
 
-	return err
+	return response.JSON200, nil
 }
 
-func (c *ScrapperClient) doWithLink(method string, chatID int64, body []byte) (*http.Response, error) {
-	u := "/links"
-
-	q := url.Values{}
-
-	q.Add("Tg-Chat-Id", fmt.Sprintf("%d", chatID))
-	q.Add("Content-Type", "application/json")
-
-	response, errDoReq := DoRequest(c.client, method, c.scheme, c.host, u, q, body, false)
-	if errDoReq != nil {
+func (c *ScrapperClient) EnableLink(ctx context.Context, chatID int64, request scrappertypes.EnableLinkRequest) error {
+	response, err := c.api.EnableLinkWithResponse(ctx, &scrapperapi.EnableLinkParams{TgChatId: chatID}, request)
+	if err != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("error", errDoReq.Error()),
+			slog.String("error", err.Error()),
 		)
 
-		return response, e.ErrDoRequest
+		return e.ErrEnableLink
 	}
 
-	if response == nil {
-		return nil, nil
+	if response.StatusCode() != http.StatusOK {
+		return apiError(response.Body, e.ErrEnableLink)
 	}
 
-	if response.StatusCode != http.StatusOK {
-		slog.Error("Error while doing operation with link:",
-			slog.Int("status code", response.StatusCode))
-	}
-
-	return response, nil
+	return nil
 }
@@ -0,0 +1,446 @@
+package clients_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapperClient_AddLink(t *testing.T) {
+	type TestCase struct {
+		name          string
+		statusCode    int
+		response      interface{}
+		expectedTitle string
+		expectedErr   error
+	}
+
+	testCases := []TestCase{
+		{
+			name:          "link added, title of resource is returned",
+			statusCode:    http.StatusOK,
+			response:      scrappertypes.LinkResponse{URL: "https://github.com/a/b/pulls", Title: "a/b"},
+			expectedTitle: "a/b",
+		},
+		{
+			name:        "link already exists",
+			statusCode:  http.StatusConflict,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeLinkAlreadyExists},
+			expectedErr: e.ErrLinkAlreadyExists,
+		},
+		{
+			name:        "link can't be tracked",
+			statusCode:  http.StatusBadRequest,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeInvalidLink},
+			expectedErr: e.ErrWrongURLFormat,
+		},
+		{
+			name:        "provider is unavailable",
+			statusCode:  http.StatusBadGateway,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeProviderUnavailable},
+			expectedErr: e.ErrProviderUnavailable,
+		},
+		{
+			name:        "internal error",
+			statusCode:  http.StatusInternalServerError,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeInternal},
+			expectedErr: e.ErrAddLink,
+		},
+		{
+			name:        "resource not found",
+			statusCode:  http.StatusNotFound,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeResourceNotFound},
+			expectedErr: e.ErrResourceNotFound,
+		},
+		{
+			name:        "resource is private",
+			statusCode:  http.StatusForbidden,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeResourcePrivate},
+			expectedErr: e.ErrResourcePrivate,
+		},
+		{
+			name:        "provider rate limit exceeded",
+			statusCode:  http.StatusTooManyRequests,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeRateLimited},
+			expectedErr: e.ErrRateLimited,
+		},
+		{
+			name:        "unknown error",
+			statusCode:  http.StatusInternalServerError,
+			response:    nil,
+			expectedErr: e.ErrAddLink,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(testCase.statusCode)
+
+				if testCase.response != nil {
+					_ = json.NewEncoder(w).Encode(testCase.response)
+				}
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			link, err := client.AddLink(context.Background(), 1, scrappertypes.AddLinkRequest{Link: "https://github.com/a/b/pulls"})
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+
+			if testCase.expectedErr == nil {
+				assert.Equal(t, testCase.expectedTitle, link.Title)
+			}
+		})
+	}
+}
+
+func TestScrapperClient_RefreshLinks(t *testing.T) {
+	type TestCase struct {
+		name        string
+		statusCode  int
+		response    interface{}
+		expected    *scrappertypes.RefreshResponse
+		expectedErr error
+	}
+
+	testCases := []TestCase{
+		{
+			name:       "links are checked",
+			statusCode: http.StatusOK,
+			response: scrappertypes.RefreshResponse{
+				Checked: 2,
+				Updated: []string{"https://github.com/a/b/pulls"},
+			},
+			expected: &scrappertypes.RefreshResponse{
+				Checked: 2,
+				Updated: []string{"https://github.com/a/b/pulls"},
+			},
+		},
+		{
+			name:        "link is not tracked",
+			statusCode:  http.StatusNotFound,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeLinkNotFound},
+			expectedErr: e.ErrLinkNotFound,
+		},
+		{
+			name:        "refreshed too often",
+			statusCode:  http.StatusTooManyRequests,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeRefreshTooOften},
+			expectedErr: e.ErrRefreshTooOften,
+		},
+		{
+			name:        "unknown error",
+			statusCode:  http.StatusInternalServerError,
+			expectedErr: e.ErrRefresh,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/links/refresh", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(testCase.statusCode)
+
+				if testCase.response != nil {
+					_ = json.NewEncoder(w).Encode(testCase.response)
+				}
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			got, err := client.RefreshLinks(context.Background(), 1, scrappertypes.RefreshRequest{})
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestScrapperClient_GetLinks(t *testing.T) {
+	testCases := []struct {
+		name          string
+		request       scrappertypes.GetLinksRequest
+		expectedQuery string
+	}{
+		{
+			name:          "all links",
+			expectedQuery: "Tg-Chat-Id=1",
+		},
+		{
+			name: "page of links",
+			request: scrappertypes.GetLinksRequest{
+				Sort:   scrappertypes.SortProvider,
+				Order:  scrappertypes.OrderDesc,
+				Cursor: "abc",
+				Limit:  5,
+			},
+			expectedQuery: "Tg-Chat-Id=1&cursor=abc&limit=5&order=desc&sort=provider",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/links", r.URL.Path)
+				assert.Equal(t, testCase.expectedQuery, r.URL.Query().Encode())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+
+				_ = json.NewEncoder(w).Encode(scrappertypes.ListLinksResponse{NextCursor: "def"})
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			got, err := client.GetLinks(context.Background(), 1, testCase.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, "def", got.NextCursor)
+		})
+	}
+}
+
+func TestScrapperClient_UpdateLink(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		response    interface{}
+		expected    *scrappertypes.LinkResponse
+		expectedErr error
+	}{
+		{
+			name:       "tags are changed",
+			statusCode: http.StatusOK,
+			response:   scrappertypes.LinkResponse{URL: "https://github.com/a/b", Tags: []string{"go"}},
+			expected:   &scrappertypes.LinkResponse{URL: "https://github.com/a/b", Tags: []string{"go"}},
+		},
+		{
+			name:        "link is not tracked",
+			statusCode:  http.StatusNotFound,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeLinkNotFound},
+			expectedErr: e.ErrLinkNotFound,
+		},
+		{
+			name:        "unknown error",
+			statusCode:  http.StatusInternalServerError,
+			expectedErr: e.ErrUpdateLink,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, "/links", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(testCase.statusCode)
+
+				if testCase.response != nil {
+					_ = json.NewEncoder(w).Encode(testCase.response)
+				}
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			got, err := client.UpdateLink(context.Background(), 1, scrappertypes.UpdateLinkRequest{
+				Link:       "https://github.com/a/b",
+				LinkChange: scrappertypes.LinkChange{AddTags: []string{"go"}},
+			})
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestScrapperClient_ExportLinks(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		body        string
+		expected    []byte
+		expectedErr error
+	}{
+		{
+			name:       "file is returned",
+			statusCode: http.StatusOK,
+			body:       "url,tags,filters\nhttps://github.com/a/b/pulls,work,\n",
+			expected:   []byte("url,tags,filters\nhttps://github.com/a/b/pulls,work,\n"),
+		},
+		{
+			name:        "chat is not registered",
+			statusCode:  http.StatusNotFound,
+			body:        `{"code":"CHAT_NOT_FOUND"}`,
+			expectedErr: e.ErrChatNotFound,
+		},
+		{
+			name:        "unknown error",
+			statusCode:  http.StatusInternalServerError,
+			expectedErr: e.ErrExport,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/links/export", r.URL.Path)
+				assert.Equal(t, "Tg-Chat-Id=1&format=csv", r.URL.Query().Encode())
+				w.Header().Set("Content-Type", "text/csv")
+				w.WriteHeader(testCase.statusCode)
+
+				_, _ = w.Write([]byte(testCase.body))
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			got, err := client.ExportLinks(context.Background(), 1, scrappertypes.FormatCSV)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestScrapperClient_ImportLinks(t *testing.T) {
+	request := scrappertypes.ImportRequest{Format: scrappertypes.FormatCSV, Data: "https://github.com/a/b/pulls\n", DryRun: true}
+	report := scrappertypes.ImportResponse{
+		Rows:   []scrappertypes.ImportRow{{Row: 1, Link: "https://github.com/a/b/pulls", Status: scrappertypes.ImportAdded}},
+		Added:  1,
+		DryRun: true,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/links/import", r.URL.Path)
+
+		var got scrappertypes.ImportRequest
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		assert.Equal(t, request, got)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		_ = json.NewEncoder(w).Encode(report)
+	}))
+	defer server.Close()
+
+	client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+	got, err := client.ImportLinks(context.Background(), 1, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, &report, got)
+}
+
+func TestScrapperClient_RenameTag(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		response    interface{}
+		expected    *scrappertypes.TagResponse
+		expectedErr error
+	}{
+		{
+			name:       "tag is renamed",
+			statusCode: http.StatusOK,
+			response:   scrappertypes.TagResponse{Name: "job", Links: 2},
+			expected:   &scrappertypes.TagResponse{Name: "job", Links: 2},
+		},
+		{
+			name:        "new name is used already",
+			statusCode:  http.StatusConflict,
+			response:    scrappertypes.APIErrorResponse{Code: scrappertypes.CodeTagAlreadyExists},
+			expectedErr: e.ErrTagAlreadyExists,
+		},
+		{
+			name:        "unknown error",
+			statusCode:  http.StatusInternalServerError,
+			expectedErr: e.ErrRenameTag,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/tags/rename", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(testCase.statusCode)
+
+				if testCase.response != nil {
+					_ = json.NewEncoder(w).Encode(testCase.response)
+				}
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			got, err := client.RenameTag(context.Background(), 1, scrappertypes.RenameTagRequest{Tag: "work", NewName: "job"})
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestScrapperClient_Ping(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		expectedErr error
+	}{
+		{
+			name:       "scrapper is alive",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "scrapper is unhealthy",
+			statusCode:  http.StatusServiceUnavailable,
+			expectedErr: e.ErrUnhealthy,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/healthz", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(testCase.statusCode)
+			}))
+			defer server.Close()
+
+			client := clients.NewScrapperClient("http", server.Listener.Addr().String(), "secret")
+
+			err := client.Ping(context.Background())
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", testCase.expectedErr, err)
+			}
+		})
+	}
+}
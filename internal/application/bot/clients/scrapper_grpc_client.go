@@ -0,0 +1,350 @@
+package clients
+
+import (
+	"context"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/auth"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/e"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCScrapperClient calls the scrapper over its gRPC API. Calls are signed with the service secret.
+type GRPCScrapperClient struct {
+	conn   *grpc.ClientConn
+	api    protov1.ScrapperServiceClient
+	health healthpb.HealthClient
+}
+
+var _ HTTPScrapperClient = (*GRPCScrapperClient)(nil)
+
+// NewGRPCScrapperClient returns the client of the scrapper at target. It connects lazily, on the first call.
+func NewGRPCScrapperClient(target, secret string) (*GRPCScrapperClient, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		tracing.DialOption(),
+		grpc.WithChainUnaryInterceptor(auth.UnaryClientInterceptor(secret)),
+		grpc.WithChainStreamInterceptor(auth.StreamClientInterceptor(secret)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCScrapperClient{
+		conn:   conn,
+		api:    protov1.NewScrapperServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+	}, nil
+}
+
+func (c *GRPCScrapperClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCScrapperClient) RegisterChat(ctx context.Context, id int64) {
+	if _, err := c.api.RegisterChat(ctx, &protov1.RegisterChatRequest{ChatId: id}); err != nil {
+		slog.Error("error registering chat",
+			slog.String("error", err.Error()))
+	}
+}
+
+func (c *GRPCScrapperClient) DeleteChat(ctx context.Context, id int64) {
+	if _, err := c.api.DeleteChat(ctx, &protov1.DeleteChatRequest{ChatId: id}); err != nil {
+		slog.Error("error deleting chat",
+			slog.String("error", err.Error()))
+	}
+}
+
+// Ping asks the gRPC health service of the scrapper whether it serves.
+func (c *GRPCScrapperClient) Ping(ctx context.Context) error {
+	response, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+
+	if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return e.ErrUnhealthy
+	}
+
+	return nil
+}
+
+func (c *GRPCScrapperClient) GetLinks(ctx context.Context, chatID int64, request scrappertypes.GetLinksRequest) (
+	*scrappertypes.ListLinksResponse, error) {
+	return c.listLinks(ctx, &protov1.ListLinksRequest{
+		ChatId:     chatID,
+		Sort:       linkSorts[request.Sort],
+		Descending: request.Order == scrappertypes.OrderDesc,
+		Cursor:     request.Cursor,
+		Limit:      int32(request.Limit),
+	})
+}
+
+// linkSorts maps the sorts of the HTTP API to the ones of the gRPC API.
+var linkSorts = map[string]protov1.LinkSort{
+	scrappertypes.SortAdded:    protov1.LinkSort_LINK_SORT_ADDED,
+	scrappertypes.SortActivity: protov1.LinkSort_LINK_SORT_ACTIVITY,
+	scrappertypes.SortURL:      protov1.LinkSort_LINK_SORT_URL,
+	scrappertypes.SortProvider: protov1.LinkSort_LINK_SORT_PROVIDER,
+}
+
+func (c *GRPCScrapperClient) GetLinksByTag(ctx context.Context, chatID int64, request scrappertypes.GetLinksByTagsRequest) (
+	*scrappertypes.ListLinksResponse, error) {
+	return c.listLinks(ctx, &protov1.ListLinksRequest{ChatId: chatID, Tags: request.Tags})
+}
+
+func (c *GRPCScrapperClient) listLinks(ctx context.Context, request *protov1.ListLinksRequest) (*scrappertypes.ListLinksResponse, error) {
+	response, err := c.api.ListLinks(ctx, request)
+	if err != nil {
+		return nil, grpcError(err, e.ErrAPI)
+	}
+
+	links := make([]scrappertypes.LinkResponse, 0, len(response.GetLinks()))
+	for _, link := range response.GetLinks() {
+		links = append(links, fromProtoLink(link))
+	}
+
+	return &scrappertypes.ListLinksResponse{
+		Links:      links,
+		Size:       int(response.GetSize()),
+		NextCursor: response.GetNextCursor(),
+		PrevCursor: response.GetPrevCursor(),
+	}, nil
+}
+
+func (c *GRPCScrapperClient) DeleteTag(ctx context.Context, chatID int64, request scrappertypes.DeleteTagRequest) error {
+	_, err := c.api.DeleteTag(ctx, &protov1.DeleteTagRequest{ChatId: chatID, Tag: request.Tag})
+
+	return grpcError(err, e.ErrDeleteTag)
+}
+
+func (c *GRPCScrapperClient) GetTags(ctx context.Context, chatID int64) (*scrappertypes.ListTagsResponse, error) {
+	response, err := c.api.ListTags(ctx, &protov1.ListTagsRequest{ChatId: chatID})
+	if err != nil {
+		return nil, grpcError(err, e.ErrGetTags)
+	}
+
+	tags := make([]scrappertypes.TagResponse, 0, len(response.GetTags()))
+	for _, tag := range response.GetTags() {
+		tags = append(tags, fromProtoTag(tag))
+	}
+
+	return &scrappertypes.ListTagsResponse{Tags: tags, Size: len(tags)}, nil
+}
+
+func (c *GRPCScrapperClient) RenameTag(ctx context.Context, chatID int64, request scrappertypes.RenameTagRequest) (
+	*scrappertypes.TagResponse, error) {
+	response, err := c.api.RenameTag(ctx, &protov1.RenameTagRequest{ChatId: chatID, Tag: request.Tag, NewName: request.NewName})
+	if err != nil {
+		return nil, grpcError(err, e.ErrRenameTag)
+	}
+
+	tag := fromProtoTag(response.GetTag())
+
+	return &tag, nil
+}
+
+func (c *GRPCScrapperClient) MergeTags(ctx context.Context, chatID int64, request scrappertypes.MergeTagsRequest) (
+	*scrappertypes.TagResponse, error) {
+	response, err := c.api.MergeTags(ctx, &protov1.MergeTagsRequest{ChatId: chatID, Tag: request.Tag, Into: request.Into})
+	if err != nil {
+		return nil, grpcError(err, e.ErrRenameTag)
+	}
+
+	tag := fromProtoTag(response.GetTag())
+
+	return &tag, nil
+}
+
+func (c *GRPCScrapperClient) AddLink(ctx context.Context, chatID int64, request scrappertypes.AddLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
+	response, err := c.api.AddLink(ctx, &protov1.AddLinkRequest{
+		ChatId:          chatID,
+		Link:            request.Link,
+		Tags:            request.Tags,
+		Filters:         request.Filters,
+		IntervalSeconds: request.IntervalSeconds,
+	})
+	if err != nil {
+		return nil, grpcError(err, e.ErrAddLink)
+	}
+
+	link := fromProtoLink(response.GetLink())
+
+	return &link, nil
+}
+
+func (c *GRPCScrapperClient) RemoveLink(ctx context.Context, chatID int64, request scrappertypes.RemoveLinkRequest) error {
+	_, err := c.api.RemoveLink(ctx, &protov1.RemoveLinkRequest{ChatId: chatID, Link: request.Link})
+
+	return grpcError(err, e.ErrDeleteLink)
+}
+
+func (c *GRPCScrapperClient) RefreshLinks(ctx context.Context, chatID int64, request scrappertypes.RefreshRequest) (
+	*scrappertypes.RefreshResponse, error) {
+	response, err := c.api.RefreshLinks(ctx, &protov1.RefreshLinksRequest{ChatId: chatID, Link: request.Link})
+	if err != nil {
+		return nil, grpcError(err, e.ErrRefresh)
+	}
+
+	return &scrappertypes.RefreshResponse{
+		Checked:    int(response.GetChecked()),
+		Updated:    response.GetUpdated(),
+		InProgress: int(response.GetInProgress()),
+		Broken:     response.GetBroken(),
+	}, nil
+}
+
+func (c *GRPCScrapperClient) EnableLink(ctx context.Context, chatID int64, request scrappertypes.EnableLinkRequest) error {
+	_, err := c.api.EnableLink(ctx, &protov1.EnableLinkRequest{ChatId: chatID, Link: request.Link})
+
+	return grpcError(err, e.ErrEnableLink)
+}
+
+func (c *GRPCScrapperClient) UpdateLink(ctx context.Context, chatID int64, request scrappertypes.UpdateLinkRequest) (
+	*scrappertypes.LinkResponse, error) {
+	response, err := c.api.UpdateLink(ctx, &protov1.UpdateLinkRequest{
+		ChatId:        chatID,
+		Link:          request.Link,
+		AddTags:       request.AddTags,
+		RemoveTags:    request.RemoveTags,
+		AddFilters:    request.AddFilters,
+		RemoveFilters: request.RemoveFilters,
+	})
+	if err != nil {
+		return nil, grpcError(err, e.ErrUpdateLink)
+	}
+
+	link := fromProtoLink(response.GetLink())
+
+	return &link, nil
+}
+
+func (c *GRPCScrapperClient) ExportLinks(ctx context.Context, chatID int64, format string) ([]byte, error) {
+	response, err := c.api.ExportLinks(ctx, &protov1.ExportLinksRequest{ChatId: chatID, Format: linkFileFormats[format]})
+	if err != nil {
+		return nil, grpcError(err, e.ErrExport)
+	}
+
+	return response.GetData(), nil
+}
+
+func (c *GRPCScrapperClient) ImportLinks(ctx context.Context, chatID int64, request scrappertypes.ImportRequest) (
+	*scrappertypes.ImportResponse, error) {
+	response, err := c.api.ImportLinks(ctx, &protov1.ImportLinksRequest{
+		ChatId: chatID,
+		Format: linkFileFormats[request.Format],
+		Data:   []byte(request.Data),
+		DryRun: request.DryRun,
+	})
+	if err != nil {
+		return nil, grpcError(err, e.ErrImport)
+	}
+
+	report := &scrappertypes.ImportResponse{
+		Rows:    make([]scrappertypes.ImportRow, 0, len(response.GetRows())),
+		Added:   int(response.GetAdded()),
+		Skipped: int(response.GetSkipped()),
+		Invalid: int(response.GetInvalid()),
+		DryRun:  response.GetDryRun(),
+	}
+
+	for _, row := range response.GetRows() {
+		report.Rows = append(report.Rows, scrappertypes.ImportRow{
+			Row:    int(row.GetRow()),
+			Link:   row.GetLink(),
+			Status: importStatuses[row.GetStatus()],
+			Reason: row.GetReason(),
+		})
+	}
+
+	return report, nil
+}
+
+// linkFileFormats maps the file formats of the HTTP API to the ones of the gRPC API.
+var linkFileFormats = map[string]protov1.LinkFileFormat{
+	scrappertypes.FormatJSON: protov1.LinkFileFormat_LINK_FILE_FORMAT_JSON,
+	scrappertypes.FormatCSV:  protov1.LinkFileFormat_LINK_FILE_FORMAT_CSV,
+	scrappertypes.FormatOPML: protov1.LinkFileFormat_LINK_FILE_FORMAT_OPML,
+}
+
+var importStatuses = map[protov1.ImportStatus]string{
+	protov1.ImportStatus_IMPORT_STATUS_ADDED:   scrappertypes.ImportAdded,
+	protov1.ImportStatus_IMPORT_STATUS_SKIPPED: scrappertypes.ImportSkipped,
+	protov1.ImportStatus_IMPORT_STATUS_INVALID: scrappertypes.ImportInvalid,
+}
+
+// StreamUpdates opens the stream the scrapper pushes link updates over.
+func (c *GRPCScrapperClient) StreamUpdates(ctx context.Context) (protov1.ScrapperService_StreamUpdatesClient, error) {
+	return c.api.StreamUpdates(ctx)
+}
+
+// grpcError maps the ErrorInfo of the scrapper's status to a sentinel error, falling back to fallback.
+func grpcError(err, fallback error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := status.Convert(err)
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			if known, ok := codeErrors[info.GetReason()]; ok {
+				return known
+			}
+		}
+	}
+
+	slog.Error(
+		e.ErrDoRequest.Error(),
+		slog.String("code", st.Code().String()),
+		slog.String("error", st.Message()),
+	)
+
+	if st.Code() == codes.Unauthenticated {
+		return e.ErrUnauthorized
+	}
+
+	return fallback
+}
+
+func fromProtoLink(link *protov1.Link) scrappertypes.LinkResponse {
+	result := scrappertypes.LinkResponse{
+		ID:                  link.GetId(),
+		URL:                 link.GetUrl(),
+		Tags:                link.GetTags(),
+		Filters:             link.GetFilters(),
+		LastVersion:         link.GetLastVersion(),
+		Title:               link.GetTitle(),
+		Broken:              link.GetBroken(),
+		ConsecutiveFailures: int(link.GetConsecutiveFailures()),
+		LastError:           link.GetLastError(),
+	}
+
+	if link.GetAddedAt() != nil {
+		result.AddedAt = link.GetAddedAt().AsTime()
+	}
+
+	if link.GetLastChecked() != nil {
+		result.LastChecked = link.GetLastChecked().AsTime()
+	}
+
+	if link.GetLastSuccessAt() != nil {
+		lastSuccessAt := link.GetLastSuccessAt().AsTime()
+		result.LastSuccessAt = &lastSuccessAt
+	}
+
+	return result
+}
+
+func fromProtoTag(tag *protov1.Tag) scrappertypes.TagResponse {
+	return scrappertypes.TagResponse{Name: tag.GetName(), Links: int(tag.GetLinks())}
+}
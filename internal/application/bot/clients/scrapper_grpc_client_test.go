@@ -0,0 +1,231 @@
+package clients_test
+
+import (
+	"context"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/auth"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const grpcSecret = "test-secret"
+
+// fakeScrapper answers with the links of chat 1 and with the errors the scrapper sends for the other calls.
+type fakeScrapper struct {
+	protov1.UnimplementedScrapperServiceServer
+}
+
+func (fakeScrapper) ListLinks(_ context.Context, req *protov1.ListLinksRequest) (*protov1.ListLinksResponse, error) {
+	if req.GetChatId() != 1 {
+		return nil, withReason(codes.NotFound, scrappertypes.CodeChatNotFound)
+	}
+
+	response := &protov1.ListLinksResponse{
+		Links: []*protov1.Link{{
+			Id:          1,
+			Url:         "https://github.com/a/b",
+			Tags:        req.GetTags(),
+			AddedAt:     timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			LastChecked: timestamppb.New(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		}},
+		Size: 1,
+	}
+
+	if req.GetSort() == protov1.LinkSort_LINK_SORT_URL && req.GetDescending() && req.GetLimit() == 1 && req.GetCursor() == "page-2" {
+		response.NextCursor, response.PrevCursor = "page-3", "page-1"
+	}
+
+	return response, nil
+}
+
+func (fakeScrapper) AddLink(_ context.Context, _ *protov1.AddLinkRequest) (*protov1.AddLinkResponse, error) {
+	return nil, withReason(codes.PermissionDenied, scrappertypes.CodeResourcePrivate)
+}
+
+func (fakeScrapper) RemoveLink(_ context.Context, _ *protov1.RemoveLinkRequest) (*protov1.RemoveLinkResponse, error) {
+	return nil, withReason(codes.NotFound, scrappertypes.CodeLinkNotFound)
+}
+
+func (fakeScrapper) DeleteTag(_ context.Context, _ *protov1.DeleteTagRequest) (*protov1.DeleteTagResponse, error) {
+	return nil, status.Error(codes.Internal, "database is down")
+}
+
+func (fakeScrapper) ListTags(_ context.Context, _ *protov1.ListTagsRequest) (*protov1.ListTagsResponse, error) {
+	return &protov1.ListTagsResponse{Tags: []*protov1.Tag{{Name: "work", Links: 2}, {Name: "home", Links: 1}}}, nil
+}
+
+func (fakeScrapper) MergeTags(_ context.Context, _ *protov1.MergeTagsRequest) (*protov1.MergeTagsResponse, error) {
+	return nil, withReason(codes.NotFound, scrappertypes.CodeTagNotFound)
+}
+
+func (fakeScrapper) ImportLinks(_ context.Context, req *protov1.ImportLinksRequest) (*protov1.ImportLinksResponse, error) {
+	if req.GetFormat() != protov1.LinkFileFormat_LINK_FILE_FORMAT_OPML {
+		return nil, withReason(codes.InvalidArgument, scrappertypes.CodeInvalidRequest)
+	}
+
+	return &protov1.ImportLinksResponse{
+		Rows: []*protov1.ImportRow{
+			{Row: 1, Link: string(req.GetData()), Status: protov1.ImportStatus_IMPORT_STATUS_SKIPPED, Reason: "link already exists"},
+		},
+		Skipped: 1,
+		DryRun:  req.GetDryRun(),
+	}, nil
+}
+
+func withReason(code codes.Code, reason string) error {
+	st, _ := status.New(code, reason).WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "scrapper"})
+
+	return st.Err()
+}
+
+func startFakeScrapper(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(grpcSecret, time.Minute)))
+	protov1.RegisterScrapperServiceServer(server, fakeScrapper{})
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestGRPCScrapperClient(t *testing.T) {
+	target := startFakeScrapper(t)
+
+	client, err := clients.NewGRPCScrapperClient(target, grpcSecret)
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	ctx := context.Background()
+
+	t.Run("links are converted", func(t *testing.T) {
+		links, err := client.GetLinksByTag(ctx, 1, scrappertypes.GetLinksByTagsRequest{Tags: []string{"work"}})
+		require.NoError(t, err)
+
+		require.Len(t, links.Links, 1)
+		assert.Equal(t, 1, links.Size)
+		assert.Equal(t, "https://github.com/a/b", links.Links[0].URL)
+		assert.Equal(t, []string{"work"}, links.Links[0].Tags)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), links.Links[0].AddedAt)
+		assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), links.Links[0].LastChecked)
+	})
+
+	t.Run("page is requested and its cursors are returned", func(t *testing.T) {
+		links, err := client.GetLinks(ctx, 1, scrappertypes.GetLinksRequest{
+			Sort:   scrappertypes.SortURL,
+			Order:  scrappertypes.OrderDesc,
+			Cursor: "page-2",
+			Limit:  1,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "page-3", links.NextCursor)
+		assert.Equal(t, "page-1", links.PrevCursor)
+	})
+
+	t.Run("import report is converted", func(t *testing.T) {
+		report, err := client.ImportLinks(ctx, 1, scrappertypes.ImportRequest{
+			Format: scrappertypes.FormatOPML,
+			Data:   "https://github.com/a/b/pulls",
+			DryRun: true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &scrappertypes.ImportResponse{
+			Rows: []scrappertypes.ImportRow{
+				{Row: 1, Link: "https://github.com/a/b/pulls", Status: scrappertypes.ImportSkipped, Reason: "link already exists"},
+			},
+			Skipped: 1,
+			DryRun:  true,
+		}, report)
+	})
+
+	t.Run("tags are converted", func(t *testing.T) {
+		tags, err := client.GetTags(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, &scrappertypes.ListTagsResponse{
+			Tags: []scrappertypes.TagResponse{{Name: "work", Links: 2}, {Name: "home", Links: 1}},
+			Size: 2,
+		}, tags)
+	})
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "unknown chat",
+			call: func() error {
+				_, err := client.GetLinks(ctx, 2, scrappertypes.GetLinksRequest{})
+				return err
+			},
+			wantErr: e.ErrChatNotFound,
+		},
+		{
+			name: "private resource",
+			call: func() error {
+				_, err := client.AddLink(ctx, 1, scrappertypes.AddLinkRequest{Link: "https://github.com/a/private"})
+				return err
+			},
+			wantErr: e.ErrResourcePrivate,
+		},
+		{
+			name: "unknown link",
+			call: func() error {
+				return client.RemoveLink(ctx, 1, scrappertypes.RemoveLinkRequest{Link: "https://github.com/c/d"})
+			},
+			wantErr: e.ErrLinkNotFound,
+		},
+		{
+			name: "unknown tag",
+			call: func() error {
+				_, err := client.MergeTags(ctx, 1, scrappertypes.MergeTagsRequest{Tag: "work", Into: "job"})
+				return err
+			},
+			wantErr: e.ErrTagNotFound,
+		},
+		{
+			name: "error without a reason falls back to the error of the call",
+			call: func() error {
+				return client.DeleteTag(ctx, 1, scrappertypes.DeleteTagRequest{Tag: "work"})
+			},
+			wantErr: e.ErrDeleteTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), tt.wantErr)
+		})
+	}
+
+	t.Run("calls signed with another secret are rejected", func(t *testing.T) {
+		other, err := clients.NewGRPCScrapperClient(target, "other-secret")
+		require.NoError(t, err)
+
+		defer other.Close()
+
+		_, err = other.GetLinks(ctx, 1, scrappertypes.GetLinksRequest{})
+		assert.ErrorIs(t, err, e.ErrUnauthorized)
+	})
+}
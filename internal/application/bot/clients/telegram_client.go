@@ -1,11 +1,15 @@
 package clients
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"go-progira/internal/domain/types/telegramtypes"
 	"go-progira/pkg/e"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path"
@@ -13,15 +17,24 @@ import (
 )
 
 const (
-	getUpdatesMethod  = "getUpdates"
-	sendMessageMethod = "sendMessage"
-	setCommandsMethod = "setMyCommands"
+	getUpdatesMethod     = "getUpdates"
+	sendMessageMethod    = "sendMessage"
+	setCommandsMethod    = "setMyCommands"
+	editMessageMethod    = "editMessageText"
+	answerCallbackMethod = "answerCallbackQuery"
+	sendDocumentMethod   = "sendDocument"
+	getFileMethod        = "getFile"
 )
 
 type HTTPTelegramClient interface {
-	Updates(offset, limit int) ([]byte, error)
-	SendMessage(chatID int, text string) error
-	SetBotCommands(commands []telegramtypes.BotCommand) error
+	Updates(ctx context.Context, offset, limit int) ([]byte, error)
+	SendMessage(ctx context.Context, chatID int, text string) error
+	SetBotCommands(ctx context.Context, commands []telegramtypes.BotCommand) error
+	SendKeyboard(ctx context.Context, chatID int, text string, keyboard telegramtypes.InlineKeyboardMarkup) error
+	EditMessage(ctx context.Context, chatID, messageID int, text string, keyboard telegramtypes.InlineKeyboardMarkup) error
+	AnswerCallback(ctx context.Context, callbackID, text string) error
+	SendDocument(ctx context.Context, chatID int, fileName string, data []byte, caption string) error
+	DownloadFile(ctx context.Context, fileID string) ([]byte, error)
 }
 
 type TelegramClient struct {
@@ -44,7 +57,7 @@ func newBasePath(token string) string {
 	return "bot" + token
 }
 
-func (c *TelegramClient) SetBotCommands(commands []telegramtypes.BotCommand) error {
+func (c *TelegramClient) SetBotCommands(ctx context.Context, commands []telegramtypes.BotCommand) error {
 	data, errMarshal := json.Marshal(map[string]interface{}{
 		"commands": commands,
 	})
@@ -57,7 +70,7 @@ func (c *TelegramClient) SetBotCommands(commands []telegramtypes.BotCommand) err
 		return errMarshal
 	}
 
-	response, errDoReq := DoRequest(c.Client, http.MethodPost, c.Scheme, c.Host, path.Join(c.BasePath, setCommandsMethod),
+	response, errDoReq := DoRequest(ctx, c.Client, http.MethodPost, c.Scheme, c.Host, path.Join(c.BasePath, setCommandsMethod),
 		nil, data, true)
 	if errDoReq != nil {
 		slog.Error(
@@ -77,12 +90,12 @@ func (c *TelegramClient) SetBotCommands(commands []telegramtypes.BotCommand) err
 	return nil
 }
 
-func (c *TelegramClient) Updates(offset, limit int) ([]byte, error) {
+func (c *TelegramClient) Updates(ctx context.Context, offset, limit int) ([]byte, error) {
 	q := url.Values{}
 	q.Add("offset", strconv.Itoa(offset))
 	q.Add("limit", strconv.Itoa(limit))
 
-	response, errDoReq := DoRequest(c.Client, http.MethodGet, c.Scheme, c.Host, path.Join(c.BasePath, getUpdatesMethod),
+	response, errDoReq := DoRequest(ctx, c.Client, http.MethodGet, c.Scheme, c.Host, path.Join(c.BasePath, getUpdatesMethod),
 		q, nil, false)
 	if errDoReq != nil {
 		slog.Error(
@@ -112,34 +125,219 @@ func (c *TelegramClient) Updates(offset, limit int) ([]byte, error) {
 	return data, nil
 }
 
-func (c *TelegramClient) SendMessage(chatID int, text string) error {
+func (c *TelegramClient) SendMessage(ctx context.Context, chatID int, text string) error {
+	if err := countSent(c.sendMessage(ctx, chatID, text)); err != nil {
+		return err
+	}
+
+	slog.Info("Sent message to tg",
+		slog.Int("chat_id", chatID))
+
+	return nil
+}
+
+func (c *TelegramClient) sendMessage(ctx context.Context, chatID int, text string) error {
 	q := url.Values{}
 	q.Add("chat_id", strconv.Itoa(chatID))
 	q.Add("text", text)
 
-	response, errDoReq := DoRequest(c.Client, http.MethodGet, c.Scheme, c.Host, path.Join(c.BasePath, sendMessageMethod),
+	response, errDoReq := DoRequest(ctx, c.Client, http.MethodGet, c.Scheme, c.Host, path.Join(c.BasePath, sendMessageMethod),
 		q, nil, false)
 	if errDoReq != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
-			slog.String("method", getUpdatesMethod),
+			slog.String("method", sendMessageMethod),
 			slog.String("error", errDoReq.Error()),
 		)
 
 		return errDoReq
 	}
 
-	if response == nil {
-		return nil
+	return checkResponse(sendMessageMethod, response)
+}
+
+// SendKeyboard sends a message with the buttons of keyboard under it.
+func (c *TelegramClient) SendKeyboard(ctx context.Context, chatID int, text string, keyboard telegramtypes.InlineKeyboardMarkup) error {
+	return countSent(c.postMethod(ctx, sendMessageMethod, map[string]interface{}{
+		"chat_id":      chatID,
+		"text":         text,
+		"reply_markup": keyboard,
+	}))
+}
+
+// EditMessage replaces the text and the buttons of a message sent by the bot.
+func (c *TelegramClient) EditMessage(ctx context.Context, chatID, messageID int, text string,
+	keyboard telegramtypes.InlineKeyboardMarkup) error {
+	return c.postMethod(ctx, editMessageMethod, map[string]interface{}{
+		"chat_id":      chatID,
+		"message_id":   messageID,
+		"text":         text,
+		"reply_markup": keyboard,
+	})
+}
+
+// AnswerCallback stops the progress indicator of a pressed button, showing text if it's not empty.
+func (c *TelegramClient) AnswerCallback(ctx context.Context, callbackID, text string) error {
+	return c.postMethod(ctx, answerCallbackMethod, map[string]interface{}{
+		"callback_query_id": callbackID,
+		"text":              text,
+	})
+}
+
+// SendDocument sends data as a file named fileName.
+func (c *TelegramClient) SendDocument(ctx context.Context, chatID int, fileName string, data []byte, caption string) error {
+	return countSent(c.sendDocument(ctx, chatID, fileName, data, caption))
+}
+
+func (c *TelegramClient) sendDocument(ctx context.Context, chatID int, fileName string, data []byte, caption string) error {
+	body, contentType, errForm := documentForm(chatID, fileName, data, caption)
+	if errForm != nil {
+		return errForm
+	}
+
+	response, errDoReq := doRequest(ctx, c.Client, http.MethodPost, c.Scheme, c.Host, path.Join(c.BasePath, sendDocumentMethod),
+		nil, body, contentType)
+	if errDoReq != nil {
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("method", sendDocumentMethod),
+			slog.String("error", errDoReq.Error()),
+		)
+
+		return errDoReq
+	}
+
+	return checkResponse(sendDocumentMethod, response)
+}
+
+// documentForm is the multipart form of sendDocument.
+func documentForm(chatID int, fileName string, data []byte, caption string) (body []byte, contentType string, err error) {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+
+	if err = writer.WriteField("chat_id", strconv.Itoa(chatID)); err != nil {
+		return nil, "", err
+	}
+
+	if caption != "" {
+		if err = writer.WriteField("caption", caption); err != nil {
+			return nil, "", err
+		}
+	}
+
+	part, err := writer.CreateFormFile("document", fileName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err = part.Write(data); err != nil {
+		return nil, "", err
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// DownloadFile returns the content of a file sent to the bot.
+func (c *TelegramClient) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	q := url.Values{}
+	q.Add("file_id", fileID)
+
+	data, err := c.get(ctx, path.Join(c.BasePath, getFileMethod), q)
+	if err != nil {
+		return nil, err
 	}
 
+	var file telegramtypes.FileResponse
+	if errUnmarshal := json.Unmarshal(data, &file); errUnmarshal != nil || !file.Ok || file.Result.FilePath == "" {
+		return nil, e.ErrDownloadFile
+	}
+
+	return c.get(ctx, path.Join("file", c.BasePath, file.Result.FilePath), nil)
+}
+
+// get returns the body of a successful GET request.
+func (c *TelegramClient) get(ctx context.Context, requestPath string, q url.Values) ([]byte, error) {
+	response, errDoReq := DoRequest(ctx, c.Client, http.MethodGet, c.Scheme, c.Host, requestPath, q, nil, false)
+	if errDoReq != nil {
+		return nil, errDoReq
+	}
+
+	data, errRead := io.ReadAll(response.Body)
+
 	errClose := response.Body.Close()
 	if errClose != nil {
 		slog.Error("Error closing response body" + errClose.Error())
 	}
 
-	slog.Info("Sent message to tg",
-		slog.Int("chat_id", chatID))
+	switch {
+	case errRead != nil:
+		return nil, errRead
+	case response.StatusCode != http.StatusOK:
+		slog.Error(
+			e.ErrDownloadFile.Error(),
+			slog.String("path", requestPath),
+			slog.Int("status", response.StatusCode),
+		)
 
-	return nil
+		return nil, e.ErrDownloadFile
+	default:
+		return data, nil
+	}
+}
+
+func (c *TelegramClient) postMethod(ctx context.Context, method string, payload map[string]interface{}) error {
+	data, errMarshal := json.Marshal(payload)
+	if errMarshal != nil {
+		slog.Error(
+			e.ErrMarshalJSON.Error(),
+			slog.String("error", errMarshal.Error()),
+		)
+
+		return errMarshal
+	}
+
+	response, errDoReq := DoRequest(ctx, c.Client, http.MethodPost, c.Scheme, c.Host, path.Join(c.BasePath, method),
+		nil, data, true)
+	if errDoReq != nil {
+		slog.Error(
+			e.ErrDoRequest.Error(),
+			slog.String("method", method),
+			slog.String("error", errDoReq.Error()),
+		)
+
+		return errDoReq
+	}
+
+	return checkResponse(method, response)
+}
+
+// checkResponse closes the body of the response and returns ErrTelegramAPI unless Telegram answered
+// with status 200 and ok set.
+func checkResponse(method string, response *http.Response) error {
+	var answer telegramtypes.Response
+
+	errDecode := json.NewDecoder(response.Body).Decode(&answer)
+
+	errClose := response.Body.Close()
+	if errClose != nil {
+		slog.Error("Error closing response body" + errClose.Error())
+	}
+
+	if response.StatusCode == http.StatusOK && errDecode == nil && answer.Ok {
+		return nil
+	}
+
+	slog.Error(
+		e.ErrTelegramAPI.Error(),
+		slog.String("method", method),
+		slog.Int("status", response.StatusCode),
+		slog.String("description", answer.Description),
+	)
+
+	return fmt.Errorf("%w: %s: status %d: %s", e.ErrTelegramAPI, method, response.StatusCode, answer.Description)
 }
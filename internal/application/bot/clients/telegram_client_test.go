@@ -1,17 +1,23 @@
 package clients_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/domain/types/telegramtypes"
 	"go-progira/pkg/e"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestTelegramClient_SendMessage_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
 	}))
 	defer server.Close()
 
@@ -22,7 +28,7 @@ func TestTelegramClient_SendMessage_Success(t *testing.T) {
 		BasePath: "/botTOKEN",
 	}
 
-	err := client.SendMessage(12345, "Hello!")
+	err := client.SendMessage(context.Background(), 12345, "Hello!")
 	if err != nil {
 		t.Errorf("Wrong error. Expected: %v, Got: %v", nil, err)
 	}
@@ -36,8 +42,210 @@ func TestTelegramClient_SendMessage_Error(t *testing.T) {
 		BasePath: "/botTOKEN",
 	}
 
-	err := client.SendMessage(12345, "Hello!")
+	err := client.SendMessage(context.Background(), 12345, "Hello!")
 	if !errors.Is(err, e.ErrDoRequest) {
 		t.Errorf("Wrong error. Expected: %v, Got: %v", e.ErrDoRequest, err)
 	}
 }
+
+func TestTelegramClient_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "chat blocked the bot", status: http.StatusForbidden, body: `{"ok":false,"error_code":403,"description":"Forbidden"}`},
+		{name: "not ok", status: http.StatusOK, body: `{"ok":false}`},
+		{name: "not json", status: http.StatusOK, body: "<html></html>"},
+		{name: "bad gateway", status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &clients.TelegramClient{
+				Client:   http.Client{},
+				Scheme:   "http",
+				Host:     server.Listener.Addr().String(),
+				BasePath: "/botTOKEN",
+			}
+
+			failed, ok := sentCount(t, "failed"), sentCount(t, "ok")
+
+			errs := map[string]error{
+				"sendMessage":  client.SendMessage(context.Background(), 12345, "Hello!"),
+				"sendKeyboard": client.SendKeyboard(context.Background(), 12345, "Hello!", telegramtypes.InlineKeyboardMarkup{}),
+				"sendDocument": client.SendDocument(context.Background(), 12345, "links.csv", []byte("url\n"), ""),
+			}
+
+			for method, err := range errs {
+				if !errors.Is(err, e.ErrTelegramAPI) {
+					t.Errorf("Wrong error of %s. Expected: %v, Got: %v", method, e.ErrTelegramAPI, err)
+				}
+			}
+
+			if got := sentCount(t, "failed") - failed; got != float64(len(errs)) {
+				t.Errorf("Wrong failed messages. Expected: %d, Got: %v", len(errs), got)
+			}
+
+			if got := sentCount(t, "ok") - ok; got != 0 {
+				t.Errorf("Wrong ok messages. Expected: 0, Got: %v", got)
+			}
+		})
+	}
+}
+
+// sentCount is the value of the counter of messages sent to Telegram with the result.
+func sentCount(t *testing.T, result string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Wrong error. Expected: %v, Got: %v", nil, err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "bot_telegram_messages_sent_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == result {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestTelegramClient_SendKeyboard(t *testing.T) {
+	var got map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&got)
+
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := &clients.TelegramClient{
+		Client:   http.Client{},
+		Scheme:   "http",
+		Host:     server.Listener.Addr().String(),
+		BasePath: "/botTOKEN",
+	}
+
+	keyboard := telegramtypes.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{{{Text: "Next", CallbackData: "list:next"}}},
+	}
+
+	err := client.SendKeyboard(context.Background(), 12345, "Hello!", keyboard)
+	if err != nil {
+		t.Fatalf("Wrong error. Expected: %v, Got: %v", nil, err)
+	}
+
+	markup, _ := json.Marshal(got["reply_markup"])
+	if got["text"] != "Hello!" || string(markup) != `{"inline_keyboard":[[{"callback_data":"list:next","text":"Next"}]]}` {
+		t.Errorf("Wrong request body: %v", got)
+	}
+}
+
+func TestTelegramClient_SendDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendDocument" || r.ParseMultipartForm(1<<20) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		data, _ := io.ReadAll(file)
+		if r.FormValue("chat_id") != "12345" || r.FormValue("caption") != "Links" || header.Filename != "links.csv" ||
+			string(data) != "url,tags,filters\n" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := &clients.TelegramClient{
+		Client:   http.Client{},
+		Scheme:   "http",
+		Host:     server.Listener.Addr().String(),
+		BasePath: "/botTOKEN",
+	}
+
+	err := client.SendDocument(context.Background(), 12345, "links.csv", []byte("url,tags,filters\n"), "Links")
+	if err != nil {
+		t.Errorf("Wrong error. Expected: %v, Got: %v", nil, err)
+	}
+}
+
+func TestTelegramClient_DownloadFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileID   string
+		expected []byte
+		wantErr  error
+	}{
+		{name: "file is downloaded", fileID: "abc", expected: []byte("[]")},
+		{name: "file is unknown", fileID: "def", wantErr: e.ErrDownloadFile},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/botTOKEN/getFile" && r.URL.Query().Get("file_id") == "abc":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"file_path":"documents/links.json"}}`))
+		case r.URL.Path == "/file/botTOKEN/documents/links.json":
+			_, _ = w.Write([]byte("[]"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+
+			_, _ = w.Write([]byte(`{"ok":false}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &clients.TelegramClient{
+		Client:   http.Client{},
+		Scheme:   "http",
+		Host:     server.Listener.Addr().String(),
+		BasePath: "botTOKEN",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.DownloadFile(context.Background(), tt.fileID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Wrong error. Expected: %v, Got: %v", tt.wantErr, err)
+			}
+
+			if string(got) != string(tt.expected) {
+				t.Errorf("Wrong file. Expected: %q, Got: %q", tt.expected, got)
+			}
+		})
+	}
+}
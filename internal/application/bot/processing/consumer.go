@@ -0,0 +1,208 @@
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	dlqWriteTimeout = 10 * time.Second
+
+	consumerRetryDelay    = time.Second
+	consumerMaxRetryDelay = 30 * time.Second
+	// maxDeliveryAttempts bounds the retries of an update some chats did not get, then it is moved to the DLQ.
+	maxDeliveryAttempts = 3
+)
+
+// UpdatesConsumer reads link updates from the topic the scrapper publishes to.
+// Messages that cannot be decoded or delivered are moved to the DLQ topic, so they do not block the partition.
+type UpdatesConsumer struct {
+	reader *kafka.Reader
+	dlq    *kafka.Writer
+	handle func(context.Context, bottypes.LinkUpdate) (string, error)
+}
+
+func NewUpdatesConsumer(appConfig *config.Config, handle func(context.Context, bottypes.LinkUpdate) (string, error)) *UpdatesConsumer {
+	return &UpdatesConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     appConfig.KafkaBrokers,
+			Topic:       appConfig.KafkaUpdatesTopic,
+			GroupID:     appConfig.KafkaConsumerGroup,
+			StartOffset: kafka.FirstOffset,
+		}),
+		dlq: &kafka.Writer{
+			Addr:                   kafka.TCP(appConfig.KafkaBrokers...),
+			Topic:                  appConfig.KafkaDLQTopic,
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		handle: handle,
+	}
+}
+
+// Run consumes messages until ctx is canceled. A message is committed only after it was handled
+// or moved to the DLQ, so after a crash it is read again. Errors of the broker are retried with backoff.
+func (c *UpdatesConsumer) Run(ctx context.Context) {
+	delay := consumerRetryDelay
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			slog.Error(e.ErrConsume.Error(),
+				slog.String("error", err.Error()))
+
+			if !waitRetry(ctx, &delay) {
+				return
+			}
+
+			continue
+		}
+
+		for err = c.process(ctx, msg); err != nil; err = c.process(ctx, msg) {
+			if !waitRetry(ctx, &delay) {
+				return
+			}
+		}
+
+		for err = c.reader.CommitMessages(ctx, msg); err != nil; err = c.reader.CommitMessages(ctx, msg) {
+			slog.Error(e.ErrConsume.Error(),
+				slog.String("error", fmt.Errorf("commit message: %w", err).Error()))
+
+			if !waitRetry(ctx, &delay) {
+				return
+			}
+		}
+
+		delay = consumerRetryDelay
+	}
+}
+
+// process delivers the update or moves the message to the DLQ: a malformed one right away and one that
+// some chats did not get after maxDeliveryAttempts. Only a failed DLQ write is returned.
+func (c *UpdatesConsumer) process(ctx context.Context, msg kafka.Message) error {
+	// The span continues the trace of the scrapper that published the message.
+	msgCtx, span := tracer.Start(tracing.ExtractMessage(ctx, &msg), "kafka.consume", trace.WithAttributes(
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.Int64("messaging.kafka.offset", msg.Offset)))
+	defer span.End()
+
+	update, errDecode := DecodeLinkUpdate(msg.Value)
+	if errDecode != nil {
+		updatesReceived.WithLabelValues("kafka", bottypes.UpdateInvalid).Inc()
+		slog.Warn("Moving malformed update to DLQ",
+			slog.Int("partition", msg.Partition),
+			slog.Int64("offset", msg.Offset),
+			slog.String("error", errDecode.Error()))
+
+		return c.deadLetter(ctx, msg, errDecode)
+	}
+
+	status, err := c.deliver(msgCtx, update)
+	updatesReceived.WithLabelValues("kafka", status).Inc()
+
+	if err != nil {
+		slog.Error("Moving update some chats did not get to DLQ",
+			slog.Int64("link id", update.ID),
+			slog.String("error", err.Error()))
+
+		return c.deadLetter(ctx, msg, err)
+	}
+
+	return nil
+}
+
+// deliver hands the update over until every chat gets it. The chats that got it already
+// are skipped by its idempotency key.
+func (c *UpdatesConsumer) deliver(ctx context.Context, update bottypes.LinkUpdate) (string, error) {
+	delay := consumerRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		status, err := c.handle(ctx, update)
+		if err == nil || attempt == maxDeliveryAttempts {
+			return status, err
+		}
+
+		slog.Warn("Update was not delivered to some chats, will retry",
+			slog.Int64("link id", update.ID),
+			slog.Int("attempt", attempt),
+			slog.String("error", err.Error()))
+
+		if !waitRetry(ctx, &delay) {
+			return status, err
+		}
+	}
+}
+
+// waitRetry waits for delay and doubles it up to consumerMaxRetryDelay. It returns false when ctx is canceled.
+func waitRetry(ctx context.Context, delay *time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(*delay):
+	}
+
+	*delay = min(*delay*2, consumerMaxRetryDelay)
+
+	return true
+}
+
+func (c *UpdatesConsumer) deadLetter(ctx context.Context, msg kafka.Message, cause error) error {
+	ctx, cancel := context.WithTimeout(ctx, dlqWriteTimeout)
+	defer cancel()
+
+	err := c.dlq.WriteMessages(ctx, kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: []kafka.Header{
+			{Key: "error", Value: []byte(cause.Error())},
+			{Key: "topic", Value: []byte(msg.Topic)},
+			{Key: "partition", Value: []byte(strconv.Itoa(msg.Partition))},
+			{Key: "offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		},
+	})
+	if err != nil {
+		slog.Error(e.ErrPublish.Error(),
+			slog.String("topic", c.dlq.Topic),
+			slog.String("error", err.Error()))
+
+		return fmt.Errorf("write to DLQ: %w", err)
+	}
+
+	return nil
+}
+
+func (c *UpdatesConsumer) Close() error {
+	return errors.Join(c.reader.Close(), c.dlq.Close())
+}
+
+// DecodeLinkUpdate parses a message and checks that it can be delivered.
+func DecodeLinkUpdate(data []byte) (bottypes.LinkUpdate, error) {
+	var update bottypes.LinkUpdate
+
+	if err := json.Unmarshal(data, &update); err != nil {
+		return bottypes.LinkUpdate{}, fmt.Errorf("%w: %w", e.ErrMalformedMsg, err)
+	}
+
+	if err := ValidateLinkUpdate(update); err != nil {
+		return bottypes.LinkUpdate{}, err
+	}
+
+	return update, nil
+}
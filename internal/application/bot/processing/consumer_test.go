@@ -0,0 +1,148 @@
+package processing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/application/bot/processing"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+)
+
+func TestDecodeLinkUpdate(t *testing.T) {
+	type TestCase struct {
+		name        string
+		data        string
+		expected    bottypes.LinkUpdate
+		expectedErr error
+	}
+
+	testCases := []TestCase{
+		{
+			name: "valid update",
+			data: `{"id":1,"url":"https://github.com/a/b","description":"new issue","tgChatIds":[1,2]}`,
+			expected: bottypes.LinkUpdate{
+				ID:          1,
+				URL:         "https://github.com/a/b",
+				Description: "new issue",
+				TgChatIDs:   []int64{1, 2},
+			},
+		},
+		{name: "not json", data: `update`, expectedErr: e.ErrMalformedMsg},
+		{name: "no url", data: `{"id":1,"tgChatIds":[1]}`, expectedErr: e.ErrMalformedMsg},
+		{name: "no chats", data: `{"id":1,"url":"https://github.com/a/b"}`, expectedErr: e.ErrMalformedMsg},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			update, err := processing.DecodeLinkUpdate([]byte(testCase.data))
+
+			assert.True(t, errors.Is(err, testCase.expectedErr), "unexpected error: %v", err)
+			assert.Equal(t, testCase.expected, update)
+		})
+	}
+}
+
+func startTestKafka(t *testing.T) []string {
+	t.Helper()
+
+	// The broker runs next to the unit tests of the package, so it is skipped when there is no docker.
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+
+	container, err := tckafka.Run(ctx, "confluentinc/confluent-local:7.5.0", tckafka.WithClusterID("test-cluster"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %s", err)
+		}
+	})
+
+	brokers, err := container.Brokers(ctx)
+	require.NoError(t, err)
+
+	return brokers
+}
+
+func TestUpdatesConsumer(t *testing.T) {
+	brokers := startTestKafka(t)
+
+	appConfig := &config.Config{
+		KafkaBrokers:       brokers,
+		KafkaUpdatesTopic:  "link-updates",
+		KafkaDLQTopic:      "link-updates-dlq",
+		KafkaConsumerGroup: "bot",
+	}
+
+	for _, topic := range []string{appConfig.KafkaUpdatesTopic, appConfig.KafkaDLQTopic} {
+		conn, err := kafka.Dial("tcp", brokers[0])
+		require.NoError(t, err)
+
+		err = conn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	}
+
+	update := bottypes.LinkUpdate{ID: 1, URL: "https://github.com/a/b", Description: "new issue", TgChatIDs: []int64{1}}
+
+	client := scrapper.NewKafkaBotClient(brokers, appConfig.KafkaUpdatesTopic)
+	defer client.Close()
+
+	require.NoError(t, client.SendUpdate(context.Background(), update))
+
+	writer := &kafka.Writer{Addr: kafka.TCP(brokers...), Topic: appConfig.KafkaUpdatesTopic}
+	defer writer.Close()
+
+	err := writer.WriteMessages(context.Background(), kafka.Message{Value: []byte("not an update")})
+	require.NoError(t, err)
+
+	handled := make(chan bottypes.LinkUpdate, 1)
+
+	consumer := processing.NewUpdatesConsumer(appConfig, func(_ context.Context, update bottypes.LinkUpdate) (string, error) {
+		handled <- update
+
+		return bottypes.UpdateDelivered, nil
+	})
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	go consumer.Run(ctx)
+
+	select {
+	case got := <-handled:
+		assert.Equal(t, update, got)
+	case <-ctx.Done():
+		t.Fatal("update was not consumed")
+	}
+
+	dlq := kafka.NewReader(kafka.ReaderConfig{Brokers: brokers, Topic: appConfig.KafkaDLQTopic})
+	defer dlq.Close()
+
+	msg, err := dlq.ReadMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "not an update", string(msg.Value))
+
+	headers := make(map[string]string)
+
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	assert.Equal(t, appConfig.KafkaUpdatesTopic, headers["topic"])
+	assert.Contains(t, headers["error"], e.ErrMalformedMsg.Error())
+	assert.False(t, json.Valid(msg.Value))
+}
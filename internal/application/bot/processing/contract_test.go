@@ -0,0 +1,73 @@
+package processing_test
+
+import (
+	"bytes"
+	"go-progira/internal/api/openapi/v1/contract"
+	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/application/bot/processing"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const specPath = "../../../../api/openapi/v1/service.yaml"
+
+func TestServer_Contract(t *testing.T) {
+	validator, err := contract.NewValidator(specPath)
+	require.NoError(t, err)
+
+	mockTg := new(clients.MockTgClient)
+	mockTg.On("SendMessage", 1, mock.Anything).Return(nil)
+	mockTg.On("SendMessage", 2, mock.Anything).Return(e.ErrAPI)
+
+	handler := processing.NewServer(mockTg).Handler()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "update is sent",
+			path:       "/updates",
+			body:       `{"id":1,"url":"https://github.com/a/b","description":"new issue","tgChatIds":[1]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "update without chats",
+			path:       "/updates",
+			body:       `{"id":1,"url":"https://github.com/a/b","description":"new issue","tgChatIds":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update is not delivered",
+			path:       "/updates",
+			body:       `{"id":1,"url":"https://github.com/a/b","description":"new issue","tgChatIds":[2]}`,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "batch of updates",
+			path:       "/updates/batch",
+			body:       `[{"id":1,"url":"https://github.com/a/b","description":"new issue","tgChatIds":[1],"idempotencyKey":"outbox-1"}]`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec, err := validator.Serve(handler, req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
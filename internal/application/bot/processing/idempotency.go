@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeliveredUpdates remembers which chats an update was already sent to,
+// so a retried update does not produce the same Telegram message twice.
+type DeliveredUpdates struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	sent      map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewDeliveredUpdates(ttl time.Duration) *DeliveredUpdates {
+	return &DeliveredUpdates{
+		ttl:  ttl,
+		sent: make(map[string]time.Time),
+	}
+}
+
+// TryReserve marks the update as sent to the chat unless it was sent or is being sent already.
+// The update is reserved before it's sent, so two retries running at once do not both send it;
+// Release gives the reservation back when sending fails.
+func (d *DeliveredUpdates) TryReserve(key string, chatID int64, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sentAt, ok := d.sent[deliveryKey(key, chatID)]
+	if ok && now.Sub(sentAt) < d.ttl {
+		return false
+	}
+
+	d.sent[deliveryKey(key, chatID)] = now
+
+	if now.Sub(d.lastSweep) < d.ttl {
+		return true
+	}
+
+	for k, sentAt := range d.sent {
+		if now.Sub(sentAt) >= d.ttl {
+			delete(d.sent, k)
+		}
+	}
+
+	d.lastSweep = now
+
+	return true
+}
+
+func (d *DeliveredUpdates) Release(key string, chatID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.sent, deliveryKey(key, chatID))
+}
+
+func deliveryKey(key string, chatID int64) string {
+	return key + "/" + strconv.FormatInt(chatID, 10)
+}
@@ -0,0 +1,166 @@
+package processing
+
+import (
+	"context"
+	"go-progira/internal/domain/botmessages"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/domain/types/telegramtypes"
+	"log/slog"
+	"strings"
+)
+
+const (
+	listPageSize = 5
+	// Telegram keeps up to 64 bytes of callback data, so the cursors stay in listPage.
+	listNextData = "list:next"
+	listPrevData = "list:prev"
+)
+
+// listPage is the page of links shown in a chat by the last /list command.
+type listPage struct {
+	next string
+	prev string
+}
+
+var listSorts = map[string]bool{
+	scrappertypes.SortAdded:    true,
+	scrappertypes.SortActivity: true,
+	scrappertypes.SortURL:      true,
+	scrappertypes.SortProvider: true,
+}
+
+var listOrders = map[string]bool{
+	scrappertypes.OrderAsc:  true,
+	scrappertypes.OrderDesc: true,
+}
+
+// processListCommand sends the first page of links sorted as /list [sort] [order] asks.
+func (m Manager) processListCommand(ctx context.Context, id int, given []string) {
+	request := scrappertypes.GetLinksRequest{Limit: listPageSize}
+
+	if len(given) > 0 {
+		request.Sort = given[0]
+	}
+
+	if len(given) > 1 {
+		request.Order = given[1]
+	}
+
+	if len(given) > 2 || request.Sort != "" && !listSorts[request.Sort] || request.Order != "" && !listOrders[request.Order] {
+		m.sendMessage(ctx, id, botmessages.MsgUnknownListSort)
+
+		return
+	}
+
+	links, err := m.ScrapClient.GetLinks(ctx, int64(id), request)
+	if err != nil {
+		m.sendListError(ctx, id, err)
+
+		return
+	}
+
+	if len(links.Links) == 0 {
+		delete(m.pages, id)
+		m.sendMessage(ctx, id, botmessages.MsgNoSavedPages)
+
+		return
+	}
+
+	m.pages[id] = &listPage{next: links.NextCursor, prev: links.PrevCursor}
+
+	err = m.TgClient.SendKeyboard(ctx, id, MakeLinkList(links.Links), listKeyboard(links))
+	if err != nil {
+		slog.Error("Error sending message",
+			slog.String("error", err.Error()))
+	}
+}
+
+// HandleCallback turns the page of links listed in the message whose button was pressed,
+// or answers the preview of an import.
+func (m Manager) HandleCallback(ctx context.Context, query *telegramtypes.CallbackQuery) {
+	if strings.HasPrefix(query.Data, importCallbackPrefix) {
+		m.handleImportCallback(ctx, query)
+
+		return
+	}
+
+	id := query.Message.Chat.ID
+
+	var cursor string
+
+	if page, ok := m.pages[id]; ok {
+		switch query.Data {
+		case listNextData:
+			cursor = page.next
+		case listPrevData:
+			cursor = page.prev
+		}
+	}
+
+	if cursor == "" {
+		m.answerCallback(ctx, query.ID, botmessages.MsgListExpired)
+
+		return
+	}
+
+	links, err := m.ScrapClient.GetLinks(ctx, int64(id), scrappertypes.GetLinksRequest{Cursor: cursor, Limit: listPageSize})
+	if err != nil {
+		slog.Error("Error getting links",
+			slog.String("error", err.Error()))
+
+		m.answerCallback(ctx, query.ID, botmessages.MsgErrGetLinks)
+
+		return
+	}
+
+	text := MakeLinkList(links.Links)
+	if len(links.Links) == 0 {
+		text = botmessages.MsgNoSavedPages
+	}
+
+	m.pages[id] = &listPage{next: links.NextCursor, prev: links.PrevCursor}
+
+	err = m.TgClient.EditMessage(ctx, id, query.Message.MessageID, text, listKeyboard(links))
+	if err != nil {
+		slog.Error("Error editing message",
+			slog.String("error", err.Error()))
+	}
+
+	m.answerCallback(ctx, query.ID, "")
+}
+
+// listKeyboard has a button for each page next to the listed one.
+func listKeyboard(links *scrappertypes.ListLinksResponse) telegramtypes.InlineKeyboardMarkup {
+	row := []telegramtypes.InlineKeyboardButton{}
+
+	if links.PrevCursor != "" {
+		row = append(row, telegramtypes.InlineKeyboardButton{Text: botmessages.BtnPrevPage, CallbackData: listPrevData})
+	}
+
+	if links.NextCursor != "" {
+		row = append(row, telegramtypes.InlineKeyboardButton{Text: botmessages.BtnNextPage, CallbackData: listNextData})
+	}
+
+	keyboard := telegramtypes.InlineKeyboardMarkup{InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{}}
+	if len(row) != 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	return keyboard
+}
+
+func (m Manager) sendMessage(ctx context.Context, id int, msg string) {
+	err := m.TgClient.SendMessage(ctx, id, msg)
+	if err != nil {
+		slog.Error("Error sending message",
+			slog.String("error", err.Error()))
+	}
+}
+
+func (m Manager) answerCallback(ctx context.Context, callbackID, text string) {
+	err := m.TgClient.AnswerCallback(ctx, callbackID, text)
+	if err != nil {
+		slog.Error("Error answering callback",
+			slog.String("error", err.Error()))
+	}
+}
@@ -1,6 +1,7 @@
 package processing
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,12 +10,17 @@ import (
 	"go-progira/internal/domain/botmessages"
 	"go-progira/internal/domain/types/scrappertypes"
 	"go-progira/internal/domain/types/telegramtypes"
+	"go-progira/internal/health"
 	"go-progira/pkg/e"
 	"log/slog"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type StateChange func(id int, text string)
+type StateChange func(ctx context.Context, id int, text string)
 
 type State uint8
 
@@ -31,8 +37,21 @@ type Manager struct {
 	States      map[int]State
 	handlers    map[State]StateChange
 	addRequests map[int]*scrappertypes.AddLinkRequest
+	// pages keeps the cursors of the last page of links listed in a chat.
+	pages map[int]*listPage
+	// imports keeps the file previewed in a chat until its import is confirmed.
+	imports map[int]*pendingImport
+	// Polls beats after every successful getUpdates.
+	Polls *health.Heartbeat
 }
 
+const (
+	minTrackInterval = time.Minute
+	pollRetryDelay   = time.Second
+)
+
+var errWrongInterval = errors.New("wrong check interval")
+
 type URLValidator func(string) bool
 
 var validators = []URLValidator{
@@ -42,52 +61,69 @@ var validators = []URLValidator{
 
 func NewManager(tgClient clients.HTTPTelegramClient, scrapClient clients.HTTPScrapperClient) *Manager {
 	return &Manager{
-		tgClient,
-		scrapClient,
-		make(map[int]State),
-		make(map[State]StateChange),
-		make(map[int]*scrappertypes.AddLinkRequest),
+		TgClient:    tgClient,
+		ScrapClient: scrapClient,
+		States:      make(map[int]State),
+		handlers:    make(map[State]StateChange),
+		addRequests: make(map[int]*scrappertypes.AddLinkRequest),
+		pages:       make(map[int]*listPage),
+		imports:     make(map[int]*pendingImport),
+		Polls:       &health.Heartbeat{},
 	}
 }
 
-func (m Manager) SetBotCommands() {
+func (m Manager) SetBotCommands(ctx context.Context) {
 	commands := []telegramtypes.BotCommand{
 		{Command: "/track", Description: "Начать отслеживать ссылку"},
 		{Command: "/untrack", Description: "Перестать отслеживать ссылку"},
 		{Command: "/list", Description: "Показать отслеживаемые ссылки"},
 		{Command: "/listbytags", Description: "Показать отслеживаемые ссылки с введёнными тегами"},
 		{Command: "/deletetag", Description: "Удалить введённый тег"},
+		{Command: "/tags", Description: "Показать теги и число ссылок с ними"},
+		{Command: "/renametag", Description: "Переименовать тег"},
+		{Command: "/mergetags", Description: "Перенести ссылки одного тега в другой"},
+		{Command: "/tag", Description: "Добавить или убрать теги ссылки"},
+		{Command: "/filter", Description: "Добавить или убрать фильтры ссылки"},
+		{Command: "/refresh", Description: "Проверить ссылки прямо сейчас"},
+		{Command: "/enable", Description: "Снова отслеживать отключённую ссылку"},
+		{Command: "/export", Description: "Выгрузить ссылки в файл"},
+		{Command: "/import", Description: "Добавить ссылки из файла"},
 		{Command: "/help", Description: "Справка"},
 	}
 
-	errSet := m.TgClient.SetBotCommands(commands)
+	errSet := m.TgClient.SetBotCommands(ctx, commands)
 	if errSet != nil {
 		slog.Error("Setting bot commands wasn't worked successfully")
 		slog.String("error", errSet.Error())
 	}
 }
 
-func (m Manager) HandleAwaitingStart(id int, text string) {
+func (m Manager) HandleAwaitingStart(ctx context.Context, id int, text string) {
 	parts := strings.Fields(text)
+	if len(parts) == 0 {
+		m.processUnknownCommand(ctx, id)
+
+		return
+	}
 
 	switch parts[0] {
 	case "/start":
 		m.States[id] = StateStart
 
-		m.ScrapClient.RegisterChat(int64(id))
+		m.ScrapClient.RegisterChat(ctx, int64(id))
 
-		err := m.TgClient.SendMessage(id, botmessages.MsgHello)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgHello)
 		if err != nil {
 			slog.Error("Error send mes to tg" + err.Error())
 
 			return
 		}
 
-		m.SetBotCommands()
+		m.SetBotCommands(ctx)
 	case "/help":
-		m.SendHelp(id)
+		m.SendHelp(ctx, id)
 	default:
-		err := m.TgClient.SendMessage(id, botmessages.MsgUnknownCommand)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgUnknownCommand)
 		if err != nil {
 			return
 		}
@@ -104,34 +140,26 @@ func isValidURL(str string) bool {
 	return false
 }
 
-func (m Manager) processListCommand(id int) {
-	links, err := m.ScrapClient.GetLinks(int64(id))
-	if err != nil {
+// sendListError tells the chat why its links can't be listed.
+func (m Manager) sendListError(ctx context.Context, id int, err error) {
+	msg := botmessages.MsgErrGetLinks
+
+	if errors.Is(err, e.ErrChatNotFound) {
+		msg = botmessages.MsgChatNotRegistered
+	} else {
 		slog.Error("Error getting links",
 			slog.String("error", err.Error()))
-		return
 	}
 
-	if len(links.Links) == 0 {
-		err = m.TgClient.SendMessage(id, botmessages.MsgNoSavedPages)
-		if err != nil {
-			slog.Error("Error sending message",
-				slog.String("error", err.Error()))
-		}
-	} else {
-		linkList := MakeLinkList(links.Links)
-
-		err := m.TgClient.SendMessage(id, linkList)
-		if err != nil {
-			slog.Error("Error sending message",
-				slog.String("error", err.Error()))
-		}
+	if errSend := m.TgClient.SendMessage(ctx, id, msg); errSend != nil {
+		slog.Error("Error sending message",
+			slog.String("error", errSend.Error()))
 	}
 }
 
-func (m Manager) processListByTagCommand(id int, tags []string) {
+func (m Manager) processListByTagCommand(ctx context.Context, id int, tags []string) {
 	if len(tags) == 0 {
-		err := m.TgClient.SendMessage(id, botmessages.MsgNoTags)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgNoTags)
 		if err != nil {
 			slog.Error("Error sending message",
 				slog.String("error", err.Error()))
@@ -142,10 +170,9 @@ func (m Manager) processListByTagCommand(id int, tags []string) {
 
 	getLinksRequest := scrappertypes.GetLinksByTagsRequest{Tags: tags}
 
-	links, err := m.ScrapClient.GetLinksByTag(int64(id), getLinksRequest)
+	links, err := m.ScrapClient.GetLinksByTag(ctx, int64(id), getLinksRequest)
 	if err != nil {
-		slog.Error("Error getting links",
-			slog.String("error", err.Error()))
+		m.sendListError(ctx, id, err)
 
 		return
 	}
@@ -162,23 +189,23 @@ func (m Manager) processListByTagCommand(id int, tags []string) {
 		msg = MakeLinkList(links.Links)
 	}
 
-	err = m.TgClient.SendMessage(id, msg)
+	err = m.TgClient.SendMessage(ctx, id, msg)
 	if err != nil {
 		slog.Error("Error sending message",
 			slog.String("error", err.Error()))
 	}
 }
 
-func (m Manager) processDeleteTag(id int, given []string) {
+func (m Manager) processDeleteTag(ctx context.Context, id int, given []string) {
 	if len(given) == 0 {
-		errSendMes := m.TgClient.SendMessage(id, botmessages.MsgNoTag)
+		errSendMes := m.TgClient.SendMessage(ctx, id, botmessages.MsgNoTag)
 		if errSendMes != nil {
 			slog.Error("Error sending message" + errSendMes.Error())
 		}
 
 		return
 	} else if len(given) > 1 {
-		errSendMes := m.TgClient.SendMessage(id, botmessages.MsgTooManyTags)
+		errSendMes := m.TgClient.SendMessage(ctx, id, botmessages.MsgTooManyTags)
 		if errSendMes != nil {
 			slog.Error("Error sending message" + errSendMes.Error())
 		}
@@ -190,7 +217,7 @@ func (m Manager) processDeleteTag(id int, given []string) {
 
 	deleteTagRequest := scrappertypes.DeleteTagRequest{Tag: tag}
 
-	err := m.ScrapClient.DeleteTag(int64(id), deleteTagRequest)
+	err := m.ScrapClient.DeleteTag(ctx, int64(id), deleteTagRequest)
 
 	var msg string
 
@@ -203,36 +230,211 @@ func (m Manager) processDeleteTag(id int, given []string) {
 		msg = botmessages.MsgTagDeleteFailed
 	}
 
-	err = m.TgClient.SendMessage(id, msg)
+	err = m.TgClient.SendMessage(ctx, id, msg)
 	if err != nil {
 		slog.Error("Error sending message" + err.Error())
 	}
 }
 
-func (m Manager) handleStart(id int, text string) {
+// processUpdateLinkCommand changes tags, or filters, of a link as /tag link +added -removed asks.
+func (m Manager) processUpdateLinkCommand(ctx context.Context, id int, given []string, filters bool) {
+	usage := botmessages.MsgTagUsage
+	if filters {
+		usage = botmessages.MsgFilterUsage
+	}
+
+	if len(given) < 2 {
+		m.sendMessage(ctx, id, usage)
+
+		return
+	}
+
+	added, removed, ok := parseLabelChange(given[1:])
+	if !ok {
+		m.sendMessage(ctx, id, usage)
+
+		return
+	}
+
+	request := scrappertypes.UpdateLinkRequest{Link: given[0]}
+	if filters {
+		request.AddFilters, request.RemoveFilters = added, removed
+	} else {
+		request.AddTags, request.RemoveTags = added, removed
+	}
+
+	link, err := m.ScrapClient.UpdateLink(ctx, int64(id), request)
+
+	var msg string
+
+	switch {
+	case err == nil:
+		msg = fmt.Sprintf(botmessages.MsgLinkUpdated, labelList(link.Tags), labelList(link.Filters))
+	case errors.Is(err, e.ErrLinkNotFound):
+		msg = botmessages.MsgLinkNotFound
+	case errors.Is(err, e.ErrInvalidRequest):
+		msg = usage
+	default:
+		msg = botmessages.MsgErrUpdateLink
+
+		slog.Error("Error updating link",
+			slog.String("error", err.Error()),
+			slog.String("link", request.Link))
+	}
+
+	m.sendMessage(ctx, id, msg)
+}
+
+// parseLabelChange splits "+added -removed" arguments, every one of them needs a sign and a name.
+func parseLabelChange(args []string) (added, removed []string, ok bool) {
+	for _, arg := range args {
+		if len(arg) < 2 {
+			return nil, nil, false
+		}
+
+		switch arg[0] {
+		case '+':
+			added = append(added, arg[1:])
+		case '-':
+			removed = append(removed, arg[1:])
+		default:
+			return nil, nil, false
+		}
+	}
+
+	return added, removed, true
+}
+
+func labelList(labels []string) string {
+	if len(labels) == 0 {
+		return botmessages.MsgNoLabels
+	}
+
+	return strings.Join(labels, ", ")
+}
+
+func (m Manager) handleStart(ctx context.Context, id int, text string) {
 	parts := strings.Fields(text)
+	if len(parts) == 0 {
+		m.processUnknownCommand(ctx, id)
+
+		return
+	}
 
 	switch parts[0] {
 	case "/track":
-		m.processTrackCommand(id, parts[1:])
+		m.processTrackCommand(ctx, id, parts[1:])
 	case "/untrack":
-		m.processUntrackCommand(id, parts[1:])
+		m.processUntrackCommand(ctx, id, parts[1:])
 	case "/list":
-		m.processListCommand(id)
+		m.processListCommand(ctx, id, parts[1:])
 	case "/listbytags":
-		m.processListByTagCommand(id, parts[1:])
+		m.processListByTagCommand(ctx, id, parts[1:])
 	case "/deletetag":
-		m.processDeleteTag(id, parts[1:])
+		m.processDeleteTag(ctx, id, parts[1:])
+	case "/tags":
+		m.processTagsCommand(ctx, id, parts[1:])
+	case "/renametag":
+		m.processRenameTagCommand(ctx, id, parts[1:], false)
+	case "/mergetags":
+		m.processRenameTagCommand(ctx, id, parts[1:], true)
+	case "/tag":
+		m.processUpdateLinkCommand(ctx, id, parts[1:], false)
+	case "/filter":
+		m.processUpdateLinkCommand(ctx, id, parts[1:], true)
+	case "/refresh":
+		m.processRefreshCommand(ctx, id, parts[1:])
+	case "/enable":
+		m.processEnableCommand(ctx, id, parts[1:])
+	case "/export":
+		m.processExportCommand(ctx, id, parts[1:])
+	case "/import":
+		m.sendMessage(ctx, id, botmessages.MsgImportUsage)
 	case "/help":
-		m.SendHelp(id)
+		m.SendHelp(ctx, id)
+	default:
+		m.processUnknownCommand(ctx, id)
+	}
+}
+
+func (m Manager) processRefreshCommand(ctx context.Context, id int, given []string) {
+	if len(given) > 1 {
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgUnknownCommand)
+		if err != nil {
+			slog.Error("Error sending message" + err.Error())
+		}
+
+		return
+	}
+
+	var request scrappertypes.RefreshRequest
+
+	if len(given) == 1 {
+		request.Link = given[0]
+	}
+
+	response, err := m.ScrapClient.RefreshLinks(ctx, int64(id), request)
+
+	var msg string
+
+	switch {
+	case err == nil && len(response.Updated) != 0:
+		msg = fmt.Sprintf(botmessages.MsgRefreshUpdated, strings.Join(response.Updated, "\n"))
+	case err == nil && response.Checked == 0 && response.InProgress != 0:
+		msg = botmessages.MsgRefreshInProgress
+	case err == nil && response.Checked == 0 && len(response.Broken) != 0:
+		msg = botmessages.MsgRefreshBroken
+	case err == nil:
+		msg = botmessages.MsgRefreshNothingNew
+	case errors.Is(err, e.ErrLinkNotFound):
+		msg = botmessages.MsgLinkNotFound
+	case errors.Is(err, e.ErrRefreshTooOften):
+		msg = botmessages.MsgRefreshTooOften
+	default:
+		msg = botmessages.MsgErrRefresh
+
+		slog.Error("Error refreshing links",
+			slog.String("error", err.Error()))
+	}
+
+	errSendMes := m.TgClient.SendMessage(ctx, id, msg)
+	if errSendMes != nil {
+		slog.Error("Error sending message" + errSendMes.Error())
+	}
+}
+
+func (m Manager) processEnableCommand(ctx context.Context, id int, given []string) {
+	if len(given) == 0 {
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgGotNoLink)
+		if err != nil {
+			slog.Error("Error sending message" + err.Error())
+		}
+
+		return
+	}
+
+	err := m.ScrapClient.EnableLink(ctx, int64(id), scrappertypes.EnableLinkRequest{Link: given[0]})
+
+	var msg string
+
+	switch {
+	case err == nil:
+		msg = botmessages.MsgEnabled
+	case errors.Is(err, e.ErrLinkNotFound):
+		msg = botmessages.MsgLinkNotFound
 	default:
-		m.processUnknownCommand(id)
+		msg = botmessages.MsgErrEnableLink
+	}
+
+	errSendMes := m.TgClient.SendMessage(ctx, id, msg)
+	if errSendMes != nil {
+		slog.Error("Error sending message" + errSendMes.Error())
 	}
 }
 
-func (m Manager) processTrackCommand(id int, given []string) {
+func (m Manager) processTrackCommand(ctx context.Context, id int, given []string) {
 	if len(given) == 0 {
-		err := m.TgClient.SendMessage(id, botmessages.MsgGotNoLink)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgGotNoLink)
 		if err != nil {
 			slog.Error("Error sending message" + err.Error())
 		}
@@ -243,7 +445,17 @@ func (m Manager) processTrackCommand(id int, given []string) {
 	link := given[0]
 
 	if !isValidURL(link) {
-		err := m.TgClient.SendMessage(id, botmessages.MsgWrongFormatLink)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgWrongFormatLink)
+		if err != nil {
+			slog.Error("Error sending message" + err.Error())
+		}
+
+		return
+	}
+
+	interval, errInterval := parseInterval(given[1:])
+	if errInterval != nil {
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgWrongInterval)
 		if err != nil {
 			slog.Error("Error sending message" + err.Error())
 		}
@@ -251,9 +463,9 @@ func (m Manager) processTrackCommand(id int, given []string) {
 		return
 	}
 
-	m.addRequests[id] = &scrappertypes.AddLinkRequest{Link: link}
+	m.addRequests[id] = &scrappertypes.AddLinkRequest{Link: link, IntervalSeconds: int64(interval.Seconds())}
 
-	err := m.TgClient.SendMessage(id, botmessages.MsgAddTags)
+	err := m.TgClient.SendMessage(ctx, id, botmessages.MsgAddTags)
 	if err != nil {
 		slog.Error("Error sending message" + err.Error())
 
@@ -263,9 +475,27 @@ func (m Manager) processTrackCommand(id int, given []string) {
 	m.States[id] = stateAwaitingTagsForTrack
 }
 
-func (m Manager) processUntrackCommand(id int, given []string) {
+// parseInterval parses the optional "every <duration>" part of /track command.
+func parseInterval(args []string) (time.Duration, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	if len(args) != 2 || args[0] != "every" {
+		return 0, errWrongInterval
+	}
+
+	interval, err := time.ParseDuration(args[1])
+	if err != nil || interval < minTrackInterval {
+		return 0, errWrongInterval
+	}
+
+	return interval, nil
+}
+
+func (m Manager) processUntrackCommand(ctx context.Context, id int, given []string) {
 	if len(given) == 0 {
-		err := m.TgClient.SendMessage(id, botmessages.MsgGotNoLink)
+		err := m.TgClient.SendMessage(ctx, id, botmessages.MsgGotNoLink)
 		if err != nil {
 			slog.Error("Error sending message" + err.Error())
 		}
@@ -277,7 +507,7 @@ func (m Manager) processUntrackCommand(id int, given []string) {
 
 	delReq := scrappertypes.RemoveLinkRequest{Link: link}
 
-	err := m.ScrapClient.RemoveLink(int64(id), delReq)
+	err := m.ScrapClient.RemoveLink(ctx, int64(id), delReq)
 
 	var msg string
 
@@ -294,14 +524,14 @@ func (m Manager) processUntrackCommand(id int, given []string) {
 			slog.String("link", link))
 	}
 
-	errSendMes := m.TgClient.SendMessage(id, msg)
+	errSendMes := m.TgClient.SendMessage(ctx, id, msg)
 	if errSendMes != nil {
 		slog.Error("Error sending message" + errSendMes.Error())
 	}
 }
 
-func (m Manager) processUnknownCommand(id int) {
-	err := m.TgClient.SendMessage(id, botmessages.MsgUnknownCommand)
+func (m Manager) processUnknownCommand(ctx context.Context, id int) {
+	err := m.TgClient.SendMessage(ctx, id, botmessages.MsgUnknownCommand)
 	if err != nil {
 		slog.Error("Error sending message" + err.Error())
 	}
@@ -322,15 +552,27 @@ func MakeLinkList(links []scrappertypes.LinkResponse) string {
 			filterString = fmt.Sprintf("Filters: %s\n", strings.Join(linkResp.Filters, ", "))
 		}
 
-		rec := fmt.Sprintf("Link: %s\n%s%s", linkResp.URL, tagString, filterString)
+		rec := fmt.Sprintf("Link: %s\n%s%s%s", linkResp.URL, tagString, filterString, healthString(linkResp))
 		linksToSend.WriteString(rec)
 	}
 
 	return linksToSend.String()
 }
 
-func (m Manager) SendHelp(id int) {
-	err := m.TgClient.SendMessage(id, botmessages.MsgHelp)
+// healthString describes the link if its last checks failed.
+func healthString(link scrappertypes.LinkResponse) string {
+	switch {
+	case link.Broken:
+		return fmt.Sprintf("Status: disabled, %s\n", link.LastError)
+	case link.ConsecutiveFailures > 0:
+		return fmt.Sprintf("Status: %d failed checks in a row, %s\n", link.ConsecutiveFailures, link.LastError)
+	default:
+		return ""
+	}
+}
+
+func (m Manager) SendHelp(ctx context.Context, id int) {
+	err := m.TgClient.SendMessage(ctx, id, botmessages.MsgHelp)
 	if err != nil {
 		return
 	}
@@ -340,11 +582,11 @@ func splitByWords(text string) []string {
 	return strings.Fields(text)
 }
 
-func (m Manager) handleAwaitingTagsForTrack(id int, text string) {
+func (m Manager) handleAwaitingTagsForTrack(ctx context.Context, id int, text string) {
 	tags := splitByWords(text)
 	m.addRequests[id].Tags = tags
 
-	err := m.TgClient.SendMessage(id, botmessages.MsgAddFilters)
+	err := m.TgClient.SendMessage(ctx, id, botmessages.MsgAddFilters)
 	if err != nil {
 		return
 	}
@@ -352,24 +594,39 @@ func (m Manager) handleAwaitingTagsForTrack(id int, text string) {
 	m.States[id] = stateAwaitingFiltersForTrack
 }
 
-func (m Manager) handleAwaitingFiltersForTrack(id int, text string) {
+func (m Manager) handleAwaitingFiltersForTrack(ctx context.Context, id int, text string) {
 	filters := splitByWords(text)
 	m.addRequests[id].Filters = filters
 	m.States[id] = StateStart
-	errAdd := m.ScrapClient.AddLink(int64(id), *m.addRequests[id])
+	link, errAdd := m.ScrapClient.AddLink(ctx, int64(id), *m.addRequests[id])
 
 	var msg string
 
 	switch {
 	case errAdd == nil:
 		msg = botmessages.MsgSaved
+		if link != nil && link.Title != "" {
+			msg = fmt.Sprintf(botmessages.MsgSavedWithTitle, link.Title)
+		}
 	case errors.Is(errAdd, e.ErrLinkAlreadyExists):
 		msg = botmessages.MsgLinkAlreadyExists
-	case errors.Is(errAdd, e.ErrAddLink):
+	case errors.Is(errAdd, e.ErrResourceNotFound):
+		msg = botmessages.MsgResourceNotFound
+	case errors.Is(errAdd, e.ErrResourcePrivate):
+		msg = botmessages.MsgResourcePrivate
+	case errors.Is(errAdd, e.ErrRateLimited):
+		msg = botmessages.MsgRateLimited
+	case errors.Is(errAdd, e.ErrProviderUnavailable):
+		msg = botmessages.MsgProviderDown
+	case errors.Is(errAdd, e.ErrWrongURLFormat):
+		msg = botmessages.MsgWrongFormatLink
+	case errors.Is(errAdd, e.ErrChatNotFound):
+		msg = botmessages.MsgChatNotRegistered
+	default:
 		msg = botmessages.MsgErrAddLink
 	}
 
-	errSendMes := m.TgClient.SendMessage(id, msg)
+	errSendMes := m.TgClient.SendMessage(ctx, id, msg)
 	if errSendMes != nil {
 		slog.Error("Error sending message to tg:" + errSendMes.Error())
 		return
@@ -391,13 +648,14 @@ func (m Manager) getUserState(id int) State {
 	return m.States[id]
 }
 
-func (m Manager) Start() {
+// Start polls Telegram for messages until ctx is canceled.
+func (m Manager) Start(ctx context.Context) {
 	commands := []telegramtypes.BotCommand{
 		{Command: "/start", Description: "Запуск бота"},
 		{Command: "/help", Description: "Справка"},
 	}
 
-	err := m.TgClient.SetBotCommands(commands)
+	err := m.TgClient.SetBotCommands(ctx, commands)
 	if err != nil {
 		slog.Error("Setting bot commands wasn't worked successfully" + err.Error())
 	}
@@ -406,8 +664,19 @@ func (m Manager) Start() {
 
 	var offset int
 
-	for {
-		data, _ := m.TgClient.Updates(offset, 1)
+	for ctx.Err() == nil {
+		data, err := m.TgClient.Updates(ctx, offset, 1)
+		if err != nil {
+			select {
+			case <-time.After(pollRetryDelay):
+			case <-ctx.Done():
+			}
+
+			continue
+		}
+
+		m.Polls.Beat(time.Now())
+
 		upds := telegramtypes.UpdatesResponse{}
 
 		if err := json.Unmarshal(data, &upds); err != nil {
@@ -416,14 +685,51 @@ func (m Manager) Start() {
 		}
 
 		for _, res := range upds.Result {
-			if res.Message != nil {
-				id := res.Message.Chat.ID
-				state := m.getUserState(id)
-
-				m.handlers[state](id, res.Message.Text)
+			m.handleUpdate(ctx, res)
 
-				offset = res.ID + 1
-			}
+			offset = res.ID + 1
 		}
 	}
 }
+
+// handleUpdate passes a message to the handler of the chat's state and a pressed button to HandleCallback.
+func (m Manager) handleUpdate(ctx context.Context, update telegramtypes.Update) {
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		messagesReceived.WithLabelValues("callback").Inc()
+
+		callbackCtx, span := tracer.Start(ctx, "telegram.callback", trace.WithAttributes(
+			attribute.String("bot.callback", update.CallbackQuery.Data),
+			attribute.Int("telegram.chat_id", update.CallbackQuery.Message.Chat.ID)))
+
+		m.HandleCallback(callbackCtx, update.CallbackQuery)
+
+		span.End()
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	id := update.Message.Chat.ID
+	state := m.getUserState(id)
+
+	command := commandLabel(update.Message.Text)
+	if update.Message.Document != nil {
+		command = "document"
+	}
+
+	messagesReceived.WithLabelValues(command).Inc()
+
+	msgCtx, span := tracer.Start(ctx, "telegram.message", trace.WithAttributes(
+		attribute.String("bot.command", command),
+		attribute.Int("telegram.chat_id", id)))
+	defer span.End()
+
+	if update.Message.Document != nil && state == StateStart {
+		m.handleDocument(msgCtx, id, update.Message.Document)
+
+		return
+	}
+
+	m.handlers[state](msgCtx, id, update.Message.Text)
+}
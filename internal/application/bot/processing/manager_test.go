@@ -1,14 +1,20 @@
 package processing_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"go-progira/internal/application/bot/clients"
 	"go-progira/internal/application/bot/processing"
 	"go-progira/internal/domain/botmessages"
 	"go-progira/internal/domain/types/scrappertypes"
 	"go-progira/internal/domain/types/telegramtypes"
+	"go-progira/pkg/e"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandleAwaitingStart_Start(t *testing.T) {
@@ -46,11 +52,22 @@ func TestHandleAwaitingStart_Start(t *testing.T) {
 			{Command: "/track", Description: "Начать отслеживать ссылку"},
 			{Command: "/untrack", Description: "Перестать отслеживать ссылку"},
 			{Command: "/list", Description: "Показать отслеживаемые ссылки"},
+			{Command: "/listbytags", Description: "Показать отслеживаемые ссылки с введёнными тегами"},
+			{Command: "/deletetag", Description: "Удалить введённый тег"},
+			{Command: "/tags", Description: "Показать теги и число ссылок с ними"},
+			{Command: "/renametag", Description: "Переименовать тег"},
+			{Command: "/mergetags", Description: "Перенести ссылки одного тега в другой"},
+			{Command: "/tag", Description: "Добавить или убрать теги ссылки"},
+			{Command: "/filter", Description: "Добавить или убрать фильтры ссылки"},
+			{Command: "/refresh", Description: "Проверить ссылки прямо сейчас"},
+			{Command: "/enable", Description: "Снова отслеживать отключённую ссылку"},
+			{Command: "/export", Description: "Выгрузить ссылки в файл"},
+			{Command: "/import", Description: "Добавить ссылки из файла"},
 			{Command: "/help", Description: "Справка"},
 		}
 		mockTg.On("SetBotCommands", commands).Return(nil)
 
-		manager.HandleAwaitingStart(testCase.chatID, "/start")
+		manager.HandleAwaitingStart(context.Background(), testCase.chatID, "/start")
 
 		assert.Equal(t, processing.StateStart, manager.States[testCase.chatID])
 
@@ -80,6 +97,12 @@ func TestHandleAwaitingStart_HelpAndUnknown(t *testing.T) {
 			command:  "jump",
 			expected: botmessages.MsgUnknownCommand,
 		},
+		{
+			name:     "message without text",
+			chatID:   12346,
+			command:  "",
+			expected: botmessages.MsgUnknownCommand,
+		},
 	}
 
 	mockTg := new(clients.MockTgClient)
@@ -94,7 +117,7 @@ func TestHandleAwaitingStart_HelpAndUnknown(t *testing.T) {
 	for _, testCase := range testCases {
 		assert.Equal(t, processing.StateAwaitingStart, manager.States[testCase.chatID])
 		mockTg.On("SendMessage", testCase.chatID, testCase.expected).Return(nil)
-		manager.HandleAwaitingStart(testCase.chatID, testCase.command)
+		manager.HandleAwaitingStart(context.Background(), testCase.chatID, testCase.command)
 
 		mockTg.AssertCalled(t, "SendMessage", testCase.chatID, testCase.expected)
 	}
@@ -138,6 +161,26 @@ func TestMakeLinkList(t *testing.T) {
 			},
 			expected: "Link: link\nFilters: filter1, filter2, filter3\n",
 		},
+		{
+			name: "broken link",
+			linkList: []scrappertypes.LinkResponse{
+				{URL: "link",
+					Broken:    true,
+					LastError: "resource not found",
+				},
+			},
+			expected: "Link: link\nStatus: disabled, resource not found\n",
+		},
+		{
+			name: "link with failed checks",
+			linkList: []scrappertypes.LinkResponse{
+				{URL: "link",
+					ConsecutiveFailures: 2,
+					LastError:           "API returned error: status 500",
+				},
+			},
+			expected: "Link: link\nStatus: 2 failed checks in a row, API returned error: status 500\n",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -151,3 +194,328 @@ func TestMakeLinkList(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_ListPages(t *testing.T) {
+	const chatID = 12348
+
+	mockTg := new(clients.MockTgClient)
+	mockScrap := new(clients.MockScrapClient)
+
+	manager := processing.NewManager(mockTg, mockScrap)
+	manager.States[chatID] = processing.StateStart
+
+	first := []scrappertypes.LinkResponse{{URL: "https://github.com/a/b"}}
+	second := []scrappertypes.LinkResponse{{URL: "https://github.com/a/c"}}
+
+	updates := []string{
+		`{"ok":true,"result":[{"update_id":1,"message":{"message_id":10,"text":"/list url desc","chat":{"id":12348}}}]}`,
+		`{"ok":true,"result":[{"update_id":2,"callback_query":{"id":"q1","data":"list:next",` +
+			`"message":{"message_id":11,"chat":{"id":12348}}}}]}`,
+		`{"ok":true,"result":[{"update_id":3,"callback_query":{"id":"q2","data":"list:next",` +
+			`"message":{"message_id":11,"chat":{"id":12348}}}}]}`,
+	}
+	mockScrap.On("GetLinks", int64(chatID), scrappertypes.GetLinksRequest{Sort: "url", Order: "desc", Limit: 5}).
+		Return(&scrappertypes.ListLinksResponse{Links: first, NextCursor: "c1"}, nil)
+	mockScrap.On("GetLinks", int64(chatID), scrappertypes.GetLinksRequest{Cursor: "c1", Limit: 5}).
+		Return(&scrappertypes.ListLinksResponse{Links: second, PrevCursor: "c0"}, nil)
+
+	next := telegramtypes.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{{{Text: botmessages.BtnNextPage, CallbackData: "list:next"}}},
+	}
+	prev := telegramtypes.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{{{Text: botmessages.BtnPrevPage, CallbackData: "list:prev"}}},
+	}
+
+	mockTg.On("SendKeyboard", chatID, processing.MakeLinkList(first), next).Return(nil)
+	mockTg.On("EditMessage", chatID, 11, processing.MakeLinkList(second), prev).Return(nil)
+	mockTg.On("AnswerCallback", "q1", "").Return(nil)
+	mockTg.On("AnswerCallback", "q2", botmessages.MsgListExpired).Return(nil)
+
+	serveUpdates(manager, mockTg, updates...)
+
+	mockTg.AssertExpectations(t)
+	mockScrap.AssertExpectations(t)
+}
+
+// serveUpdates polls the updates, numbered from 1, one by one and stops the manager after the last one.
+func serveUpdates(manager *processing.Manager, mockTg *clients.MockTgClient, updates ...string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i, update := range updates {
+		offset := i + 1
+		if i == 0 {
+			offset = 0
+		}
+
+		mockTg.On("Updates", offset, 1).Return([]byte(update), nil).Once()
+	}
+
+	mockTg.On("Updates", len(updates)+1, 1).Return([]byte(nil), assert.AnError).Run(func(_ mock.Arguments) { cancel() })
+	mockTg.On("SetBotCommands", mock.Anything).Return(nil)
+
+	manager.Start(ctx)
+}
+
+func TestManager_UpdateLink(t *testing.T) {
+	const chatID = 12349
+
+	tests := []struct {
+		name    string
+		command string
+		request *scrappertypes.UpdateLinkRequest
+		link    *scrappertypes.LinkResponse
+		err     error
+		want    string
+	}{
+		{
+			name:    "tags are changed",
+			command: "/tag https://github.com/a/b +go -old",
+			request: &scrappertypes.UpdateLinkRequest{
+				Link:       "https://github.com/a/b",
+				LinkChange: scrappertypes.LinkChange{AddTags: []string{"go"}, RemoveTags: []string{"old"}},
+			},
+			link: &scrappertypes.LinkResponse{Tags: []string{"work", "go"}},
+			want: fmt.Sprintf(botmessages.MsgLinkUpdated, "work, go", botmessages.MsgNoLabels),
+		},
+		{
+			name:    "filters are changed",
+			command: "/filter https://github.com/a/b -user=a",
+			request: &scrappertypes.UpdateLinkRequest{
+				Link:       "https://github.com/a/b",
+				LinkChange: scrappertypes.LinkChange{RemoveFilters: []string{"user=a"}},
+			},
+			link: &scrappertypes.LinkResponse{Filters: []string{"user=b"}},
+			want: fmt.Sprintf(botmessages.MsgLinkUpdated, botmessages.MsgNoLabels, "user=b"),
+		},
+		{
+			name:    "link is not tracked",
+			command: "/tag https://github.com/a/c +go",
+			request: &scrappertypes.UpdateLinkRequest{
+				Link:       "https://github.com/a/c",
+				LinkChange: scrappertypes.LinkChange{AddTags: []string{"go"}},
+			},
+			link: (*scrappertypes.LinkResponse)(nil),
+			err:  e.ErrLinkNotFound,
+			want: botmessages.MsgLinkNotFound,
+		},
+		{
+			name:    "change has no sign",
+			command: "/tag https://github.com/a/b go",
+			want:    botmessages.MsgTagUsage,
+		},
+		{
+			name:    "filter command without changes",
+			command: "/filter https://github.com/a/b",
+			want:    botmessages.MsgFilterUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := new(clients.MockTgClient)
+			mockScrap := new(clients.MockScrapClient)
+
+			manager := processing.NewManager(mockTg, mockScrap)
+			manager.States[chatID] = processing.StateStart
+
+			if tt.request != nil {
+				mockScrap.On("UpdateLink", int64(chatID), *tt.request).Return(tt.link, tt.err)
+			}
+
+			mockTg.On("SendMessage", chatID, tt.want).Return(nil)
+
+			update, err := json.Marshal(telegramtypes.UpdatesResponse{Ok: true, Result: []telegramtypes.Update{{
+				ID:      1,
+				Message: &telegramtypes.Message{Text: tt.command, Chat: telegramtypes.Chat{ID: chatID}},
+			}}})
+			require.NoError(t, err)
+
+			serveUpdates(manager, mockTg, string(update))
+
+			mockTg.AssertExpectations(t)
+			mockScrap.AssertExpectations(t)
+		})
+	}
+}
+
+func TestManager_ExportLinks(t *testing.T) {
+	const chatID = 12350
+
+	mockTg := new(clients.MockTgClient)
+	mockScrap := new(clients.MockScrapClient)
+
+	manager := processing.NewManager(mockTg, mockScrap)
+	manager.States[chatID] = processing.StateStart
+
+	data := []byte("url,tags,filters\n")
+
+	mockScrap.On("ExportLinks", int64(chatID), scrappertypes.FormatCSV).Return(data, nil)
+	mockTg.On("SendDocument", chatID, "links.csv", data, botmessages.MsgExportCaption).Return(nil)
+	mockTg.On("SendMessage", chatID, botmessages.MsgExportUsage).Return(nil)
+
+	serveUpdates(manager, mockTg,
+		`{"ok":true,"result":[{"update_id":1,"message":{"text":"/export CSV","chat":{"id":12350}}}]}`,
+		`{"ok":true,"result":[{"update_id":2,"message":{"text":"/export xml","chat":{"id":12350}}}]}`,
+	)
+
+	mockTg.AssertExpectations(t)
+	mockScrap.AssertExpectations(t)
+}
+
+func TestManager_ImportLinks(t *testing.T) {
+	const chatID = 12351
+
+	mockTg := new(clients.MockTgClient)
+	mockScrap := new(clients.MockScrapClient)
+
+	manager := processing.NewManager(mockTg, mockScrap)
+	manager.States[chatID] = processing.StateStart
+
+	data := "https://github.com/a/b/pulls\nhttps://github.com/a/c/pulls\n"
+	rows := []scrappertypes.ImportRow{
+		{Row: 1, Link: "https://github.com/a/b/pulls", Status: scrappertypes.ImportAdded},
+		{Row: 2, Link: "https://github.com/a/c/pulls", Status: scrappertypes.ImportSkipped, Reason: "link already exists"},
+	}
+	preview := &scrappertypes.ImportResponse{Rows: rows, Added: 1, Skipped: 1, DryRun: true}
+	report := &scrappertypes.ImportResponse{Rows: rows, Added: 1, Skipped: 1}
+
+	summary := fmt.Sprintf(botmessages.MsgImportSummary, 1, 1, 0) +
+		"1. + https://github.com/a/b/pulls\n" +
+		"2. = https://github.com/a/c/pulls - link already exists\n"
+	buttons := telegramtypes.InlineKeyboardMarkup{InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{{
+		{Text: botmessages.BtnImportConfirm, CallbackData: "import:confirm"},
+		{Text: botmessages.BtnImportCancel, CallbackData: "import:cancel"},
+	}}}
+	noButtons := telegramtypes.InlineKeyboardMarkup{InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{}}
+
+	mockTg.On("SendMessage", chatID, botmessages.MsgImportUnknownFormat).Return(nil)
+	mockTg.On("DownloadFile", "f1").Return([]byte(data), nil)
+	mockScrap.On("ImportLinks", int64(chatID), scrappertypes.ImportRequest{Format: "csv", Data: data, DryRun: true}).Return(preview, nil)
+	mockTg.On("SendKeyboard", chatID, fmt.Sprintf(botmessages.MsgImportPreview, "links.csv")+summary, buttons).Return(nil)
+	mockTg.On("AnswerCallback", "q1", "").Return(nil)
+	mockScrap.On("ImportLinks", int64(chatID), scrappertypes.ImportRequest{Format: "csv", Data: data}).Return(report, nil)
+	mockTg.On("EditMessage", chatID, 11, fmt.Sprintf(botmessages.MsgImportDone, "links.csv")+summary, noButtons).Return(nil)
+	mockTg.On("AnswerCallback", "q2", botmessages.MsgImportExpired).Return(nil)
+
+	serveUpdates(manager, mockTg,
+		`{"ok":true,"result":[{"update_id":1,"message":{"chat":{"id":12351},`+
+			`"document":{"file_id":"f0","file_name":"links.xlsx","file_size":10}}}]}`,
+		`{"ok":true,"result":[{"update_id":2,"message":{"chat":{"id":12351},`+
+			`"document":{"file_id":"f1","file_name":"links.csv","file_size":58}}}]}`,
+		`{"ok":true,"result":[{"update_id":3,"callback_query":{"id":"q1","data":"import:confirm",`+
+			`"message":{"message_id":11,"chat":{"id":12351}}}}]}`,
+		`{"ok":true,"result":[{"update_id":4,"callback_query":{"id":"q2","data":"import:confirm",`+
+			`"message":{"message_id":11,"chat":{"id":12351}}}}]}`,
+	)
+
+	mockTg.AssertExpectations(t)
+	mockScrap.AssertExpectations(t)
+}
+
+func TestManager_Tags(t *testing.T) {
+	const chatID = 12352
+
+	mockTg := new(clients.MockTgClient)
+	mockScrap := new(clients.MockScrapClient)
+
+	manager := processing.NewManager(mockTg, mockScrap)
+	manager.States[chatID] = processing.StateStart
+
+	mockScrap.On("GetTags", int64(chatID)).Return(&scrappertypes.ListTagsResponse{
+		Tags: []scrappertypes.TagResponse{{Name: "work", Links: 2}, {Name: "go", Links: 1}},
+		Size: 2,
+	}, nil)
+	mockTg.On("SendMessage", chatID, botmessages.MsgTagsHeader+"work — 2\ngo — 1\n").Return(nil)
+
+	serveUpdates(manager, mockTg, `{"ok":true,"result":[{"update_id":1,"message":{"text":"/tags","chat":{"id":12352}}}]}`)
+
+	mockTg.AssertExpectations(t)
+	mockScrap.AssertExpectations(t)
+}
+
+func TestManager_RenameTag(t *testing.T) {
+	const chatID = 12353
+
+	tests := []struct {
+		name    string
+		command string
+		method  string
+		request any
+		tag     *scrappertypes.TagResponse
+		err     error
+		want    string
+	}{
+		{
+			name:    "tag is renamed",
+			command: "/renametag work job",
+			method:  "RenameTag",
+			request: scrappertypes.RenameTagRequest{Tag: "work", NewName: "job"},
+			tag:     &scrappertypes.TagResponse{Name: "job", Links: 2},
+			want:    fmt.Sprintf(botmessages.MsgTagRenamed, "job", 2),
+		},
+		{
+			name:    "new name is used already",
+			command: "/renametag work go",
+			method:  "RenameTag",
+			request: scrappertypes.RenameTagRequest{Tag: "work", NewName: "go"},
+			tag:     (*scrappertypes.TagResponse)(nil),
+			err:     e.ErrTagAlreadyExists,
+			want:    botmessages.MsgTagAlreadyExists,
+		},
+		{
+			name:    "tags are merged",
+			command: "/mergetags work go",
+			method:  "MergeTags",
+			request: scrappertypes.MergeTagsRequest{Tag: "work", Into: "go"},
+			tag:     &scrappertypes.TagResponse{Name: "go", Links: 3},
+			want:    fmt.Sprintf(botmessages.MsgTagsMerged, "go", 3),
+		},
+		{
+			name:    "tag to merge into is unknown",
+			command: "/mergetags work job",
+			method:  "MergeTags",
+			request: scrappertypes.MergeTagsRequest{Tag: "work", Into: "job"},
+			tag:     (*scrappertypes.TagResponse)(nil),
+			err:     e.ErrTagNotFound,
+			want:    botmessages.MsgMergeTagNotFound,
+		},
+		{
+			name:    "new name is missing",
+			command: "/renametag work",
+			want:    botmessages.MsgRenameTagUsage,
+		},
+		{
+			name:    "tag is merged into itself",
+			command: "/mergetags work work",
+			want:    botmessages.MsgMergeTagsUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := new(clients.MockTgClient)
+			mockScrap := new(clients.MockScrapClient)
+
+			manager := processing.NewManager(mockTg, mockScrap)
+			manager.States[chatID] = processing.StateStart
+
+			if tt.method != "" {
+				mockScrap.On(tt.method, int64(chatID), tt.request).Return(tt.tag, tt.err)
+			}
+
+			mockTg.On("SendMessage", chatID, tt.want).Return(nil)
+
+			update, err := json.Marshal(telegramtypes.UpdatesResponse{Ok: true, Result: []telegramtypes.Update{{
+				ID:      1,
+				Message: &telegramtypes.Message{Text: tt.command, Chat: telegramtypes.Chat{ID: chatID}},
+			}}})
+			require.NoError(t, err)
+
+			serveUpdates(manager, mockTg, string(update))
+
+			mockTg.AssertExpectations(t)
+			mockScrap.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package processing
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	messagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bot_telegram_messages_received_total",
+		Help: `Telegram messages received, by command. Messages that are not commands are labeled "text", files "document".`,
+	}, []string{"command"})
+
+	updatesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bot_updates_received_total",
+		Help: "Link updates received from the scrapper, by transport and delivery status.",
+	}, []string{"transport", "status"})
+)
+
+var knownCommands = map[string]bool{
+	"/start": true, "/help": true, "/track": true, "/untrack": true, "/list": true, "/listbytags": true,
+	"/deletetag": true, "/refresh": true, "/enable": true, "/tag": true, "/filter": true, "/export": true, "/import": true,
+	"/tags": true, "/renametag": true, "/mergetags": true,
+}
+
+// commandLabel keeps the number of label values small whatever users type.
+func commandLabel(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "text"
+	}
+
+	if command := strings.ToLower(fields[0]); knownCommands[command] {
+		return command
+	}
+
+	return "unknown"
+}
@@ -1,9 +1,17 @@
 package processing
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"go-progira/internal/api/openapi/v1/botapi"
 	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/auth"
 	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/health"
+	"go-progira/internal/metrics"
+	"go-progira/internal/tracing"
 	"go-progira/pkg/config"
 	"go-progira/pkg/e"
 	"log/slog"
@@ -11,58 +19,188 @@ import (
 	"time"
 )
 
+const (
+	maxBatchSize   = 1000
+	idempotencyTTL = 24 * time.Hour
+)
+
+var tracer = tracing.Tracer("go-progira/bot")
+
+var _ botapi.ServerInterface = (*Server)(nil)
+
 type Server struct {
-	tgClient *clients.TelegramClient
+	tgClient  clients.HTTPTelegramClient
+	delivered *DeliveredUpdates
+
+	Probes     *health.Probes
+	httpServer *http.Server
+	drainDelay time.Duration
 }
 
-func NewServer(tgClient *clients.TelegramClient) *Server {
+func NewServer(tgClient clients.HTTPTelegramClient) *Server {
 	return &Server{
-		tgClient: tgClient,
+		tgClient:  tgClient,
+		delivered: NewDeliveredUpdates(idempotencyTTL),
+		Probes:    health.NewProbes(),
 	}
 }
 
-func (s *Server) handleUpdates(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+func (s *Server) SendUpdate(w http.ResponseWriter, r *http.Request) {
+	var linkUpdate bottypes.LinkUpdate
+
+	if err := json.NewDecoder(r.Body).Decode(&linkUpdate); err != nil {
 		slog.Error(
-			e.ErrMethodNotAllowed.Error(),
-			slog.String("method", r.Method),
-			slog.String("allowed method", http.MethodPost),
+			e.ErrDecodeJSONBody.Error(),
+			slog.String("error", err.Error()),
 		)
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		sendErrorResponse(w, http.StatusBadRequest, "Invalid JSON", "400", "LinkUpdate", "Failed to decode JSON", nil)
 
 		return
 	}
 
-	var linkUpdate bottypes.LinkUpdate
+	if err := ValidateLinkUpdate(linkUpdate); err != nil {
+		updatesReceived.WithLabelValues("http", bottypes.UpdateInvalid).Inc()
+		sendErrorResponse(w, http.StatusBadRequest, "Invalid update", "400", "LinkUpdate", err.Error(), nil)
 
-	if err := json.NewDecoder(r.Body).Decode(&linkUpdate); err != nil {
+		return
+	}
+
+	status, err := s.DeliverUpdate(r.Context(), linkUpdate)
+	updatesReceived.WithLabelValues("http", status).Inc()
+
+	if err != nil {
+		sendErrorResponse(w, http.StatusBadGateway, "Update was not delivered", "502", "LinkUpdate", err.Error(), nil)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	response := botapi.UpdateReceivedResponse{Status: "Update received"}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// SendUpdates delivers an array of updates and reports the result of each of them.
+func (s *Server) SendUpdates(w http.ResponseWriter, r *http.Request) {
+	var updates []bottypes.LinkUpdate
+
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
 		slog.Error(
 			e.ErrDecodeJSONBody.Error(),
 			slog.String("error", err.Error()),
 		)
 
-		sendErrorResponse(w, "Invalid JSON", "400", "LinkUpdate", "Failed to decode JSON", nil)
+		sendErrorResponse(w, http.StatusBadRequest, "Invalid JSON", "400", "LinkUpdate", "Failed to decode JSON", nil)
 
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if len(updates) > maxBatchSize {
+		sendErrorResponse(w, http.StatusBadRequest, "Too many updates", "400", "LinkUpdate",
+			fmt.Sprintf("batch holds %d updates, at most %d are allowed", len(updates), maxBatchSize), nil)
+
+		return
+	}
+
+	response := bottypes.BatchUpdateResponse{Results: make([]bottypes.LinkUpdateResult, 0, len(updates))}
+
+	for _, update := range updates {
+		result := bottypes.LinkUpdateResult{IdempotencyKey: update.IdempotencyKey}
+
+		if err := ValidateLinkUpdate(update); err != nil {
+			result.Status = bottypes.UpdateInvalid
+			result.Error = err.Error()
+		} else if status, errDeliver := s.DeliverUpdate(r.Context(), update); errDeliver != nil {
+			result.Status = bottypes.UpdateFailed
+			result.Error = errDeliver.Error()
+		} else {
+			result.Status = status
+		}
+
+		updatesReceived.WithLabelValues("http_batch", result.Status).Inc()
+
+		response.Results = append(response.Results, result)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
-	response := map[string]string{"status": "Update received"}
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		slog.Error(
 			e.ErrEncodeToJSON.Error(),
 			slog.String("error", err.Error()),
 		)
+	}
+}
 
-		return
+// DeliverUpdate sends the update to every chat it is addressed to. Chats that already got the update
+// with the same idempotency key, or are getting it right now, are skipped;
+// if all of them are, the update is reported as a duplicate.
+func (s *Server) DeliverUpdate(ctx context.Context, linkUpdate bottypes.LinkUpdate) (string, error) {
+	var errs []error
+
+	skipped := 0
+
+	for _, chatID := range linkUpdate.TgChatIDs {
+		key := linkUpdate.IdempotencyKey
+		if key != "" && !s.delivered.TryReserve(key, chatID, time.Now()) {
+			skipped++
+
+			continue
+		}
+
+		if err := s.tgClient.SendMessage(ctx, int(chatID), linkUpdate.Description+linkUpdate.URL); err != nil {
+			slog.Error("Error sending update to chat",
+				slog.Int64("chat_id", chatID),
+				slog.String("error", err.Error()))
+
+			if key != "" {
+				s.delivered.Release(key, chatID)
+			}
+
+			errs = append(errs, fmt.Errorf("chat %d: %w", chatID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return bottypes.UpdateFailed, errors.Join(errs...)
+	}
+
+	if skipped > 0 && skipped == len(linkUpdate.TgChatIDs) {
+		return bottypes.UpdateDuplicate, nil
 	}
 
-	_ = s.tgClient.SendMessage(int(linkUpdate.TgChatIDs[0]), linkUpdate.Description+linkUpdate.URL)
+	return bottypes.UpdateDelivered, nil
 }
 
-func sendErrorResponse(w http.ResponseWriter, desc, code, exceptionName, exceptionMsg string, stacktrace []string) {
+// ValidateLinkUpdate checks that the update can be delivered.
+func ValidateLinkUpdate(update bottypes.LinkUpdate) error {
+	if update.URL == "" {
+		return fmt.Errorf("%w: no url", e.ErrMalformedMsg)
+	}
+
+	if len(update.TgChatIDs) == 0 {
+		return fmt.Errorf("%w: no chats", e.ErrMalformedMsg)
+	}
+
+	return nil
+}
+
+// rejectUnauthorized answers the requests that were not signed by the scrapper.
+func rejectUnauthorized(w http.ResponseWriter, err error) {
+	slog.Warn("Rejected unauthorized request",
+		slog.String("error", err.Error()))
+
+	sendErrorResponse(w, http.StatusUnauthorized, "Request is not authorized", "401", "AuthError", err.Error(), nil)
+}
+
+func sendErrorResponse(w http.ResponseWriter, status int, desc, code, exceptionName, exceptionMsg string, stacktrace []string) {
 	apiError := bottypes.APIErrorResponse{
 		Description:      desc,
 		Code:             code,
@@ -71,8 +209,8 @@ func sendErrorResponse(w http.ResponseWriter, desc, code, exceptionName, excepti
 		Stacktrace:       stacktrace,
 	}
 
-	w.WriteHeader(http.StatusBadRequest)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 
 	if err := json.NewEncoder(w).Encode(apiError); err != nil {
 		slog.Error(
@@ -84,26 +222,55 @@ func sendErrorResponse(w http.ResponseWriter, desc, code, exceptionName, excepti
 	}
 }
 
+// Handler routes the requests of the scrapper.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	botapi.HandlerFromMux(s, mux)
+	mux.Handle("/metrics", metrics.Handler())
+	s.Probes.Register(mux)
+
+	return mux
+}
+
 func (s *Server) Start(config *config.Config) {
-	http.HandleFunc("/updates", s.handleUpdates)
+	handler := auth.Middleware(config.ServiceSecret, config.SignatureMaxAge, rejectUnauthorized, s.Handler())
 
-	srv := &http.Server{
+	s.httpServer = &http.Server{
 		Addr:         config.BotHost,
-		Handler:      nil,
+		Handler:      tracing.Middleware(metrics.Middleware(handler)),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	s.drainDelay = config.ShutdownDrainDelay
 
 	slog.Info("Starting bot server on",
 		slog.String("address", config.BotHost))
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			slog.Error(
 				e.ErrServerFailed.Error(),
 				slog.String("error", err.Error()),
 			)
 		}
 	}()
+
+	s.Probes.SetReady(true)
+}
+
+// Shutdown fails readiness, keeps serving for the drain delay and then stops the HTTP server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Probes.SetReady(false)
+
+	if s.httpServer == nil {
+		return nil
+	}
+
+	select {
+	case <-time.After(s.drainDelay):
+	case <-ctx.Done():
+	}
+
+	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,108 @@
+package processing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/application/bot/processing"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_BatchUpdates(t *testing.T) {
+	mockTg := new(clients.MockTgClient)
+	mockTg.On("SendMessage", 1, mock.Anything).Return(nil).Once()
+	mockTg.On("SendMessage", 2, mock.Anything).Return(nil).Once()
+	mockTg.On("SendMessage", 3, mock.Anything).Return(e.ErrAPI)
+
+	server := processing.NewServer(mockTg)
+
+	updates := []bottypes.LinkUpdate{
+		{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1, 2}, IdempotencyKey: "outbox-1"},
+		{ID: 2, TgChatIDs: []int64{1}, IdempotencyKey: "outbox-2"},
+		{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1, 2}, IdempotencyKey: "outbox-1"},
+		{ID: 3, URL: "https://github.com/c/d", TgChatIDs: []int64{3}, IdempotencyKey: "outbox-3"},
+	}
+
+	body, err := json.Marshal(updates)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/updates/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response bottypes.BatchUpdateResponse
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Results, len(updates))
+
+	expected := []string{bottypes.UpdateDelivered, bottypes.UpdateInvalid, bottypes.UpdateDuplicate, bottypes.UpdateFailed}
+
+	for i, result := range response.Results {
+		assert.Equal(t, updates[i].IdempotencyKey, result.IdempotencyKey)
+		assert.Equal(t, expected[i], result.Status, "update %d", i)
+	}
+
+	mockTg.AssertExpectations(t)
+}
+
+func TestServer_DeliverUpdateConcurrently(t *testing.T) {
+	sending, release := make(chan struct{}), make(chan struct{})
+
+	mockTg := new(clients.MockTgClient)
+	mockTg.On("SendMessage", 1, mock.Anything).Run(func(mock.Arguments) {
+		close(sending)
+		<-release
+	}).Return(nil).Once()
+
+	server := processing.NewServer(mockTg)
+	update := bottypes.LinkUpdate{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-1"}
+
+	first := make(chan string, 1)
+
+	go func() {
+		status, _ := server.DeliverUpdate(context.Background(), update)
+		first <- status
+	}()
+
+	<-sending
+
+	status, err := server.DeliverUpdate(context.Background(), update)
+	require.NoError(t, err)
+	assert.Equal(t, bottypes.UpdateDuplicate, status, "update that is being sent must not be sent again")
+
+	close(release)
+	assert.Equal(t, bottypes.UpdateDelivered, <-first)
+
+	mockTg.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestServer_DeliverUpdateAfterFailure(t *testing.T) {
+	mockTg := new(clients.MockTgClient)
+	mockTg.On("SendMessage", 1, mock.Anything).Return(e.ErrTelegramAPI).Once()
+	mockTg.On("SendMessage", 1, mock.Anything).Return(nil).Once()
+
+	server := processing.NewServer(mockTg)
+	update := bottypes.LinkUpdate{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-1"}
+
+	status, err := server.DeliverUpdate(context.Background(), update)
+	require.ErrorIs(t, err, e.ErrTelegramAPI)
+	assert.Equal(t, bottypes.UpdateFailed, status)
+
+	status, err = server.DeliverUpdate(context.Background(), update)
+	require.NoError(t, err)
+	assert.Equal(t, bottypes.UpdateDelivered, status, "failed update must be sent again")
+
+	mockTg.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package processing
+
+import (
+	"context"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/e"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const streamRetryDelay = time.Second
+
+// UpdatesOpener opens the stream the scrapper pushes link updates over.
+type UpdatesOpener interface {
+	StreamUpdates(ctx context.Context) (protov1.ScrapperService_StreamUpdatesClient, error)
+}
+
+// UpdatesStream receives link updates the scrapper pushes over gRPC and acknowledges every one of them
+// with the result of its delivery. The scrapper keeps the updates that were not acknowledged in its outbox.
+type UpdatesStream struct {
+	opener UpdatesOpener
+	handle func(context.Context, bottypes.LinkUpdate) (string, error)
+}
+
+func NewUpdatesStream(opener UpdatesOpener, handle func(context.Context, bottypes.LinkUpdate) (string, error)) *UpdatesStream {
+	return &UpdatesStream{
+		opener: opener,
+		handle: handle,
+	}
+}
+
+// Run receives updates until ctx is canceled. A broken stream is opened again after a delay.
+func (s *UpdatesStream) Run(ctx context.Context) {
+	for {
+		err := s.serve(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Error(e.ErrConsume.Error(),
+			slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+func (s *UpdatesStream) serve(ctx context.Context) error {
+	stream, err := s.opener.StreamUpdates(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Receiving updates over gRPC")
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(s.process(ctx, msg)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *UpdatesStream) process(ctx context.Context, msg *protov1.StreamUpdatesResponse) *protov1.StreamUpdatesRequest {
+	// The span continues the trace of the scrapper that sent the update.
+	msgCtx, span := tracer.Start(tracing.ExtractMap(ctx, msg.GetTraceContext()), "grpc.stream.receive",
+		trace.WithAttributes(attribute.Int64("delivery.id", int64(msg.GetDeliveryId()))))
+	defer span.End()
+
+	ack := &protov1.StreamUpdatesRequest{DeliveryId: msg.GetDeliveryId()}
+	update := fromProtoUpdate(msg.GetUpdate())
+
+	if err := ValidateLinkUpdate(update); err != nil {
+		updatesReceived.WithLabelValues("grpc", bottypes.UpdateInvalid).Inc()
+
+		ack.Status, ack.Error = protov1.UpdateStatus_UPDATE_STATUS_INVALID, err.Error()
+
+		return ack
+	}
+
+	status, err := s.handle(msgCtx, update)
+	updatesReceived.WithLabelValues("grpc", status).Inc()
+
+	if err != nil {
+		slog.Error("Update was not delivered to some chats",
+			slog.Int64("link id", update.ID),
+			slog.String("error", err.Error()))
+
+		ack.Error = err.Error()
+	}
+
+	ack.Status = updateStatuses[status]
+
+	return ack
+}
+
+var updateStatuses = map[string]protov1.UpdateStatus{
+	bottypes.UpdateDelivered: protov1.UpdateStatus_UPDATE_STATUS_DELIVERED,
+	bottypes.UpdateDuplicate: protov1.UpdateStatus_UPDATE_STATUS_DUPLICATE,
+	bottypes.UpdateInvalid:   protov1.UpdateStatus_UPDATE_STATUS_INVALID,
+	bottypes.UpdateFailed:    protov1.UpdateStatus_UPDATE_STATUS_FAILED,
+}
+
+func fromProtoUpdate(update *protov1.LinkUpdate) bottypes.LinkUpdate {
+	return bottypes.LinkUpdate{
+		ID:             update.GetId(),
+		URL:            update.GetUrl(),
+		Description:    update.GetDescription(),
+		TgChatIDs:      update.GetTgChatIds(),
+		IdempotencyKey: update.GetIdempotencyKey(),
+	}
+}
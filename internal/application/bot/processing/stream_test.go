@@ -0,0 +1,106 @@
+package processing_test
+
+import (
+	"context"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/application/bot/clients"
+	"go-progira/internal/application/bot/processing"
+	"go-progira/internal/domain/types/bottypes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// pushingScrapper pushes the updates to every bot that opens the stream and collects the acknowledgements.
+type pushingScrapper struct {
+	protov1.UnimplementedScrapperServiceServer
+
+	updates []*protov1.LinkUpdate
+	acks    chan *protov1.StreamUpdatesRequest
+}
+
+func (s *pushingScrapper) StreamUpdates(stream protov1.ScrapperService_StreamUpdatesServer) error {
+	for i, update := range s.updates {
+		if err := stream.Send(&protov1.StreamUpdatesResponse{DeliveryId: uint64(i + 1), Update: update}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		ack, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		s.acks <- ack
+	}
+}
+
+func TestUpdatesStream(t *testing.T) {
+	scrapper := &pushingScrapper{
+		updates: []*protov1.LinkUpdate{
+			{Id: 1, Url: "https://github.com/a/b", TgChatIds: []int64{1}, IdempotencyKey: "outbox-1"},
+			{Id: 2, Url: "https://github.com/a/b", IdempotencyKey: "outbox-2"},
+			{Id: 3, Url: "https://github.com/c/d", TgChatIds: []int64{3}, IdempotencyKey: "outbox-3"},
+		},
+		acks: make(chan *protov1.StreamUpdatesRequest, 3),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	protov1.RegisterScrapperServiceServer(server, scrapper)
+
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	client, err := clients.NewGRPCScrapperClient(listener.Addr().String(), "test-secret")
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	statuses := map[int64]string{1: bottypes.UpdateDelivered, 3: bottypes.UpdateDuplicate}
+
+	var (
+		mu      sync.Mutex
+		handled []int64
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go processing.NewUpdatesStream(client, func(_ context.Context, update bottypes.LinkUpdate) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled = append(handled, update.ID)
+
+		return statuses[update.ID], nil
+	}).Run(ctx)
+
+	want := map[uint64]protov1.UpdateStatus{
+		1: protov1.UpdateStatus_UPDATE_STATUS_DELIVERED,
+		2: protov1.UpdateStatus_UPDATE_STATUS_INVALID,
+		3: protov1.UpdateStatus_UPDATE_STATUS_DUPLICATE,
+	}
+
+	for range want {
+		select {
+		case ack := <-scrapper.acks:
+			assert.Equal(t, want[ack.GetDeliveryId()], ack.GetStatus(), "delivery %d", ack.GetDeliveryId())
+		case <-time.After(time.Second):
+			t.Fatal("update was not acknowledged")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []int64{1, 3}, handled, "invalid update must not be delivered")
+}
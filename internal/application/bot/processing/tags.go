@@ -0,0 +1,95 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-progira/internal/domain/botmessages"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"log/slog"
+	"strings"
+)
+
+// processTagsCommand lists the tags of the chat with the number of their links.
+func (m Manager) processTagsCommand(ctx context.Context, id int, given []string) {
+	if len(given) != 0 {
+		m.processUnknownCommand(ctx, id)
+
+		return
+	}
+
+	response, err := m.ScrapClient.GetTags(ctx, int64(id))
+
+	switch {
+	case errors.Is(err, e.ErrChatNotFound):
+		m.sendMessage(ctx, id, botmessages.MsgChatNotRegistered)
+	case err != nil:
+		slog.Error("Error getting tags",
+			slog.String("error", err.Error()))
+
+		m.sendMessage(ctx, id, botmessages.MsgErrGetTags)
+	case len(response.Tags) == 0:
+		m.sendMessage(ctx, id, botmessages.MsgNoTagsSaved)
+	default:
+		var text strings.Builder
+
+		text.WriteString(botmessages.MsgTagsHeader)
+
+		for _, tag := range response.Tags {
+			text.WriteString(fmt.Sprintf(botmessages.MsgTagCount, tag.Name, tag.Links))
+		}
+
+		m.sendMessage(ctx, id, text.String())
+	}
+}
+
+// processRenameTagCommand renames a tag as /renametag old new asks, or moves its links to another tag for /mergetags from into.
+func (m Manager) processRenameTagCommand(ctx context.Context, id int, given []string, merge bool) {
+	usage := botmessages.MsgRenameTagUsage
+	if merge {
+		usage = botmessages.MsgMergeTagsUsage
+	}
+
+	if len(given) != 2 || given[0] == given[1] {
+		m.sendMessage(ctx, id, usage)
+
+		return
+	}
+
+	var (
+		tag *scrappertypes.TagResponse
+		err error
+	)
+
+	if merge {
+		tag, err = m.ScrapClient.MergeTags(ctx, int64(id), scrappertypes.MergeTagsRequest{Tag: given[0], Into: given[1]})
+	} else {
+		tag, err = m.ScrapClient.RenameTag(ctx, int64(id), scrappertypes.RenameTagRequest{Tag: given[0], NewName: given[1]})
+	}
+
+	m.sendMessage(ctx, id, renameTagMessage(tag, err, merge))
+}
+
+// renameTagMessage tells the chat what happened to its tag.
+func renameTagMessage(tag *scrappertypes.TagResponse, err error, merge bool) string {
+	switch {
+	case err == nil && merge:
+		return fmt.Sprintf(botmessages.MsgTagsMerged, tag.Name, tag.Links)
+	case err == nil:
+		return fmt.Sprintf(botmessages.MsgTagRenamed, tag.Name, tag.Links)
+	case errors.Is(err, e.ErrTagNotFound) && merge:
+		return botmessages.MsgMergeTagNotFound
+	case errors.Is(err, e.ErrTagNotFound):
+		return botmessages.MsgNoSavedPagesByTag
+	case errors.Is(err, e.ErrTagAlreadyExists):
+		return botmessages.MsgTagAlreadyExists
+	case errors.Is(err, e.ErrChatNotFound):
+		return botmessages.MsgChatNotRegistered
+	default:
+		slog.Error("Error renaming tag",
+			slog.String("error", err.Error()))
+
+		return botmessages.MsgErrRenameTag
+	}
+}
@@ -0,0 +1,224 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-progira/internal/domain/botmessages"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/domain/types/telegramtypes"
+	"go-progira/pkg/e"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+const (
+	maxImportFileSize = 1 << 20
+	// maxReportRows keeps the report of an import under the 4096 characters of a message.
+	maxReportRows    = 20
+	maxReportLinkLen = 100
+
+	importCallbackPrefix = "import:"
+	importConfirmData    = importCallbackPrefix + "confirm"
+	importCancelData     = importCallbackPrefix + "cancel"
+)
+
+// pendingImport is the file previewed in a chat, it is imported when the import is confirmed.
+type pendingImport struct {
+	fileName string
+	format   string
+	data     string
+}
+
+var fileFormats = map[string]bool{
+	scrappertypes.FormatJSON: true,
+	scrappertypes.FormatCSV:  true,
+	scrappertypes.FormatOPML: true,
+}
+
+var importMarks = map[string]string{
+	scrappertypes.ImportAdded:   "+",
+	scrappertypes.ImportSkipped: "=",
+	scrappertypes.ImportInvalid: "✗",
+}
+
+// processExportCommand sends the links of the chat as a file in the format /export [format] asks, JSON by default.
+func (m Manager) processExportCommand(ctx context.Context, id int, given []string) {
+	format := scrappertypes.FormatJSON
+	if len(given) > 0 {
+		format = strings.ToLower(given[0])
+	}
+
+	if len(given) > 1 || !fileFormats[format] {
+		m.sendMessage(ctx, id, botmessages.MsgExportUsage)
+
+		return
+	}
+
+	data, err := m.ScrapClient.ExportLinks(ctx, int64(id), format)
+	if err != nil {
+		msg := botmessages.MsgErrExport
+		if errors.Is(err, e.ErrChatNotFound) {
+			msg = botmessages.MsgChatNotRegistered
+		}
+
+		slog.Error("Error exporting links",
+			slog.String("error", err.Error()))
+
+		m.sendMessage(ctx, id, msg)
+
+		return
+	}
+
+	err = m.TgClient.SendDocument(ctx, id, "links."+format, data, botmessages.MsgExportCaption)
+	if err != nil {
+		slog.Error("Error sending document",
+			slog.String("error", err.Error()))
+	}
+}
+
+// handleDocument previews the import of a file sent to the bot and asks to confirm it.
+func (m Manager) handleDocument(ctx context.Context, id int, document *telegramtypes.Document) {
+	delete(m.imports, id)
+
+	format := strings.TrimPrefix(strings.ToLower(path.Ext(document.FileName)), ".")
+
+	switch {
+	case !fileFormats[format]:
+		m.sendMessage(ctx, id, botmessages.MsgImportUnknownFormat)
+
+		return
+	case document.FileSize > maxImportFileSize:
+		m.sendMessage(ctx, id, botmessages.MsgImportTooLarge)
+
+		return
+	}
+
+	data, err := m.TgClient.DownloadFile(ctx, document.FileID)
+	if err != nil {
+		slog.Error("Error downloading file",
+			slog.String("error", err.Error()))
+
+		m.sendMessage(ctx, id, botmessages.MsgErrImport)
+
+		return
+	}
+
+	pending := &pendingImport{fileName: document.FileName, format: format, data: string(data)}
+
+	report, err := m.ScrapClient.ImportLinks(ctx, int64(id), scrappertypes.ImportRequest{
+		Format: pending.format,
+		Data:   pending.data,
+		DryRun: true,
+	})
+	if err != nil {
+		m.sendMessage(ctx, id, importError(err))
+
+		return
+	}
+
+	text := fmt.Sprintf(botmessages.MsgImportPreview, pending.fileName) + importReport(report)
+
+	if report.Added == 0 {
+		m.sendMessage(ctx, id, text)
+
+		return
+	}
+
+	m.imports[id] = pending
+
+	err = m.TgClient.SendKeyboard(ctx, id, text, telegramtypes.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{{
+			{Text: botmessages.BtnImportConfirm, CallbackData: importConfirmData},
+			{Text: botmessages.BtnImportCancel, CallbackData: importCancelData},
+		}},
+	})
+	if err != nil {
+		slog.Error("Error sending message",
+			slog.String("error", err.Error()))
+	}
+}
+
+// handleImportCallback imports the previewed file, or forgets it, as the pressed button asks.
+func (m Manager) handleImportCallback(ctx context.Context, query *telegramtypes.CallbackQuery) {
+	id := query.Message.Chat.ID
+
+	pending, ok := m.imports[id]
+	if !ok {
+		m.answerCallback(ctx, query.ID, botmessages.MsgImportExpired)
+
+		return
+	}
+
+	delete(m.imports, id)
+
+	// The import resolves every new link, so the button is answered before it starts.
+	m.answerCallback(ctx, query.ID, "")
+
+	text := botmessages.MsgImportCanceled
+
+	if query.Data == importConfirmData {
+		report, err := m.ScrapClient.ImportLinks(ctx, int64(id), scrappertypes.ImportRequest{
+			Format: pending.format,
+			Data:   pending.data,
+		})
+		if err != nil {
+			text = importError(err)
+		} else {
+			text = fmt.Sprintf(botmessages.MsgImportDone, pending.fileName) + importReport(report)
+		}
+	}
+
+	err := m.TgClient.EditMessage(ctx, id, query.Message.MessageID, text,
+		telegramtypes.InlineKeyboardMarkup{InlineKeyboard: [][]telegramtypes.InlineKeyboardButton{}})
+	if err != nil {
+		slog.Error("Error editing message",
+			slog.String("error", err.Error()))
+	}
+}
+
+// importError tells the chat why its file can't be imported.
+func importError(err error) string {
+	switch {
+	case errors.Is(err, e.ErrInvalidRequest):
+		return botmessages.MsgImportBadFile
+	case errors.Is(err, e.ErrChatNotFound):
+		return botmessages.MsgChatNotRegistered
+	default:
+		slog.Error("Error importing links",
+			slog.String("error", err.Error()))
+
+		return botmessages.MsgErrImport
+	}
+}
+
+// importReport counts the rows of the import and lists the first of them with what happened to each one.
+func importReport(report *scrappertypes.ImportResponse) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf(botmessages.MsgImportSummary, report.Added, report.Skipped, report.Invalid))
+
+	for i, row := range report.Rows {
+		if i == maxReportRows {
+			text.WriteString(fmt.Sprintf(botmessages.MsgImportMoreRows, len(report.Rows)-maxReportRows))
+
+			break
+		}
+
+		link := []rune(row.Link)
+		if len(link) > maxReportLinkLen {
+			link = append(link[:maxReportLinkLen], '…')
+		}
+
+		text.WriteString(fmt.Sprintf("%d. %s %s", row.Row, importMarks[row.Status], string(link)))
+
+		if row.Reason != "" {
+			text.WriteString(" - " + row.Reason)
+		}
+
+		text.WriteString("\n")
+	}
+
+	return text.String()
+}
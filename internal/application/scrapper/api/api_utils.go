@@ -1,16 +1,37 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-progira/internal/domain/types/apitypes"
+	"go-progira/internal/tracing"
 	"go-progira/pkg/e"
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
+	"time"
 )
 
-func doRequest(request *http.Request) (body []byte, err error) {
-	client := &http.Client{}
+const (
+	maxErrorBodySize = 4096
+	// requestTimeout keeps a hanging provider from holding a request of the scrapper past its write timeout.
+	requestTimeout = 5 * time.Second
+)
+
+var defaultClient = &http.Client{Timeout: requestTimeout}
+
+// doRequest sends request with client, or with the shared client with a timeout when client is nil.
+func doRequest(ctx context.Context, client *http.Client, request *http.Request) (body []byte, err error) {
+	if client == nil {
+		client = defaultClient
+	}
+
+	request, span := tracing.StartRequest(request.WithContext(ctx), "provider "+request.URL.Hostname())
 
 	response, errDoReq := client.Do(request)
+	tracing.FinishRequest(span, response, errDoReq)
 
 	if errDoReq != nil {
 		slog.Error(
@@ -22,13 +43,24 @@ func doRequest(request *http.Request) (body []byte, err error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
+		errStatus := statusError(response)
+
 		slog.Error(
 			e.ErrAPI.Error(),
-			slog.String("function", "Github updates"),
+			slog.String("url", request.URL.Host+request.URL.Path),
 			slog.Int("status code", response.StatusCode),
+			slog.String("error", errStatus.Error()),
 		)
 
-		return nil, e.ErrAPI
+		closeErr := response.Body.Close()
+		if closeErr != nil {
+			slog.Error(
+				e.ErrCloseBody.Error(),
+				slog.String("error", closeErr.Error()),
+			)
+		}
+
+		return nil, errStatus
 	}
 
 	body, errRead := io.ReadAll(response.Body)
@@ -53,3 +85,30 @@ func doRequest(request *http.Request) (body []byte, err error) {
 
 	return body, nil
 }
+
+// statusError classifies an unsuccessful provider response. The returned error
+// always wraps e.ErrAPI, and wraps a more specific sentinel when the status allows.
+func statusError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests,
+		response.Header.Get("X-RateLimit-Remaining") == "0",
+		bytes.Contains(body, []byte("throttle_violation")):
+		return fmt.Errorf("%w: %w", e.ErrAPI, e.ErrRateLimited)
+	case response.StatusCode == http.StatusNotFound,
+		response.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: %w", e.ErrAPI, e.ErrResourceNotFound)
+	case response.StatusCode == http.StatusUnauthorized,
+		response.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %w", e.ErrAPI, e.ErrResourcePrivate)
+	default:
+		return fmt.Errorf("%w: status %d", e.ErrAPI, response.StatusCode)
+	}
+}
+
+func sortEvents(events []apitypes.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+}
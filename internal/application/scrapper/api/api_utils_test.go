@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"context"
+	"go-progira/internal/application/scrapper/api"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// redirectTransport sends the requests for the providers to the test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme, request.URL.Host = t.target.Scheme, t.target.Host
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestResolve_StatusErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		remaining string
+		body      string
+		wantErr   error
+	}{
+		{name: "too many requests", status: http.StatusTooManyRequests, wantErr: e.ErrRateLimited},
+		{name: "no requests remain", status: http.StatusForbidden, remaining: "0", wantErr: e.ErrRateLimited},
+		{name: "throttle violation", status: http.StatusBadRequest, body: `{"error_name":"throttle_violation"}`, wantErr: e.ErrRateLimited},
+		{name: "not found", status: http.StatusNotFound, wantErr: e.ErrResourceNotFound},
+		{name: "gone", status: http.StatusGone, wantErr: e.ErrResourceNotFound},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: e.ErrResourcePrivate},
+		{name: "forbidden", status: http.StatusForbidden, remaining: "10", wantErr: e.ErrResourcePrivate},
+		{name: "server error", status: http.StatusBadGateway, wantErr: e.ErrAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.remaining != "" {
+					w.Header().Set("X-RateLimit-Remaining", tt.remaining)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			target, _ := url.Parse(server.URL)
+			client := &http.Client{Transport: redirectTransport{target: target}}
+
+			updaters := map[string]api.Updater{
+				"https://github.com/a/b/pulls":                  &api.GithubUpdater{Client: client},
+				"https://stackoverflow.com/questions/1/answers": &api.StackoverflowUpdater{Client: client},
+			}
+
+			for link, updater := range updaters {
+				_, err := updater.Resolve(context.Background(), link)
+
+				assert.ErrorIs(t, err, tt.wantErr, link)
+				assert.ErrorIs(t, err, e.ErrAPI, link)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,59 +11,45 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
-	"regexp"
-	"strings"
 	"time"
 )
 
 type GithubUpdater struct {
 	Key string
+	// Client sends the requests to GitHub, the shared client with a timeout is used when it is nil.
+	Client *http.Client
 }
 
 func IsGitHubURL(url string) bool {
-	patternPulls := `^https://github\.com/[\w\-]+/[\w\-]+/pulls$`
-	patternIssues := `^https://github\.com/[\w\-]+/[\w\-]+/issues$`
+	_, err := ParseGithubLink(url)
 
-	patterns := []string{patternPulls, patternIssues}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if re.MatchString(url) {
-			return true
-		}
-	}
-
-	return false
+	return err == nil
 }
 
 func GetOwnerAndRepo(link string) (owner, repo string, err error) {
-	parts := strings.Split(link, "/")
-
-	if len(parts) < 5 {
-		if parts[3] != "" {
-			err = e.ErrNoRepoInPath
-		} else {
-			err = e.ErrNoOwnerAndRepoInPath
-		}
-
+	segments, err := linkSegments(link, githubHost)
+	if err != nil {
 		return "", "", err
 	}
 
-	owner = parts[3]
-	repo = parts[4]
+	switch len(segments) {
+	case 0:
+		return "", "", e.ErrNoOwnerAndRepoInPath
+	case 1:
+		return "", "", e.ErrNoRepoInPath
+	}
 
-	return owner, repo, nil
-}
+	owner = segments[0]
+	repo = segments[1]
 
-func (updater *GithubUpdater) GetResponse(owner, repo string, updateType apitypes.GithubType,
-	prevUpdateTime time.Time) ([]apitypes.GithubUpdate, error) {
-	since := prevUpdateTime.UTC().Format(time.RFC3339)
-
-	urlString := fmt.Sprintf("https://api.github.com/search/issues?q=repo:%s/%s+type:%s+updated:>%v",
-		owner, repo, updateType.StringForRequest(), since)
+	if !githubOwnerPattern.MatchString(owner) || !githubRepoPattern.MatchString(repo) {
+		return "", "", e.ErrWrongURLFormat
+	}
 
-	fmt.Println(urlString)
+	return owner, repo, nil
+}
 
+func (updater *GithubUpdater) newRequest(urlString string) (*http.Request, error) {
 	req, errMakeReq := http.NewRequest(http.MethodGet, urlString, http.NoBody)
 	if errMakeReq != nil {
 		slog.Error(
@@ -72,20 +59,73 @@ func (updater *GithubUpdater) GetResponse(owner, repo string, updateType apitype
 			slog.String("url", urlString),
 		)
 
-		return []apitypes.GithubUpdate{}, e.ErrMakeRequest
+		return nil, e.ErrMakeRequest
 	}
 
 	req.Header.Set("Authorization", "Bearer "+updater.Key)
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", "LinkChecker")
 
-	body, err := doRequest(req)
-	if errors.Is(err, e.ErrAPI) {
-		return []apitypes.GithubUpdate{}, nil
+	return req, nil
+}
+
+// Resolve checks that the repository exists and is accessible with the configured key.
+func (updater *GithubUpdater) Resolve(ctx context.Context, link string) (apitypes.Resource, error) {
+	ref, err := ParseGithubLink(link)
+	if err != nil {
+		return apitypes.Resource{}, err
 	}
 
-	if len(body) == 0 {
-		return []apitypes.GithubUpdate{}, nil
+	req, err := updater.newRequest(fmt.Sprintf("https://api.github.com/repos/%s/%s", ref.Owner, ref.Repo))
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	body, err := doRequest(ctx, updater.Client, req)
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	var repository struct {
+		FullName  string    `json:"full_name"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	if errDecode := json.Unmarshal(body, &repository); errDecode != nil {
+		slog.Error(
+			e.ErrDecodeJSONBody.Error(),
+			slog.String("error", errDecode.Error()),
+		)
+
+		return apitypes.Resource{}, e.ErrDecodeJSONBody
+	}
+
+	lastActivity, err := updater.getLastActivity(ctx, ref)
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	if lastActivity.IsZero() {
+		lastActivity = repository.CreatedAt
+	}
+
+	return apitypes.Resource{Title: repository.FullName, LastActivity: lastActivity.UTC()}, nil
+}
+
+// getLastActivity returns creation time of the newest pull request or issue of the repository,
+// or zero time if there are none.
+func (updater *GithubUpdater) getLastActivity(ctx context.Context, ref GithubLink) (time.Time, error) {
+	urlString := fmt.Sprintf("https://api.github.com/search/issues?q=repo:%s/%s+type:%s&sort=created&order=desc&per_page=1",
+		ref.Owner, ref.Repo, ref.Type.StringForRequest())
+
+	req, err := updater.newRequest(urlString)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	body, err := doRequest(ctx, updater.Client, req)
+	if err != nil {
+		return time.Time{}, err
 	}
 
 	var result struct {
@@ -98,74 +138,114 @@ func (updater *GithubUpdater) GetResponse(owner, repo string, updateType apitype
 			slog.String("error", errDecode.Error()),
 		)
 
-		return []apitypes.GithubUpdate{}, e.ErrDecodeJSONBody
+		return time.Time{}, e.ErrDecodeJSONBody
 	}
 
-	return result.Items, nil
+	if len(result.Items) == 0 {
+		return time.Time{}, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, result.Items[0].CreatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return createdAt, nil
 }
 
-func (updater *GithubUpdater) GetUpdates(link string, prevUpdateTime time.Time) (string, time.Time) {
-	owner, repo, err := GetOwnerAndRepo(link)
+func (updater *GithubUpdater) GetResponse(ctx context.Context, owner, repo string, updateType apitypes.GithubType,
+	prevUpdateTime time.Time) ([]apitypes.GithubUpdate, error) {
+	since := prevUpdateTime.UTC().Format(time.RFC3339)
+
+	urlString := fmt.Sprintf("https://api.github.com/search/issues?q=repo:%s/%s+type:%s+updated:>%v",
+		owner, repo, updateType.StringForRequest(), since)
+
+	req, errMakeReq := updater.newRequest(urlString)
+	if errMakeReq != nil {
+		return []apitypes.GithubUpdate{}, errMakeReq
+	}
+
+	body, err := doRequest(ctx, updater.Client, req)
 	if err != nil {
-		slog.Error(err.Error(),
-			slog.String("link", link),
-		)
+		return []apitypes.GithubUpdate{}, err
+	}
 
-		return "", prevUpdateTime
+	if len(body) == 0 {
+		return []apitypes.GithubUpdate{}, nil
 	}
 
-	parts := strings.Split(link, "/")
-	if len(parts) < 6 {
-		log.Println("invalid github link: not enough parts")
-		return "", prevUpdateTime
+	var result struct {
+		Items []apitypes.GithubUpdate `json:"items"`
 	}
 
-	var githubType apitypes.GithubType
+	if errDecode := json.Unmarshal(body, &result); errDecode != nil {
+		slog.Error(
+			e.ErrDecodeJSONBody.Error(),
+			slog.String("error", errDecode.Error()),
+		)
 
-	switch parts[5] {
-	case "pulls":
-		githubType = apitypes.PR
-	case "issues":
-		githubType = apitypes.Issue
-	default:
-		log.Printf("Unknown update type(not a pullRequest and not an issue): %s", parts[5])
+		return []apitypes.GithubUpdate{}, e.ErrDecodeJSONBody
+	}
 
-		return "", prevUpdateTime
+	return result.Items, nil
+}
+
+func (updater *GithubUpdater) GetUpdates(ctx context.Context, link string,
+	prevUpdateTime time.Time) ([]apitypes.Event, time.Time, error) {
+	ref, err := ParseGithubLink(link)
+	if err != nil {
+		return nil, prevUpdateTime, err
 	}
 
-	updates, err := updater.GetResponse(owner, repo, githubType, prevUpdateTime)
+	githubType := ref.Type
+
+	updates, err := updater.GetResponse(ctx, ref.Owner, ref.Repo, githubType, prevUpdateTime)
 	if err != nil {
 		log.Printf("Error getting updates from Github: %s", err.Error())
-		return "", prevUpdateTime
+
+		// Search answers 422 for a deleted repository, ask the repository itself to tell why it failed.
+		if errors.Is(err, e.ErrAPI) && !errors.Is(err, e.ErrRateLimited) {
+			if _, errResolve := updater.Resolve(ctx, link); errResolve != nil {
+				return nil, prevUpdateTime, errResolve
+			}
+		}
+
+		return nil, prevUpdateTime, err
 	}
 
 	lastTime := prevUpdateTime
 
-	var filteredUpdates []apitypes.GithubUpdate
+	var events []apitypes.Event
 
 	for _, update := range updates {
 		updateTime, err := time.Parse(time.RFC3339, update.CreatedAt)
 		if err != nil {
 			log.Printf("Error parsing time %v for update %s: %s", update.CreatedAt, link, err.Error())
 
-			return "", lastTime
+			return nil, prevUpdateTime, err
 		}
 
-		updateLocalTime := updateTime.In(time.Local)
+		updateTime = updateTime.UTC()
 
-		if updateLocalTime.After(prevUpdateTime) {
+		if updateTime.After(prevUpdateTime) {
 			update.Type = githubType
-			update.CreatedAt = updateLocalTime.Format(time.RFC3339)
-			filteredUpdates = append(filteredUpdates, update)
+			update.CreatedAt = updateTime.Format(time.RFC3339)
+
+			events = append(events, apitypes.Event{
+				CreatedAt: updateTime,
+				Message:   formatter.FormatMessageForGithub([]apitypes.GithubUpdate{update}),
+			})
 
 			if updateTime.After(lastTime) {
-				lastTime = updateLocalTime
+				lastTime = updateTime
 			}
 		}
 	}
 
+	sortEvents(events)
+
 	slog.Info("Get Github updates ",
-		slog.Int("Number of updates ", len(filteredUpdates)))
+		slog.Int("Number of updates ", len(events)))
 
-	return formatter.FormatMessageForGithub(filteredUpdates), lastTime.In(time.Local)
+	return events, lastTime, nil
 }
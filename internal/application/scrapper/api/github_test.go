@@ -18,10 +18,20 @@ func TestIsGithubURL(t *testing.T) {
 
 	testCases := []TestCase{
 		{
-			name:     "url is correct, length is longer than base URL",
-			given:    "https://github.com/todo",
+			name:     "url to pull requests of repository is correct",
+			given:    "https://github.com/progirira/Link-checker/pulls",
+			expected: true,
+		},
+		{
+			name:     "url to issues with trailing slash, www and http is correct",
+			given:    "http://www.github.com/progirira/Link-checker/issues/",
 			expected: true,
 		},
+		{
+			name:     "url without type of updates is not correct",
+			given:    "https://github.com/todo",
+			expected: false,
+		},
 		{
 			name:     "the length of URL is not long enough",
 			given:    "https://github",
@@ -82,6 +92,20 @@ func TestGetOwnerAndRepo(t *testing.T) {
 			expectedRepo:  "",
 			expectedErr:   e.ErrNoOwnerAndRepoInPath,
 		},
+		{
+			name:          "too short URL does not cause panic",
+			given:         "https://",
+			expectedOwner: "",
+			expectedRepo:  "",
+			expectedErr:   e.ErrWrongURLFormat,
+		},
+		{
+			name:          "URL of another host",
+			given:         "https://gitlab.com/progirira/Golang-projects",
+			expectedOwner: "",
+			expectedRepo:  "",
+			expectedErr:   e.ErrWrongURLFormat,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"go-progira/internal/domain/types/apitypes"
+	"go-progira/pkg/e"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubHost        = "github.com"
+	stackoverflowHost = "stackoverflow.com"
+)
+
+var (
+	githubOwnerPattern = regexp.MustCompile(`^[\w-]+$`)
+	githubRepoPattern  = regexp.MustCompile(`^[\w.-]+$`)
+)
+
+// GithubLink is a parsed reference to the pull requests or issues of a GitHub repository.
+type GithubLink struct {
+	Owner string
+	Repo  string
+	Type  apitypes.GithubType
+}
+
+// String returns the canonical form of the link.
+func (l GithubLink) String() string {
+	var kind string
+
+	switch l.Type {
+	case apitypes.PR:
+		kind = "pulls"
+	case apitypes.Issue:
+		kind = "issues"
+	}
+
+	return fmt.Sprintf("https://%s/%s/%s/%s", githubHost, l.Owner, l.Repo, kind)
+}
+
+// StackoverflowLink is a parsed reference to the answers or comments of a StackOverflow question.
+type StackoverflowLink struct {
+	QuestionID int
+	Type       apitypes.StackOverFlowType
+}
+
+// String returns the canonical form of the link.
+func (l StackoverflowLink) String() string {
+	var kind string
+
+	switch l.Type {
+	case apitypes.Answer:
+		kind = "answers"
+	case apitypes.Comment:
+		kind = "comments"
+	}
+
+	return fmt.Sprintf("https://%s/questions/%d/%s", stackoverflowHost, l.QuestionID, kind)
+}
+
+// linkSegments checks that link points to host and returns the non-empty segments of its path.
+// Scheme may be http, https or omitted, "www." is ignored, query and fragment are dropped.
+func linkSegments(link, host string) ([]string, error) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return nil, e.ErrWrongURLFormat
+	}
+
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, e.ErrWrongURLFormat
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return nil, e.ErrWrongURLFormat
+	}
+
+	if strings.TrimPrefix(strings.ToLower(u.Host), "www.") != host {
+		return nil, e.ErrWrongURLFormat
+	}
+
+	var segments []string
+
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments, nil
+}
+
+func ParseGithubLink(link string) (GithubLink, error) {
+	owner, repo, err := GetOwnerAndRepo(link)
+	if err != nil {
+		return GithubLink{}, err
+	}
+
+	segments, _ := linkSegments(link, githubHost)
+	if len(segments) != 3 {
+		return GithubLink{}, e.ErrWrongURLFormat
+	}
+
+	ref := GithubLink{
+		Owner: strings.ToLower(owner),
+		Repo:  strings.ToLower(repo),
+	}
+
+	switch strings.ToLower(segments[2]) {
+	case "pulls":
+		ref.Type = apitypes.PR
+	case "issues":
+		ref.Type = apitypes.Issue
+	default:
+		return GithubLink{}, e.ErrWrongURLFormat
+	}
+
+	return ref, nil
+}
+
+func ParseStackoverflowLink(link string) (StackoverflowLink, error) {
+	segments, err := linkSegments(link, stackoverflowHost)
+	if err != nil {
+		return StackoverflowLink{}, err
+	}
+
+	if len(segments) != 3 || strings.ToLower(segments[0]) != "questions" {
+		return StackoverflowLink{}, e.ErrWrongURLFormat
+	}
+
+	questionID, err := strconv.Atoi(segments[1])
+	if err != nil || questionID <= 0 || strconv.Itoa(questionID) != segments[1] {
+		return StackoverflowLink{}, e.ErrWrongURLFormat
+	}
+
+	ref := StackoverflowLink{QuestionID: questionID}
+
+	switch strings.ToLower(segments[2]) {
+	case "answers":
+		ref.Type = apitypes.Answer
+	case "comments":
+		ref.Type = apitypes.Comment
+	default:
+		return StackoverflowLink{}, e.ErrWrongURLFormat
+	}
+
+	return ref, nil
+}
+
+// CanonicalLink returns the form under which the link is stored, so that
+// variants of the same URL collapse into one.
+func CanonicalLink(link string) (string, error) {
+	if ref, err := ParseGithubLink(link); err == nil {
+		return ref.String(), nil
+	}
+
+	if ref, err := ParseStackoverflowLink(link); err == nil {
+		return ref.String(), nil
+	}
+
+	return "", e.ErrWrongURLFormat
+}
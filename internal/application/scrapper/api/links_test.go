@@ -0,0 +1,164 @@
+package api_test
+
+import (
+	"errors"
+	"go-progira/internal/application/scrapper/api"
+	"go-progira/pkg/e"
+	"testing"
+)
+
+func TestCanonicalLink(t *testing.T) {
+	t.Parallel()
+
+	type TestCase struct {
+		name        string
+		given       string
+		expected    string
+		expectedErr error
+	}
+
+	testCases := []TestCase{
+		{
+			name:     "canonical github link stays the same",
+			given:    "https://github.com/progirira/link-checker/pulls",
+			expected: "https://github.com/progirira/link-checker/pulls",
+		},
+		{
+			name:     "github link with http, www, trailing slash and upper case",
+			given:    "http://www.GitHub.com/Progirira/Link-Checker/Issues/",
+			expected: "https://github.com/progirira/link-checker/issues",
+		},
+		{
+			name:     "github link with query parameters and fragment",
+			given:    "https://github.com/progirira/link-checker/pulls?q=is%3Aopen#top",
+			expected: "https://github.com/progirira/link-checker/pulls",
+		},
+		{
+			name:     "github link without scheme",
+			given:    "github.com/progirira/link-checker/pulls",
+			expected: "https://github.com/progirira/link-checker/pulls",
+		},
+		{
+			name:     "stackoverflow link with www and trailing slash",
+			given:    "https://www.stackoverflow.com/questions/79515510/answers/",
+			expected: "https://stackoverflow.com/questions/79515510/answers",
+		},
+		{
+			name:     "stackoverflow link with query parameters",
+			given:    "http://stackoverflow.com/questions/79515510/Comments?tab=votes",
+			expected: "https://stackoverflow.com/questions/79515510/comments",
+		},
+		{
+			name:        "unsupported scheme",
+			given:       "ftp://github.com/progirira/link-checker/pulls",
+			expectedErr: e.ErrWrongURLFormat,
+		},
+		{
+			name:        "unknown type of github updates",
+			given:       "https://github.com/progirira/link-checker/commits",
+			expectedErr: e.ErrWrongURLFormat,
+		},
+		{
+			name:        "question id is not a number",
+			given:       "https://stackoverflow.com/questions/abc/answers",
+			expectedErr: e.ErrWrongURLFormat,
+		},
+		{
+			name:        "question id with leading zeros",
+			given:       "https://stackoverflow.com/questions/0123/answers",
+			expectedErr: e.ErrWrongURLFormat,
+		},
+		{
+			name:        "empty link",
+			given:       "",
+			expectedErr: e.ErrWrongURLFormat,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(tt *testing.T) {
+			tt.Parallel()
+
+			got, err := api.CanonicalLink(testCase.given)
+			if !errors.Is(err, testCase.expectedErr) {
+				tt.Errorf("Incorrect error, got: %v; expected: %v; in case with url %s",
+					err, testCase.expectedErr, testCase.given)
+			}
+
+			if got != testCase.expected {
+				tt.Errorf("Incorrect canonical link, got: %s; expected %s; in case with url %s",
+					got, testCase.expected, testCase.given)
+			}
+		})
+	}
+}
+
+func FuzzParseGithubLink(f *testing.F) {
+	f.Add("https://github.com/progirira/link-checker/pulls")
+	f.Add("http://www.github.com/Progirira/Link-Checker/issues/")
+	f.Add("https://github.com/")
+	f.Add("https://")
+	f.Add("github.com//a//b//pulls")
+
+	f.Fuzz(func(t *testing.T, link string) {
+		ref, err := api.ParseGithubLink(link)
+		if err != nil {
+			return
+		}
+
+		again, err := api.ParseGithubLink(ref.String())
+		if err != nil {
+			t.Fatalf("canonical form %q of %q is not parsed: %v", ref.String(), link, err)
+		}
+
+		if again != ref {
+			t.Fatalf("canonical form %q of %q is parsed as %+v, expected %+v", ref.String(), link, again, ref)
+		}
+	})
+}
+
+func FuzzParseStackoverflowLink(f *testing.F) {
+	f.Add("https://stackoverflow.com/questions/79515510/answers")
+	f.Add("https://www.stackoverflow.com/questions/79515510/comments?tab=votes")
+	f.Add("https://stackoverflow.com/questions")
+	f.Add("stackoverflow.com/questions/-1/answers")
+
+	f.Fuzz(func(t *testing.T, link string) {
+		ref, err := api.ParseStackoverflowLink(link)
+		if err != nil {
+			return
+		}
+
+		again, err := api.ParseStackoverflowLink(ref.String())
+		if err != nil {
+			t.Fatalf("canonical form %q of %q is not parsed: %v", ref.String(), link, err)
+		}
+
+		if again != ref {
+			t.Fatalf("canonical form %q of %q is parsed as %+v, expected %+v", ref.String(), link, again, ref)
+		}
+	})
+}
+
+func FuzzCanonicalLink(f *testing.F) {
+	f.Add("https://github.com/progirira/link-checker/pulls")
+	f.Add("https://stackoverflow.com/questions/79515510/answers")
+	f.Add("HTTP://WWW.GITHUB.COM/A/B/ISSUES/")
+	f.Add("key")
+
+	f.Fuzz(func(t *testing.T, link string) {
+		canonical, err := api.CanonicalLink(link)
+		if err != nil {
+			return
+		}
+
+		again, err := api.CanonicalLink(canonical)
+		if err != nil {
+			t.Fatalf("canonical form %q of %q is not valid: %v", canonical, link, err)
+		}
+
+		if again != canonical {
+			t.Fatalf("canonical form is not stable: %q -> %q -> %q", link, canonical, again)
+		}
+	})
+}
@@ -1,8 +1,8 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"go-progira/internal/domain/types/apitypes"
 	"go-progira/internal/formatter"
@@ -10,9 +10,6 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -39,30 +36,37 @@ func GetUpdater(url string) (Updater, bool) {
 }
 
 type Updater interface {
-	GetUpdates(link string, prevUpdateTime time.Time) (string, time.Time)
+	GetUpdates(ctx context.Context, link string, prevUpdateTime time.Time) ([]apitypes.Event, time.Time, error)
+	Resolve(ctx context.Context, link string) (apitypes.Resource, error)
 }
 
 type StackoverflowUpdater struct {
 	Key string
+	// Client sends the requests to StackExchange, the shared client with a timeout is used when it is nil.
+	Client *http.Client
 }
 
 func IsStackOverflowURL(url string) bool {
-	patternAnswers := `^https://stackoverflow\.com/questions/(\d+)/answers$`
-	patternCommits := `^https://stackoverflow\.com/questions/(\d+)/comments$`
+	_, err := ParseStackoverflowLink(url)
 
-	patterns := []string{patternAnswers, patternCommits}
+	return err == nil
+}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if re.MatchString(url) {
-			return true
-		}
+func (updater *StackoverflowUpdater) GetTitle(ctx context.Context, questionID int) (string, error) {
+	info, err := updater.getQuestion(ctx, questionID)
+	if err != nil {
+		return "", err
 	}
 
-	return false
+	return info.Title, nil
+}
+
+type question struct {
+	Title     string `json:"title"`
+	CreatedAt int64  `json:"creation_date"`
 }
 
-func (updater *StackoverflowUpdater) GetTitle(questionID int) (string, error) {
+func (updater *StackoverflowUpdater) getQuestion(ctx context.Context, questionID int) (question, error) {
 	urlString := fmt.Sprintf(
 		"https://api.stackexchange.com/2.3/questions/%d?site=stackoverflow&filter=withbody",
 		questionID,
@@ -80,36 +84,62 @@ func (updater *StackoverflowUpdater) GetTitle(questionID int) (string, error) {
 			slog.String("url", urlString),
 		)
 
-		return "", e.ErrMakeRequest
+		return question{}, e.ErrMakeRequest
 	}
 
-	body, err := doRequest(req)
-	if errors.Is(err, e.ErrAPI) {
-		return "", nil
+	body, err := doRequest(ctx, updater.Client, req)
+	if err != nil {
+		return question{}, err
 	}
 
 	var result struct {
-		Items []struct {
-			Title string `json:"title"`
-		} `json:"items"`
+		Items []question `json:"items"`
 	}
 
 	if errDecode := json.Unmarshal(body, &result); errDecode != nil {
 		slog.Error(e.ErrDecodeJSONBody.Error(),
 			slog.String("error", errDecode.Error()))
-		return "", errDecode
+		return question{}, errDecode
 	}
 
 	if len(result.Items) == 0 {
 		slog.Error("question not found, error getting title")
-		return "", fmt.Errorf("question not found")
+		return question{}, e.ErrResourceNotFound
 	}
 
-	return result.Items[0].Title, nil
+	return result.Items[0], nil
 }
 
-func (updater *StackoverflowUpdater) GetResponse(questionID int, updateType apitypes.StackOverFlowType,
-	prevUpdateTime time.Time) ([]apitypes.StackOverFlowUpdate, error) {
+// Resolve checks that the question exists.
+func (updater *StackoverflowUpdater) Resolve(ctx context.Context, link string) (apitypes.Resource, error) {
+	ref, err := ParseStackoverflowLink(link)
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	info, err := updater.getQuestion(ctx, ref.QuestionID)
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	lastActivity := time.Unix(info.CreatedAt, 0).UTC()
+
+	updates, err := updater.GetResponse(ctx, ref.QuestionID, ref.Type, time.Time{})
+	if err != nil {
+		return apitypes.Resource{}, err
+	}
+
+	for _, update := range updates {
+		if createdAt := time.Unix(update.CreatedAt, 0).UTC(); createdAt.After(lastActivity) {
+			lastActivity = createdAt
+		}
+	}
+
+	return apitypes.Resource{Title: info.Title, LastActivity: lastActivity}, nil
+}
+
+func (updater *StackoverflowUpdater) GetResponse(ctx context.Context, questionID int,
+	updateType apitypes.StackOverFlowType, prevUpdateTime time.Time) ([]apitypes.StackOverFlowUpdate, error) {
 	var format string
 
 	if updateType == apitypes.Answer {
@@ -139,9 +169,9 @@ func (updater *StackoverflowUpdater) GetResponse(questionID int, updateType apit
 		return []apitypes.StackOverFlowUpdate{}, e.ErrMakeRequest
 	}
 
-	body, err := doRequest(req)
-	if errors.Is(err, e.ErrAPI) {
-		return []apitypes.StackOverFlowUpdate{}, nil
+	body, err := doRequest(ctx, updater.Client, req)
+	if err != nil {
+		return []apitypes.StackOverFlowUpdate{}, err
 	}
 
 	var result struct {
@@ -152,50 +182,60 @@ func (updater *StackoverflowUpdater) GetResponse(questionID int, updateType apit
 		slog.Error(e.ErrDecodeJSONBody.Error(),
 			slog.String("error", errDecode.Error()))
 
-		return nil, err
+		return nil, e.ErrDecodeJSONBody
 	}
 
 	return result.Items, nil
 }
 
-func (updater *StackoverflowUpdater) GetUpdates(link string, prevUpdateTime time.Time) (string, time.Time) {
-	parts := strings.Split(link, "/")
-
-	ID, errToInt := strconv.Atoi(parts[4])
-	if errToInt != nil {
-		log.Println("Error converting id to int", errToInt)
-		return "", prevUpdateTime
+func (updater *StackoverflowUpdater) GetUpdates(ctx context.Context, link string,
+	prevUpdateTime time.Time) ([]apitypes.Event, time.Time, error) {
+	ref, err := ParseStackoverflowLink(link)
+	if err != nil {
+		return nil, prevUpdateTime, err
 	}
 
-	title, err := updater.GetTitle(ID)
+	ID := ref.QuestionID
+	updateType := ref.Type
+
+	title, err := updater.GetTitle(ctx, ID)
 	if err != nil {
 		log.Println("Error getting title ", err)
-		return "", prevUpdateTime
+		return nil, prevUpdateTime, err
 	}
 
-	var updateType apitypes.StackOverFlowType
-
-	switch parts[5] {
-	case "comments":
-		updateType = apitypes.Comment
-	case "answers":
-		updateType = apitypes.Answer
-	default:
-		log.Printf("Unknown update type(not an answer or comment): %s", parts[5])
-
-		return "", prevUpdateTime
+	updates, err := updater.GetResponse(ctx, ID, updateType, prevUpdateTime)
+	if err != nil {
+		return nil, prevUpdateTime, err
 	}
 
-	updates, _ := updater.GetResponse(ID, updateType, prevUpdateTime)
 	lastTime := prevUpdateTime
 
+	events := make([]apitypes.Event, 0, len(updates))
+
 	for _, update := range updates {
 		update.Title = title
-		lastTime = time.Unix(update.CreatedAt, 0)
+		update.Type = updateType
+
+		createdAt := time.Unix(update.CreatedAt, 0).UTC()
+		if !createdAt.After(prevUpdateTime) {
+			continue
+		}
+
+		events = append(events, apitypes.Event{
+			CreatedAt: createdAt,
+			Message:   formatter.FormatMessageForStackOverflow([]apitypes.StackOverFlowUpdate{update}),
+		})
+
+		if createdAt.After(lastTime) {
+			lastTime = createdAt
+		}
 	}
 
+	sortEvents(events)
+
 	slog.Info("Get Stackoverflow updates ",
-		slog.Int("Number of updates ", len(updates)))
+		slog.Int("Number of updates ", len(events)))
 
-	return formatter.FormatMessageForStackOverflow(updates), lastTime
+	return events, lastTime, nil
 }
@@ -16,10 +16,25 @@ func TestIsStackOverflowURL(t *testing.T) {
 
 	testCases := []TestCase{
 		{
-			name:     "url is correct, length is longer than base URL",
-			given:    "https://stackoverflow.com/questions/79515510/why-transaction-timeout-in-pgx-doesnt-work",
+			name:     "url to answers of question is correct",
+			given:    "https://stackoverflow.com/questions/79515510/answers",
+			expected: true,
+		},
+		{
+			name:     "url to comments with query parameters is correct",
+			given:    "https://www.stackoverflow.com/questions/79515510/comments?tab=votes",
 			expected: true,
 		},
+		{
+			name:     "url with slug instead of type of updates is not correct",
+			given:    "https://stackoverflow.com/questions/79515510/why-transaction-timeout-in-pgx-doesnt-work",
+			expected: false,
+		},
+		{
+			name:     "url without type of updates does not cause panic",
+			given:    "https://stackoverflow.com/questions",
+			expected: false,
+		},
 		{
 			name:     "the length of URL is not long enough",
 			given:    "https://stackoverflow.com",
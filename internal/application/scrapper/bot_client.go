@@ -2,52 +2,168 @@ package scrapper
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"go-progira/internal/auth"
 	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/config"
 	"go-progira/pkg/e"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"path"
+	"time"
 )
 
 type HTTPBotClient interface {
-	SendUpdate(update bottypes.LinkUpdate) (err error)
+	SendUpdate(ctx context.Context, update bottypes.LinkUpdate) (err error)
+}
+
+// BatchBotClient is implemented by clients that can send several updates at once.
+// The returned errors match the updates by index, nil means the update was delivered.
+type BatchBotClient interface {
+	SendUpdates(ctx context.Context, updates []bottypes.LinkUpdate) []error
 }
 
 type BotClient struct {
 	scheme   string
 	host     string
 	basePath string
+	client   http.Client
 }
 
-func NewBotClient(scheme, host, basePath string) *BotClient {
+// NewBotClient returns the client that signs its requests with secret.
+func NewBotClient(scheme, host, basePath string, timeout time.Duration, secret string) *BotClient {
 	return &BotClient{
 		scheme:   scheme,
 		host:     host,
 		basePath: basePath,
+		client:   http.Client{Timeout: timeout, Transport: auth.NewTransport(secret)},
+	}
+}
+
+// NewBotTransport returns the client that delivers updates to the bot over the configured transport.
+// If a fallback transport is configured, the client switches to it while the primary one is failing.
+// Updates go over gRPC through updates, which has to be served by the scrapper.
+func NewBotTransport(appConfig *config.Config, updates *GRPCBotClient) (HTTPBotClient, error) {
+	primary, err := newTransport(appConfig, appConfig.BotTransport, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	if appConfig.BotFallbackTransport == "" {
+		return primary, nil
+	}
+
+	secondary, err := newTransport(appConfig, appConfig.BotFallbackTransport, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFallbackBotClient(appConfig.BotTransport, primary, appConfig.BotFallbackTransport, secondary,
+		appConfig.FallbackFailureThreshold, appConfig.FallbackProbeInterval), nil
+}
+
+func newTransport(appConfig *config.Config, transport string, updates *GRPCBotClient) (HTTPBotClient, error) {
+	switch transport {
+	case config.TransportHTTP:
+		return NewBotClient("http", appConfig.BotHost, "/updates", appConfig.BotRequestTimeout, appConfig.ServiceSecret), nil
+	case config.TransportKafka:
+		return NewKafkaBotClient(appConfig.KafkaBrokers, appConfig.KafkaUpdatesTopic), nil
+	case config.TransportGRPC:
+		return updates, nil
+	default:
+		return nil, e.ErrUnknownTransport
+	}
+}
+
+func (c *BotClient) SendUpdate(ctx context.Context, update bottypes.LinkUpdate) (err error) {
+	_, err = c.post(ctx, c.basePath, update)
+
+	return err
+}
+
+// SendUpdates sends the updates in one request. Updates the bot rejected as invalid get e.ErrMalformedMsg,
+// so they are not retried.
+func (c *BotClient) SendUpdates(ctx context.Context, updates []bottypes.LinkUpdate) []error {
+	errs := make([]error, len(updates))
+
+	body, err := c.post(ctx, path.Join(c.basePath, "batch"), updates)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+
+		return errs
+	}
+
+	var response bottypes.BatchUpdateResponse
+
+	if errDecode := json.Unmarshal(body, &response); errDecode != nil || len(response.Results) != len(updates) {
+		slog.Error(
+			e.ErrDecodeJSONBody.Error(),
+			slog.Int("updates", len(updates)),
+			slog.Int("results", len(response.Results)),
+			slog.String("response", string(body)),
+		)
+
+		for i := range errs {
+			errs[i] = e.ErrDecodeJSONBody
+		}
+
+		return errs
+	}
+
+	for i, result := range response.Results {
+		switch result.Status {
+		case bottypes.UpdateDelivered, bottypes.UpdateDuplicate:
+		case bottypes.UpdateInvalid:
+			errs[i] = fmt.Errorf("%w: %s", e.ErrMalformedMsg, result.Error)
+		default:
+			errs[i] = fmt.Errorf("%w: %s", e.ErrAPI, result.Error)
+		}
 	}
+
+	return errs
 }
 
-func (c *BotClient) SendUpdate(update bottypes.LinkUpdate) (err error) {
+func (c *BotClient) post(ctx context.Context, urlPath string, payload any) ([]byte, error) {
 	u := url.URL{
 		Scheme: c.scheme,
 		Host:   c.host,
-		Path:   c.basePath,
+		Path:   urlPath,
 	}
 
-	jsonData, err := json.Marshal(update)
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		slog.Error(
 			e.ErrMarshalJSON.Error(),
 			slog.String("error", err.Error()),
 		)
 
-		return e.ErrMarshalJSON
+		return nil, e.ErrMarshalJSON
+	}
+
+	req, errMakeReq := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewBuffer(jsonData))
+	if errMakeReq != nil {
+		slog.Error(
+			e.ErrMakeRequest.Error(),
+			slog.String("error", errMakeReq.Error()),
+			slog.String("url", u.String()),
+		)
+
+		return nil, e.ErrMakeRequest
 	}
 
-	resp, errDoReq := http.Post(u.String(), "application/json", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
 
+	req, span := tracing.StartRequest(req, "bot "+urlPath)
+
+	resp, errDoReq := c.client.Do(req)
+	tracing.FinishRequest(span, resp, errDoReq)
 	if errDoReq != nil {
 		slog.Error(
 			e.ErrDoRequest.Error(),
@@ -55,12 +171,29 @@ func (c *BotClient) SendUpdate(update bottypes.LinkUpdate) (err error) {
 			slog.String("url", u.String()),
 		)
 
-		return e.ErrDoRequest
+		return nil, e.ErrDoRequest
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			slog.Error(
+				e.ErrCloseBody.Error(),
+				slog.String("error", errClose.Error()),
+			)
+		}
+	}()
+
+	body, errRead := io.ReadAll(resp.Body)
+	if errRead != nil {
+		slog.Error(
+			e.ErrReadBody.Error(),
+			slog.String("error", errRead.Error()),
+		)
 
+		return nil, e.ErrReadBody
+	}
+
+	if resp.StatusCode != http.StatusOK {
 		var apiError bottypes.APIErrorResponse
 
 		if errDecode := json.Unmarshal(body, &apiError); errDecode != nil {
@@ -76,18 +209,30 @@ func (c *BotClient) SendUpdate(update bottypes.LinkUpdate) (err error) {
 			slog.String("error", apiError.Description),
 		)
 
-		return e.ErrAPI
+		switch resp.StatusCode {
+		case http.StatusBadRequest:
+			return nil, e.ErrMalformedMsg
+		case http.StatusUnauthorized:
+			return nil, e.ErrUnauthorized
+		}
+
+		return nil, e.ErrAPI
 	}
 
-	errClose := resp.Body.Close()
-	if errClose != nil {
-		slog.Error(
-			e.ErrCloseBody.Error(),
-			slog.String("error", errClose.Error()),
-		)
+	return body, nil
+}
+
+// sendUpdates sends the updates in one call if the client supports it and one by one otherwise.
+func sendUpdates(ctx context.Context, client HTTPBotClient, updates []bottypes.LinkUpdate) []error {
+	if batchClient, ok := client.(BatchBotClient); ok {
+		return batchClient.SendUpdates(ctx, updates)
+	}
+
+	errs := make([]error, len(updates))
 
-		return e.ErrCloseBody
+	for i, update := range updates {
+		errs[i] = client.SendUpdate(ctx, update)
 	}
 
-	return nil
+	return errs
 }
@@ -0,0 +1,101 @@
+package scrapper_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/auth"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotClient_SendUpdates(t *testing.T) {
+	type TestCase struct {
+		name         string
+		status       int
+		response     string
+		botSecret    string
+		expectedErrs []error
+	}
+
+	testCases := []TestCase{
+		{
+			name:         "per-item results",
+			status:       http.StatusOK,
+			response:     `{"results":[{"status":"delivered"},{"status":"duplicate"},{"status":"invalid"},{"status":"failed"}]}`,
+			expectedErrs: []error{nil, nil, e.ErrMalformedMsg, e.ErrAPI},
+		},
+		{
+			name:         "bot error",
+			status:       http.StatusInternalServerError,
+			response:     `{"description":"internal error"}`,
+			expectedErrs: []error{e.ErrAPI, e.ErrAPI, e.ErrAPI, e.ErrAPI},
+		},
+		{
+			name:         "results do not match updates",
+			status:       http.StatusOK,
+			response:     `{"results":[{"status":"delivered"}]}`,
+			expectedErrs: []error{e.ErrDecodeJSONBody, e.ErrDecodeJSONBody, e.ErrDecodeJSONBody, e.ErrDecodeJSONBody},
+		},
+		{
+			name:         "bot does not accept the signature",
+			botSecret:    "other-secret",
+			expectedErrs: []error{e.ErrUnauthorized, e.ErrUnauthorized, e.ErrUnauthorized, e.ErrUnauthorized},
+		},
+	}
+
+	updates := []bottypes.LinkUpdate{
+		{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-1"},
+		{ID: 2, URL: "https://github.com/c/d", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-2"},
+		{ID: 3, TgChatIDs: []int64{1}, IdempotencyKey: "outbox-3"},
+		{ID: 4, URL: "https://github.com/e/f", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-4"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			botSecret := "secret"
+			if testCase.botSecret != "" {
+				botSecret = testCase.botSecret
+			}
+
+			reject := func(w http.ResponseWriter, _ error) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+
+			server := httptest.NewServer(auth.Middleware(botSecret, time.Minute, reject,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/updates/batch", r.URL.Path)
+
+					var got []bottypes.LinkUpdate
+
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+					assert.Equal(t, updates, got)
+
+					w.WriteHeader(testCase.status)
+					_, _ = w.Write([]byte(testCase.response))
+				})))
+			defer server.Close()
+
+			client := scrapper.NewBotClient("http", strings.TrimPrefix(server.URL, "http://"), "/updates", time.Second, "secret")
+
+			errs := client.SendUpdates(context.Background(), updates)
+			require.Len(t, errs, len(updates))
+
+			for i, err := range errs {
+				if testCase.expectedErrs[i] == nil {
+					assert.NoError(t, err, "update %d", i)
+				} else {
+					assert.ErrorIs(t, err, testCase.expectedErrs[i], "update %d", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package scrapper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/api/openapi/v1/contract"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/scrappertypes"
+	repository "go-progira/internal/repository/dictionary_storage"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const specPath = "../../../api/openapi/v1/service.yaml"
+
+// contractStorage keeps one chat with one link, the methods the handlers don't call panic.
+type contractStorage struct {
+	repository.LinkService
+}
+
+func (contractStorage) CreateChat(_ context.Context, id int64) error {
+	if id == 1 {
+		return e.ErrChatAlreadyExists
+	}
+
+	return nil
+}
+
+func (contractStorage) DeleteChat(_ context.Context, id int64) error {
+	if id != 1 {
+		return e.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (contractStorage) GetLinks(_ context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error) {
+	if id != 1 {
+		return scrappertypes.LinksPage{}, e.ErrChatNotFound
+	}
+
+	added := time.Date(2025, time.May, 5, 18, 0, 0, 0, time.UTC)
+
+	return repository.PaginateLinks([]scrappertypes.LinkResponse{
+		{ID: 1, URL: "https://github.com/a/b", Tags: []string{"work"}, Filters: []string{}, AddedAt: added},
+		{ID: 2, URL: "https://stackoverflow.com/questions/1", AddedAt: added.Add(time.Hour)},
+		{ID: 3, URL: "https://github.com/a/c", AddedAt: added.Add(2 * time.Hour)},
+	}, query), nil
+}
+
+func (contractStorage) RemoveLink(_ context.Context, _ int64, link string) error {
+	switch link {
+	case "https://github.com/a/b":
+		return nil
+	case "https://github.com/a/down":
+		return errors.New("connection to the database is lost")
+	default:
+		return e.ErrLinkNotFound
+	}
+}
+
+func (contractStorage) EnableLink(_ context.Context, _ int64, link string) error {
+	if link != "https://github.com/a/b" {
+		return e.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+func (contractStorage) UpdateLink(_ context.Context, _ int64, link string, change scrappertypes.LinkChange) (
+	scrappertypes.LinkResponse, error) {
+	if link != "https://github.com/a/b" {
+		return scrappertypes.LinkResponse{}, e.ErrLinkNotFound
+	}
+
+	return scrappertypes.LinkResponse{ID: 1, URL: link, Tags: change.AddTags, Filters: []string{}}, nil
+}
+
+func (contractStorage) IsURLInAdded(_ context.Context, _ int64, link string) bool {
+	return link == "https://github.com/a/b/pulls"
+}
+
+func (contractStorage) DeleteTag(_ context.Context, _ int64, tag string) error {
+	if tag != "work" {
+		return e.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (contractStorage) ListTags(_ context.Context, id int64) ([]scrappertypes.TagResponse, error) {
+	if id != 1 {
+		return []scrappertypes.TagResponse{}, nil
+	}
+
+	return []scrappertypes.TagResponse{{Name: "work", Links: 1}}, nil
+}
+
+func (contractStorage) RenameTag(_ context.Context, _ int64, tag, newName string, merge bool) (scrappertypes.TagResponse, error) {
+	switch {
+	case tag != "work" || merge && newName != "home":
+		return scrappertypes.TagResponse{}, e.ErrTagNotFound
+	case !merge && newName == "home":
+		return scrappertypes.TagResponse{}, e.ErrTagAlreadyExists
+	}
+
+	return scrappertypes.TagResponse{Name: newName, Links: 1}, nil
+}
+
+func TestServer_Contract(t *testing.T) {
+	validator, err := contract.NewValidator(specPath)
+	require.NoError(t, err)
+
+	handler := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "register chat", method: http.MethodPost, path: "/tg-chat/2", wantStatus: http.StatusOK},
+		{
+			name:       "register chat twice",
+			method:     http.MethodPost,
+			path:       "/tg-chat/1",
+			wantStatus: http.StatusConflict,
+			wantCode:   scrappertypes.CodeChatAlreadyExists,
+		},
+		{name: "delete chat", method: http.MethodDelete, path: "/tg-chat/1", wantStatus: http.StatusOK},
+		{
+			name:       "delete unknown chat",
+			method:     http.MethodDelete,
+			path:       "/tg-chat/2",
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeChatNotFound,
+		},
+		{name: "get links", method: http.MethodGet, path: "/links?Tg-Chat-Id=1", wantStatus: http.StatusOK},
+		{
+			name:       "get page of links",
+			method:     http.MethodGet,
+			path:       "/links?Tg-Chat-Id=1&limit=2&sort=provider&order=desc",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "get links of unknown chat",
+			method:     http.MethodGet,
+			path:       "/links?Tg-Chat-Id=2",
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeChatNotFound,
+		},
+		{
+			name:       "add link with wrong url",
+			method:     http.MethodPost,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"not a link"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidLink,
+		},
+		{
+			name:       "remove link",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "remove link with broken storage",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/down"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   scrappertypes.CodeInternal,
+		},
+		{
+			name:       "remove unknown link",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/c/d"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeLinkNotFound,
+		},
+		{
+			name:       "refresh unknown link",
+			method:     http.MethodPost,
+			path:       "/links/refresh?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/c/d"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeLinkNotFound,
+		},
+		{
+			name:       "enable link",
+			method:     http.MethodPost,
+			path:       "/links/enable?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "update link",
+			method:     http.MethodPatch,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b","add_tags":["go"],"remove_filters":["user=a"]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "update unknown link",
+			method:     http.MethodPatch,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/c/d","add_tags":["go"]}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeLinkNotFound,
+		},
+		{
+			name:       "update link without changes",
+			method:     http.MethodPatch,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{name: "export links", method: http.MethodGet, path: "/links/export?Tg-Chat-Id=1", wantStatus: http.StatusOK},
+		{name: "export links as csv", method: http.MethodGet, path: "/links/export?Tg-Chat-Id=1&format=csv", wantStatus: http.StatusOK},
+		{name: "export links as opml", method: http.MethodGet, path: "/links/export?Tg-Chat-Id=1&format=opml", wantStatus: http.StatusOK},
+		{
+			name:       "export links of unknown chat",
+			method:     http.MethodGet,
+			path:       "/links/export?Tg-Chat-Id=2",
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeChatNotFound,
+		},
+		{
+			name:       "import links",
+			method:     http.MethodPost,
+			path:       "/links/import?Tg-Chat-Id=1",
+			body:       `{"format":"csv","data":"https://github.com/a/b/pulls\nhttps://github.com/c/d/issues,go\n","dry_run":true}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "import unreadable file",
+			method:     http.MethodPost,
+			path:       "/links/import?Tg-Chat-Id=1",
+			body:       `{"format":"json","data":"{"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{name: "get links by tags", method: http.MethodGet, path: "/tags?Tg-Chat-Id=1&tag=work&tag=home", wantStatus: http.StatusOK},
+		{
+			name:       "delete tag",
+			method:     http.MethodDelete,
+			path:       "/tags?Tg-Chat-Id=1",
+			body:       `{"tag":"work"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "delete unknown tag",
+			method:     http.MethodDelete,
+			path:       "/tags?Tg-Chat-Id=1",
+			body:       `{"tag":"home"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeTagNotFound,
+		},
+		{name: "list tags", method: http.MethodGet, path: "/tags/counts?Tg-Chat-Id=1", wantStatus: http.StatusOK},
+		{
+			name:       "rename tag",
+			method:     http.MethodPost,
+			path:       "/tags/rename?Tg-Chat-Id=1",
+			body:       `{"tag":"work","new_name":"job"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rename tag to a used name",
+			method:     http.MethodPost,
+			path:       "/tags/rename?Tg-Chat-Id=1",
+			body:       `{"tag":"work","new_name":"home"}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   scrappertypes.CodeTagAlreadyExists,
+		},
+		{
+			name:       "merge tags",
+			method:     http.MethodPost,
+			path:       "/tags/merge?Tg-Chat-Id=1",
+			body:       `{"tag":"work","into":"home"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "merge into unknown tag",
+			method:     http.MethodPost,
+			path:       "/tags/merge?Tg-Chat-Id=1",
+			body:       `{"tag":"work","into":"job"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   scrappertypes.CodeTagNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			rec, err := validator.Serve(handler, req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantCode == "" {
+				return
+			}
+
+			var apiErr scrappertypes.APIErrorResponse
+
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+		})
+	}
+}
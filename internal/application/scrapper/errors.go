@@ -0,0 +1,95 @@
+package scrapper
+
+import (
+	"errors"
+	"fmt"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// apiError describes how an error of the scrapper is reported by the HTTP and gRPC APIs.
+type apiError struct {
+	err         error
+	status      int
+	grpcCode    codes.Code
+	code        string
+	description string
+	exception   string
+}
+
+// apiErrors lists the errors the bot can tell apart, the first one err matches wins.
+var apiErrors = []apiError{
+	{e.ErrInvalidRequest, http.StatusBadRequest, codes.InvalidArgument, scrappertypes.CodeInvalidRequest,
+		"Request is invalid", "ValidationError"},
+	{e.ErrWrongURLFormat, http.StatusBadRequest, codes.InvalidArgument, scrappertypes.CodeInvalidLink,
+		"Link can't be tracked", "ValidationError"},
+	{e.ErrChatNotFound, http.StatusNotFound, codes.NotFound, scrappertypes.CodeChatNotFound,
+		"Chat not found", "NotFoundError"},
+	{e.ErrChatAlreadyExists, http.StatusConflict, codes.AlreadyExists, scrappertypes.CodeChatAlreadyExists,
+		"Chat is registered already", "ConflictError"},
+	{e.ErrLinkNotFound, http.StatusNotFound, codes.NotFound, scrappertypes.CodeLinkNotFound,
+		"Link not found", "NotFoundError"},
+	{e.ErrLinkAlreadyExists, http.StatusConflict, codes.AlreadyExists, scrappertypes.CodeLinkAlreadyExists,
+		"Link is tracked already", "ConflictError"},
+	{e.ErrTagNotFound, http.StatusNotFound, codes.NotFound, scrappertypes.CodeTagNotFound,
+		"Tag not found", "NotFoundError"},
+	{e.ErrTagAlreadyExists, http.StatusConflict, codes.AlreadyExists, scrappertypes.CodeTagAlreadyExists,
+		"Tag is used already", "ConflictError"},
+	{e.ErrResourceNotFound, http.StatusNotFound, codes.NotFound, scrappertypes.CodeResourceNotFound,
+		"Resource not found", "ProviderError"},
+	{e.ErrResourcePrivate, http.StatusForbidden, codes.PermissionDenied, scrappertypes.CodeResourcePrivate,
+		"Resource is private or not accessible", "ProviderError"},
+	{e.ErrRateLimited, http.StatusTooManyRequests, codes.ResourceExhausted, scrappertypes.CodeRateLimited,
+		"Provider rate limit exceeded", "ProviderError"},
+	{e.ErrRefreshTooOften, http.StatusTooManyRequests, codes.ResourceExhausted, scrappertypes.CodeRefreshTooOften,
+		"Links of the chat were refreshed recently", "RateLimitError"},
+	{e.ErrProviderUnavailable, http.StatusBadGateway, codes.Unavailable, scrappertypes.CodeProviderUnavailable,
+		"Provider is unavailable", "ProviderError"},
+	{e.ErrMethodNotAllowed, http.StatusMethodNotAllowed, codes.Unimplemented, scrappertypes.CodeMethodNotAllowed,
+		"Method not allowed", "ValidationError"},
+	{e.ErrFallbackDisabled, http.StatusNotFound, codes.FailedPrecondition, scrappertypes.CodeFallbackDisabled,
+		"Fallback transport is disabled", "NotFoundError"},
+}
+
+// lookupError finds how err is reported, the errors that are not known are internal ones.
+func lookupError(err error) (apiError, bool) {
+	for _, known := range apiErrors {
+		if errors.Is(err, known.err) {
+			return known, true
+		}
+	}
+
+	return apiError{
+		err:         err,
+		status:      http.StatusInternalServerError,
+		grpcCode:    codes.Internal,
+		code:        scrappertypes.CodeInternal,
+		description: "Internal error",
+		exception:   "InternalError",
+	}, false
+}
+
+// sendError answers with the APIErrorResponse err is reported as.
+// The message of an internal error is only logged, it may tell about the storage.
+func sendError(w http.ResponseWriter, err error) {
+	known, ok := lookupError(err)
+	message := err.Error()
+
+	if !ok {
+		slog.Error("Error serving request",
+			slog.String("error", err.Error()))
+
+		message = http.StatusText(known.status)
+	}
+
+	sendErrorResponse(w, known.status, known.description, known.code, known.exception, message)
+}
+
+// invalidRequest wraps the reason the request was rejected with e.ErrInvalidRequest.
+func invalidRequest(reason string) error {
+	return fmt.Errorf("%w: %s", e.ErrInvalidRequest, reason)
+}
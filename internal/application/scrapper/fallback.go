@@ -0,0 +1,205 @@
+package scrapper
+
+import (
+	"context"
+	"errors"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/pkg/e"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DeliveryStats tells which transport delivered the updates.
+type DeliveryStats struct {
+	Active       string           `json:"active"`
+	Switches     int64            `json:"switches"`
+	LastSwitchAt time.Time        `json:"last_switch_at,omitempty"`
+	Delivered    map[string]int64 `json:"delivered"`
+	Failed       map[string]int64 `json:"failed"`
+}
+
+// DeliveryReporter is implemented by bot clients that collect delivery stats.
+type DeliveryReporter interface {
+	DeliveryStats() DeliveryStats
+}
+
+// FallbackBotClient sends updates over the primary transport and switches to the secondary one
+// after failureThreshold failures in a row. While on the secondary transport it probes the primary one
+// at most once per probeInterval and switches back as soon as a probe succeeds.
+type FallbackBotClient struct {
+	primary, secondary         HTTPBotClient
+	primaryName, secondaryName string
+	failureThreshold           int
+	probeInterval              time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	fallback  bool
+	nextProbe time.Time
+	stats     DeliveryStats
+}
+
+func NewFallbackBotClient(primaryName string, primary HTTPBotClient, secondaryName string, secondary HTTPBotClient,
+	failureThreshold int, probeInterval time.Duration) *FallbackBotClient {
+	return &FallbackBotClient{
+		primary:          primary,
+		secondary:        secondary,
+		primaryName:      primaryName,
+		secondaryName:    secondaryName,
+		failureThreshold: failureThreshold,
+		probeInterval:    probeInterval,
+		stats: DeliveryStats{
+			Active:    primaryName,
+			Delivered: map[string]int64{primaryName: 0, secondaryName: 0},
+			Failed:    map[string]int64{primaryName: 0, secondaryName: 0},
+		},
+	}
+}
+
+func (c *FallbackBotClient) SendUpdate(ctx context.Context, update bottypes.LinkUpdate) error {
+	return c.SendUpdates(ctx, []bottypes.LinkUpdate{update})[0]
+}
+
+// SendUpdates sends the updates over the active transport. When the primary transport fails
+// and the client switches to the secondary one, the failed updates are sent over it right away.
+func (c *FallbackBotClient) SendUpdates(ctx context.Context, updates []bottypes.LinkUpdate) []error {
+	errs := make([]error, len(updates))
+	pending := make([]int, 0, len(updates))
+
+	for i := range updates {
+		pending = append(pending, i)
+	}
+
+	if c.usePrimary(time.Now()) {
+		errs = sendUpdates(ctx, c.primary, updates)
+		pending = pending[:0]
+
+		for i, err := range errs {
+			// Updates the bot rejected as malformed say nothing about the transport.
+			if err != nil && !errors.Is(err, e.ErrMalformedMsg) {
+				pending = append(pending, i)
+			}
+		}
+
+		if c.primaryResult(len(updates)-len(pending), len(pending), time.Now()) {
+			return errs
+		}
+	}
+
+	retry := make([]bottypes.LinkUpdate, 0, len(pending))
+
+	for _, i := range pending {
+		retry = append(retry, updates[i])
+	}
+
+	secondaryErrs := sendUpdates(ctx, c.secondary, retry)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for n, i := range pending {
+		errs[i] = secondaryErrs[n]
+
+		if secondaryErrs[n] != nil {
+			c.stats.Failed[c.secondaryName]++
+		} else {
+			c.stats.Delivered[c.secondaryName]++
+		}
+	}
+
+	return errs
+}
+
+// usePrimary reports whether the update goes over the primary transport,
+// either because there is no fallback or because it is time to probe it.
+func (c *FallbackBotClient) usePrimary(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fallback {
+		return true
+	}
+
+	if now.Before(c.nextProbe) {
+		return false
+	}
+
+	c.nextProbe = now.Add(c.probeInterval)
+
+	return true
+}
+
+// primaryResult records the outcome of a send over the primary transport and reports
+// whether it is final. Otherwise the failed updates have to be sent over the secondary transport.
+// The send counts as a failure only if nothing was delivered.
+func (c *FallbackBotClient) primaryResult(delivered, failed int, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Delivered[c.primaryName] += int64(delivered)
+	c.stats.Failed[c.primaryName] += int64(failed)
+
+	if failed == 0 || delivered > 0 {
+		c.failures = 0
+
+		if c.fallback {
+			c.fallback = false
+			c.switchTo(c.primaryName, now)
+		}
+
+		return true
+	}
+
+	c.failures++
+
+	if !c.fallback && c.failures >= c.failureThreshold {
+		c.fallback = true
+		c.nextProbe = now.Add(c.probeInterval)
+		c.switchTo(c.secondaryName, now)
+	}
+
+	return !c.fallback
+}
+
+func (c *FallbackBotClient) switchTo(name string, now time.Time) {
+	slog.Warn("Switching bot transport",
+		slog.String("from", c.stats.Active),
+		slog.String("to", name))
+
+	c.stats.Active = name
+	c.stats.Switches++
+	c.stats.LastSwitchAt = now
+}
+
+func (c *FallbackBotClient) DeliveryStats() DeliveryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Delivered = make(map[string]int64, len(c.stats.Delivered))
+	stats.Failed = make(map[string]int64, len(c.stats.Failed))
+
+	for name, n := range c.stats.Delivered {
+		stats.Delivered[name] = n
+	}
+
+	for name, n := range c.stats.Failed {
+		stats.Failed[name] = n
+	}
+
+	return stats
+}
+
+func (c *FallbackBotClient) Close() error {
+	var errs []error
+
+	for _, client := range []HTTPBotClient{c.primary, c.secondary} {
+		if closer, ok := client.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package scrapper_test
+
+import (
+	"context"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/pkg/e"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTransport struct {
+	err  error
+	sent int
+}
+
+func (f *fakeTransport) SendUpdate(_ context.Context, _ bottypes.LinkUpdate) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.sent++
+
+	return nil
+}
+
+func TestFallbackBotClient(t *testing.T) {
+	primary := &fakeTransport{err: e.ErrAPI}
+	secondary := &fakeTransport{}
+	probeInterval := 20 * time.Millisecond
+
+	client := scrapper.NewFallbackBotClient("http", primary, "kafka", secondary, 2, probeInterval)
+	update := bottypes.LinkUpdate{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1}}
+
+	assert.ErrorIs(t, client.SendUpdate(context.Background(), update), e.ErrAPI, "first failure must not switch transports")
+	assert.Equal(t, 0, secondary.sent)
+
+	assert.NoError(t, client.SendUpdate(context.Background(), update), "update must be sent over the fallback after the threshold")
+	assert.Equal(t, 1, secondary.sent)
+	assert.Equal(t, "kafka", client.DeliveryStats().Active)
+
+	primary.err = nil
+
+	assert.NoError(t, client.SendUpdate(context.Background(), update))
+	assert.Equal(t, 2, secondary.sent, "primary must not be probed before the interval")
+	assert.Equal(t, 0, primary.sent)
+
+	time.Sleep(probeInterval)
+
+	assert.NoError(t, client.SendUpdate(context.Background(), update))
+	assert.Equal(t, 1, primary.sent, "primary must be used again after a successful probe")
+
+	stats := client.DeliveryStats()
+	assert.Equal(t, "http", stats.Active)
+	assert.Equal(t, int64(2), stats.Switches)
+	assert.Equal(t, map[string]int64{"http": 1, "kafka": 2}, stats.Delivered)
+	assert.Equal(t, map[string]int64{"http": 2, "kafka": 0}, stats.Failed)
+}
@@ -0,0 +1,473 @@
+package scrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/application/scrapper/api"
+	"go-progira/internal/auth"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errorDomain is the domain of the ErrorInfo attached to the errors of the gRPC API.
+const errorDomain = "scrapper"
+
+// GRPCServer serves the gRPC API of the scrapper. It shares the storage and the checks with Server.
+type GRPCServer struct {
+	scrapper *Server
+}
+
+var _ protov1.ScrapperServiceServer = (*GRPCServer)(nil)
+
+func NewGRPCServer(scrapper *Server) *GRPCServer {
+	return &GRPCServer{scrapper: scrapper}
+}
+
+func (g *GRPCServer) RegisterChat(ctx context.Context, req *protov1.RegisterChatRequest) (*protov1.RegisterChatResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	if err := g.scrapper.Storage.CreateChat(ctx, req.GetChatId()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	slog.Info("Registered chat")
+
+	return &protov1.RegisterChatResponse{}, nil
+}
+
+func (g *GRPCServer) DeleteChat(ctx context.Context, req *protov1.DeleteChatRequest) (*protov1.DeleteChatResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	if err := g.scrapper.Storage.DeleteChat(ctx, req.GetChatId()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.DeleteChatResponse{}, nil
+}
+
+func (g *GRPCServer) ListLinks(ctx context.Context, req *protov1.ListLinksRequest) (*protov1.ListLinksResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	paged := req.GetLimit() != 0 || req.GetCursor() != ""
+	if paged && len(req.GetTags()) != 0 {
+		return nil, grpcError(invalidRequest("tags can't be combined with pages"))
+	}
+
+	request := scrappertypes.GetLinksRequest{
+		Sort:   linkSorts[req.GetSort()],
+		Cursor: req.GetCursor(),
+		Limit:  int(req.GetLimit()),
+	}
+	if req.GetDescending() {
+		request.Order = scrappertypes.OrderDesc
+	}
+
+	list, err := g.scrapper.listLinks(ctx, req.GetChatId(), request)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	links := list.Links
+	if len(req.GetTags()) != 0 {
+		links = filterLinksByTags(links, req.GetTags())
+	}
+
+	response := &protov1.ListLinksResponse{
+		Links:      make([]*protov1.Link, 0, len(links)),
+		Size:       int32(len(links)),
+		NextCursor: list.NextCursor,
+		PrevCursor: list.PrevCursor,
+	}
+	for i := range links {
+		response.Links = append(response.Links, toProtoLink(&links[i]))
+	}
+
+	return response, nil
+}
+
+var linkSorts = map[protov1.LinkSort]string{
+	protov1.LinkSort_LINK_SORT_ADDED:    scrappertypes.SortAdded,
+	protov1.LinkSort_LINK_SORT_ACTIVITY: scrappertypes.SortActivity,
+	protov1.LinkSort_LINK_SORT_URL:      scrappertypes.SortURL,
+	protov1.LinkSort_LINK_SORT_PROVIDER: scrappertypes.SortProvider,
+}
+
+func (g *GRPCServer) AddLink(ctx context.Context, req *protov1.AddLinkRequest) (*protov1.AddLinkResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	if req.GetIntervalSeconds() < 0 {
+		return nil, grpcError(invalidRequest("check interval is negative"))
+	}
+
+	link, errParse := api.CanonicalLink(req.GetLink())
+	if errParse != nil {
+		return nil, grpcError(e.ErrWrongURLFormat)
+	}
+
+	resource, err := resolve(ctx, link)
+	if err != nil {
+		return nil, grpcError(providerError(err))
+	}
+
+	response, err := g.scrapper.trackLink(ctx, req.GetChatId(), link, scrappertypes.AddLinkRequest{
+		Link:            req.GetLink(),
+		Tags:            req.GetTags(),
+		Filters:         req.GetFilters(),
+		IntervalSeconds: req.GetIntervalSeconds(),
+	}, resource)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.AddLinkResponse{Link: toProtoLink(&response)}, nil
+}
+
+func (g *GRPCServer) RemoveLink(ctx context.Context, req *protov1.RemoveLinkRequest) (*protov1.RemoveLinkResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	err := withStoredLink(req.GetLink(), func(link string) error {
+		return g.scrapper.Storage.RemoveLink(ctx, req.GetChatId(), link)
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.RemoveLinkResponse{}, nil
+}
+
+func (g *GRPCServer) RefreshLinks(ctx context.Context, req *protov1.RefreshLinksRequest) (*protov1.RefreshLinksResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	response, retryAfter, err := g.scrapper.refreshChat(ctx, req.GetChatId(), req.GetLink())
+	if errors.Is(err, e.ErrRefreshTooOften) {
+		return nil, grpcError(err, "retry_after_seconds", strconv.Itoa(int(retryAfter.Seconds())+1))
+	} else if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.RefreshLinksResponse{
+		Checked:    int32(response.Checked),
+		Updated:    response.Updated,
+		InProgress: int32(response.InProgress),
+		Broken:     response.Broken,
+	}, nil
+}
+
+func (g *GRPCServer) EnableLink(ctx context.Context, req *protov1.EnableLinkRequest) (*protov1.EnableLinkResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	err := withStoredLink(req.GetLink(), func(link string) error {
+		return g.scrapper.Storage.EnableLink(ctx, req.GetChatId(), link)
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.EnableLinkResponse{}, nil
+}
+
+func (g *GRPCServer) UpdateLink(ctx context.Context, req *protov1.UpdateLinkRequest) (*protov1.UpdateLinkResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	link, err := g.scrapper.updateLink(ctx, req.GetChatId(), scrappertypes.UpdateLinkRequest{
+		Link: req.GetLink(),
+		LinkChange: scrappertypes.LinkChange{
+			AddTags:       req.GetAddTags(),
+			RemoveTags:    req.GetRemoveTags(),
+			AddFilters:    req.GetAddFilters(),
+			RemoveFilters: req.GetRemoveFilters(),
+		},
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.UpdateLinkResponse{Link: toProtoLink(&link)}, nil
+}
+
+func (g *GRPCServer) ExportLinks(ctx context.Context, req *protov1.ExportLinksRequest) (*protov1.ExportLinksResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	format := scrappertypes.FormatJSON
+	if req.GetFormat() != protov1.LinkFileFormat_LINK_FILE_FORMAT_UNSPECIFIED {
+		format = fileFormats[req.GetFormat()]
+	}
+
+	data, contentType, err := g.scrapper.exportLinks(ctx, req.GetChatId(), format)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.ExportLinksResponse{Data: data, ContentType: contentType}, nil
+}
+
+func (g *GRPCServer) ImportLinks(ctx context.Context, req *protov1.ImportLinksRequest) (*protov1.ImportLinksResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	report, err := g.scrapper.importLinks(ctx, req.GetChatId(), scrappertypes.ImportRequest{
+		Format: fileFormats[req.GetFormat()],
+		Data:   string(req.GetData()),
+		DryRun: req.GetDryRun(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &protov1.ImportLinksResponse{
+		Added:   int32(report.Added),
+		Skipped: int32(report.Skipped),
+		Invalid: int32(report.Invalid),
+		DryRun:  report.DryRun,
+	}
+	for _, row := range report.Rows {
+		response.Rows = append(response.Rows, &protov1.ImportRow{
+			Row:    int32(row.Row),
+			Link:   row.Link,
+			Status: importStatuses[row.Status],
+			Reason: row.Reason,
+		})
+	}
+
+	return response, nil
+}
+
+var fileFormats = map[protov1.LinkFileFormat]string{
+	protov1.LinkFileFormat_LINK_FILE_FORMAT_JSON: scrappertypes.FormatJSON,
+	protov1.LinkFileFormat_LINK_FILE_FORMAT_CSV:  scrappertypes.FormatCSV,
+	protov1.LinkFileFormat_LINK_FILE_FORMAT_OPML: scrappertypes.FormatOPML,
+}
+
+var importStatuses = map[string]protov1.ImportStatus{
+	scrappertypes.ImportAdded:   protov1.ImportStatus_IMPORT_STATUS_ADDED,
+	scrappertypes.ImportSkipped: protov1.ImportStatus_IMPORT_STATUS_SKIPPED,
+	scrappertypes.ImportInvalid: protov1.ImportStatus_IMPORT_STATUS_INVALID,
+}
+
+func (g *GRPCServer) DeleteTag(ctx context.Context, req *protov1.DeleteTagRequest) (*protov1.DeleteTagResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	if err := g.scrapper.Storage.DeleteTag(ctx, req.GetChatId(), req.GetTag()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.DeleteTagResponse{}, nil
+}
+
+func (g *GRPCServer) ListTags(ctx context.Context, req *protov1.ListTagsRequest) (*protov1.ListTagsResponse, error) {
+	if err := checkChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.scrapper.Storage.ListTags(ctx, req.GetChatId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &protov1.ListTagsResponse{Tags: make([]*protov1.Tag, 0, len(tags))}
+	for _, tag := range tags {
+		response.Tags = append(response.Tags, toProtoTag(tag))
+	}
+
+	return response, nil
+}
+
+func (g *GRPCServer) RenameTag(ctx context.Context, req *protov1.RenameTagRequest) (*protov1.RenameTagResponse, error) {
+	tag, err := g.scrapper.renameTag(ctx, req.GetChatId(), req.GetTag(), req.GetNewName(), false)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.RenameTagResponse{Tag: toProtoTag(tag)}, nil
+}
+
+func (g *GRPCServer) MergeTags(ctx context.Context, req *protov1.MergeTagsRequest) (*protov1.MergeTagsResponse, error) {
+	tag, err := g.scrapper.renameTag(ctx, req.GetChatId(), req.GetTag(), req.GetInto(), true)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &protov1.MergeTagsResponse{Tag: toProtoTag(tag)}, nil
+}
+
+// StreamUpdates hands the stream of the bot to the client that delivers updates over gRPC.
+func (g *GRPCServer) StreamUpdates(stream protov1.ScrapperService_StreamUpdatesServer) error {
+	if g.scrapper.Updates == nil {
+		return status.Error(codes.FailedPrecondition, "updates are not delivered over gRPC")
+	}
+
+	return g.scrapper.Updates.Serve(stream)
+}
+
+// startGRPC serves the gRPC API next to HTTP. Errors of the server are reported through Errors.
+func (s *Server) startGRPC(config *config.Config) {
+	s.grpcServer = grpc.NewServer(
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(config.ServiceSecret, config.SignatureMaxAge)),
+		grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(config.ServiceSecret, config.SignatureMaxAge)),
+	)
+	s.grpcHealth = grpchealth.NewServer()
+	s.grpcHealth.Shutdown()
+
+	protov1.RegisterScrapperServiceServer(s.grpcServer, NewGRPCServer(s))
+	healthpb.RegisterHealthServer(s.grpcServer, s.grpcHealth)
+
+	listener, err := net.Listen("tcp", config.ScrapperGRPCHost)
+	if err != nil {
+		slog.Error(
+			e.ErrServerFailed.Error(),
+			slog.String("error", err.Error()),
+		)
+
+		s.errs <- err
+
+		return
+	}
+
+	slog.Info("Starting scrapper gRPC server on",
+		slog.String("address", config.ScrapperGRPCHost))
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			slog.Error(
+				e.ErrServerFailed.Error(),
+				slog.String("error", err.Error()),
+			)
+
+			s.errs <- err
+		}
+	}()
+}
+
+func checkChatID(id int64) error {
+	if id <= 0 {
+		return grpcError(invalidRequest("invalid chat ID"))
+	}
+
+	return nil
+}
+
+func canonicalOrRaw(link string) string {
+	if canonical, err := api.CanonicalLink(link); err == nil {
+		return canonical
+	}
+
+	return link
+}
+
+// withStoredLink calls call with the canonical form of link and, when the chat has no such link, with link as it was sent:
+// links saved before links were canonicalized are stored in the form they were sent in.
+func withStoredLink(link string, call func(link string) error) error {
+	canonical := canonicalOrRaw(link)
+
+	err := call(canonical)
+	if errors.Is(err, e.ErrLinkNotFound) && canonical != link {
+		return call(link)
+	}
+
+	return err
+}
+
+// grpcError converts err to a status with the ErrorInfo the bot maps back to the error.
+// Metadata holds key and value pairs added to the ErrorInfo. As in sendError, the message of an internal error is only logged.
+func grpcError(err error, metadata ...string) error {
+	known, ok := lookupError(err)
+	if !ok {
+		slog.Error("Error serving gRPC call",
+			slog.String("error", err.Error()))
+
+		return status.Error(known.grpcCode, http.StatusText(known.status))
+	}
+
+	info := &errdetails.ErrorInfo{Reason: known.code, Domain: errorDomain, Metadata: map[string]string{}}
+	for i := 0; i+1 < len(metadata); i += 2 {
+		info.Metadata[metadata[i]] = metadata[i+1]
+	}
+
+	st, errDetails := status.New(known.grpcCode, err.Error()).WithDetails(info)
+	if errDetails != nil {
+		return status.Error(known.grpcCode, err.Error())
+	}
+
+	return st.Err()
+}
+
+// providerError reports the errors of a provider that are not known as an unavailable provider, like the HTTP API.
+func providerError(err error) error {
+	for _, known := range []error{e.ErrWrongURLFormat, e.ErrResourceNotFound, e.ErrResourcePrivate, e.ErrRateLimited} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: %w", e.ErrProviderUnavailable, err)
+}
+
+func toProtoLink(link *scrappertypes.LinkResponse) *protov1.Link {
+	result := &protov1.Link{
+		Id:                  link.ID,
+		Url:                 link.URL,
+		Tags:                link.Tags,
+		Filters:             link.Filters,
+		LastVersion:         link.LastVersion,
+		Title:               link.Title,
+		Broken:              link.Broken,
+		ConsecutiveFailures: int32(link.ConsecutiveFailures),
+		LastError:           link.LastError,
+	}
+
+	if !link.LastChecked.IsZero() {
+		result.LastChecked = timestamppb.New(link.LastChecked)
+	}
+
+	if link.LastSuccessAt != nil {
+		result.LastSuccessAt = timestamppb.New(*link.LastSuccessAt)
+	}
+
+	if !link.AddedAt.IsZero() {
+		result.AddedAt = timestamppb.New(link.AddedAt)
+	}
+
+	return result
+}
+
+func toProtoTag(tag scrappertypes.TagResponse) *protov1.Tag {
+	return &protov1.Tag{Name: tag.Name, Links: int32(tag.Links)}
+}
@@ -0,0 +1,279 @@
+package scrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/e"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCBotClient pushes link updates over the streams the bots opened with StreamUpdates
+// and waits until the bot acknowledges every update. While no bot is connected updates fail
+// with e.ErrNotConnected, so they stay in the outbox.
+type GRPCBotClient struct {
+	timeout time.Duration
+	ids     atomic.Uint64
+
+	mu      sync.Mutex
+	streams []*updateStream
+	turn    int
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// updateStream is the stream of one connected bot.
+type updateStream struct {
+	stream protov1.ScrapperService_StreamUpdatesServer
+
+	// sendMu serializes the sends, a stream must not be sent to from several goroutines.
+	sendMu sync.Mutex
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pending map[uint64]chan *protov1.StreamUpdatesRequest
+}
+
+func NewGRPCBotClient(timeout time.Duration) *GRPCBotClient {
+	return &GRPCBotClient{
+		timeout: timeout,
+		closed:  make(chan struct{}),
+	}
+}
+
+func (c *GRPCBotClient) SendUpdate(ctx context.Context, update bottypes.LinkUpdate) (err error) {
+	return c.SendUpdates(ctx, []bottypes.LinkUpdate{update})[0]
+}
+
+// SendUpdates sends the updates to one of the connected bots. Updates the bot rejected as invalid
+// get e.ErrMalformedMsg, so they are not retried.
+func (c *GRPCBotClient) SendUpdates(ctx context.Context, updates []bottypes.LinkUpdate) []error {
+	errs := make([]error, len(updates))
+
+	stream := c.pick()
+	if stream == nil {
+		for i := range errs {
+			errs[i] = e.ErrNotConnected
+		}
+
+		return errs
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ids := make([]uint64, len(updates))
+	acks := make([]chan *protov1.StreamUpdatesRequest, len(updates))
+
+	for i, update := range updates {
+		ids[i] = c.ids.Add(1)
+		acks[i] = stream.expect(ids[i])
+
+		msg := &protov1.StreamUpdatesResponse{
+			DeliveryId:   ids[i],
+			Update:       toProtoUpdate(update),
+			TraceContext: map[string]string{},
+		}
+		tracing.InjectMap(ctx, msg.TraceContext)
+
+		if err := stream.send(msg); err != nil {
+			stream.forget(ids[i])
+
+			acks[i], errs[i] = nil, err
+		}
+	}
+
+	for i, ack := range acks {
+		if ack == nil {
+			continue
+		}
+
+		select {
+		case result := <-ack:
+			errs[i] = ackError(result)
+		case <-stream.done:
+			errs[i] = e.ErrNotConnected
+		case <-ctx.Done():
+			stream.forget(ids[i])
+
+			errs[i] = fmt.Errorf("%w: %w", e.ErrDoRequest, ctx.Err())
+		}
+	}
+
+	return errs
+}
+
+// Serve sends updates over the stream of a bot until the bot disconnects or the client is closed.
+func (c *GRPCBotClient) Serve(stream protov1.ScrapperService_StreamUpdatesServer) error {
+	s := &updateStream{
+		stream:  stream,
+		done:    make(chan struct{}),
+		pending: make(map[uint64]chan *protov1.StreamUpdatesRequest),
+	}
+
+	c.add(s)
+	defer c.remove(s)
+
+	slog.Info("Bot connected to the updates stream")
+
+	errs := make(chan error, 1)
+
+	go func() {
+		for {
+			ack, err := stream.Recv()
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			s.resolve(ack)
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		slog.Info("Bot disconnected from the updates stream")
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return err
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-c.closed:
+		return status.Error(codes.Unavailable, "scrapper is shutting down")
+	}
+}
+
+// Close ends the streams of all bots, they reconnect to another instance.
+func (c *GRPCBotClient) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+// Connected is the number of bots connected to the updates stream.
+func (c *GRPCBotClient) Connected() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.streams)
+}
+
+// pick returns the streams of the connected bots in turn.
+func (c *GRPCBotClient) pick() *updateStream {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.streams) == 0 {
+		return nil
+	}
+
+	c.turn = (c.turn + 1) % len(c.streams)
+
+	return c.streams[c.turn]
+}
+
+func (c *GRPCBotClient) add(s *updateStream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.streams = append(c.streams, s)
+}
+
+func (c *GRPCBotClient) remove(s *updateStream) {
+	c.mu.Lock()
+
+	for i, stream := range c.streams {
+		if stream == s {
+			c.streams = append(c.streams[:i], c.streams[i+1:]...)
+
+			break
+		}
+	}
+
+	c.mu.Unlock()
+
+	s.sendMu.Lock()
+	close(s.done)
+	s.sendMu.Unlock()
+}
+
+func (s *updateStream) send(msg *protov1.StreamUpdatesResponse) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	select {
+	case <-s.done:
+		return e.ErrNotConnected
+	default:
+	}
+
+	if err := s.stream.Send(msg); err != nil {
+		return fmt.Errorf("%w: %w", e.ErrDoRequest, err)
+	}
+
+	return nil
+}
+
+func (s *updateStream) expect(id uint64) chan *protov1.StreamUpdatesRequest {
+	ack := make(chan *protov1.StreamUpdatesRequest, 1)
+
+	s.mu.Lock()
+	s.pending[id] = ack
+	s.mu.Unlock()
+
+	return ack
+}
+
+func (s *updateStream) forget(id uint64) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+// resolve passes the acknowledgement to the sender waiting for it. Late acknowledgements are dropped.
+func (s *updateStream) resolve(ack *protov1.StreamUpdatesRequest) {
+	s.mu.Lock()
+	waiting, ok := s.pending[ack.GetDeliveryId()]
+	delete(s.pending, ack.GetDeliveryId())
+	s.mu.Unlock()
+
+	if ok {
+		waiting <- ack
+	}
+}
+
+func ackError(ack *protov1.StreamUpdatesRequest) error {
+	switch ack.GetStatus() {
+	case protov1.UpdateStatus_UPDATE_STATUS_DELIVERED, protov1.UpdateStatus_UPDATE_STATUS_DUPLICATE:
+		return nil
+	case protov1.UpdateStatus_UPDATE_STATUS_INVALID:
+		return fmt.Errorf("%w: %s", e.ErrMalformedMsg, ack.GetError())
+	default:
+		return fmt.Errorf("%w: %s", e.ErrAPI, ack.GetError())
+	}
+}
+
+func toProtoUpdate(update bottypes.LinkUpdate) *protov1.LinkUpdate {
+	return &protov1.LinkUpdate{
+		Id:             update.ID,
+		Url:            update.URL,
+		Description:    update.Description,
+		TgChatIds:      update.TgChatIDs,
+		IdempotencyKey: update.IdempotencyKey,
+	}
+}
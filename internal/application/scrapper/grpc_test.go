@@ -0,0 +1,345 @@
+package scrapper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startGRPC serves the gRPC API of server in memory and returns its client.
+func startGRPC(t *testing.T, server *scrapper.Server) protov1.ScrapperServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	protov1.RegisterScrapperServiceServer(grpcServer, scrapper.NewGRPCServer(server))
+
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+
+		grpcServer.Stop()
+	})
+
+	return protov1.NewScrapperServiceClient(conn)
+}
+
+func reason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
+
+func TestGRPCServer(t *testing.T) {
+	client := startGRPC(t, scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}))
+	ctx := context.Background()
+
+	t.Run("links are filtered by tags", func(t *testing.T) {
+		response, err := client.ListLinks(ctx, &protov1.ListLinksRequest{ChatId: 1, Tags: []string{"work"}})
+		require.NoError(t, err)
+
+		require.Len(t, response.GetLinks(), 1)
+		assert.Equal(t, "https://github.com/a/b", response.GetLinks()[0].GetUrl())
+		assert.EqualValues(t, 1, response.GetSize())
+
+		response, err = client.ListLinks(ctx, &protov1.ListLinksRequest{ChatId: 1, Tags: []string{"home"}})
+		require.NoError(t, err)
+		assert.Empty(t, response.GetLinks())
+	})
+
+	t.Run("tags of a link are changed", func(t *testing.T) {
+		response, err := client.UpdateLink(ctx, &protov1.UpdateLinkRequest{
+			ChatId:  1,
+			Link:    "https://github.com/a/b",
+			AddTags: []string{"go"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"go"}, response.GetLink().GetTags())
+	})
+
+	t.Run("tags are listed and renamed", func(t *testing.T) {
+		tags, err := client.ListTags(ctx, &protov1.ListTagsRequest{ChatId: 1})
+		require.NoError(t, err)
+
+		require.Len(t, tags.GetTags(), 1)
+		assert.Equal(t, "work", tags.GetTags()[0].GetName())
+		assert.EqualValues(t, 1, tags.GetTags()[0].GetLinks())
+
+		renamed, err := client.RenameTag(ctx, &protov1.RenameTagRequest{ChatId: 1, Tag: "work", NewName: "job"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "job", renamed.GetTag().GetName())
+	})
+
+	t.Run("exported links are imported back", func(t *testing.T) {
+		exported, err := client.ExportLinks(ctx, &protov1.ExportLinksRequest{ChatId: 1, Format: protov1.LinkFileFormat_LINK_FILE_FORMAT_CSV})
+		require.NoError(t, err)
+
+		assert.Equal(t, "text/csv", exported.GetContentType())
+
+		report, err := client.ImportLinks(ctx, &protov1.ImportLinksRequest{
+			ChatId: 1,
+			Format: protov1.LinkFileFormat_LINK_FILE_FORMAT_CSV,
+			Data:   append(exported.GetData(), "https://github.com/a/b/pulls\n"...),
+			DryRun: true,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, report.GetRows(), 4)
+		assert.Equal(t, protov1.ImportStatus_IMPORT_STATUS_SKIPPED, report.GetRows()[3].GetStatus())
+		assert.EqualValues(t, 3, report.GetInvalid())
+		assert.True(t, report.GetDryRun())
+	})
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name: "chat is registered",
+			call: func() error {
+				_, err := client.RegisterChat(ctx, &protov1.RegisterChatRequest{ChatId: 2})
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "chat is registered twice",
+			call: func() error {
+				_, err := client.RegisterChat(ctx, &protov1.RegisterChatRequest{ChatId: 1})
+				return err
+			},
+			wantCode:   codes.AlreadyExists,
+			wantReason: scrappertypes.CodeChatAlreadyExists,
+		},
+		{
+			name: "invalid chat id",
+			call: func() error {
+				_, err := client.ListLinks(ctx, &protov1.ListLinksRequest{ChatId: 0})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name: "links of unknown chat",
+			call: func() error {
+				_, err := client.ListLinks(ctx, &protov1.ListLinksRequest{ChatId: 2})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: scrappertypes.CodeChatNotFound,
+		},
+		{
+			name: "link with wrong url",
+			call: func() error {
+				_, err := client.AddLink(ctx, &protov1.AddLinkRequest{ChatId: 1, Link: "not a link"})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: scrappertypes.CodeInvalidLink,
+		},
+		{
+			name: "unknown link is removed",
+			call: func() error {
+				_, err := client.RemoveLink(ctx, &protov1.RemoveLinkRequest{ChatId: 1, Link: "https://github.com/c/d"})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: scrappertypes.CodeLinkNotFound,
+		},
+		{
+			name: "unknown link is updated",
+			call: func() error {
+				_, err := client.UpdateLink(ctx, &protov1.UpdateLinkRequest{
+					ChatId:  1,
+					Link:    "https://github.com/c/d",
+					AddTags: []string{"go"},
+				})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: scrappertypes.CodeLinkNotFound,
+		},
+		{
+			name: "unknown tag is deleted",
+			call: func() error {
+				_, err := client.DeleteTag(ctx, &protov1.DeleteTagRequest{ChatId: 1, Tag: "home"})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: scrappertypes.CodeTagNotFound,
+		},
+		{
+			name: "tag is renamed to a used name",
+			call: func() error {
+				_, err := client.RenameTag(ctx, &protov1.RenameTagRequest{ChatId: 1, Tag: "work", NewName: "home"})
+				return err
+			},
+			wantCode:   codes.AlreadyExists,
+			wantReason: scrappertypes.CodeTagAlreadyExists,
+		},
+		{
+			name: "tag is merged into itself",
+			call: func() error {
+				_, err := client.MergeTags(ctx, &protov1.MergeTagsRequest{ChatId: 1, Tag: "work", Into: "work"})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name: "file of unknown format is imported",
+			call: func() error {
+				_, err := client.ImportLinks(ctx, &protov1.ImportLinksRequest{ChatId: 1, Data: []byte("[]")})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name: "updates are not delivered over gRPC",
+			call: func() error {
+				stream, err := client.StreamUpdates(ctx)
+				if err != nil {
+					return err
+				}
+
+				_, err = stream.Recv()
+
+				return err
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			assert.Equal(t, tt.wantCode, status.Code(err), "got error %v", err)
+			assert.Equal(t, tt.wantReason, reason(err))
+		})
+	}
+}
+
+func TestGRPCServer_InternalError(t *testing.T) {
+	server := scrapper.NewServer(refreshStorage{}, &scrapper.MockBotClient{})
+	server.Configure(&config.Config{Workers: 1})
+
+	client := startGRPC(t, server)
+
+	_, err := client.RefreshLinks(context.Background(), &protov1.RefreshLinksRequest{ChatId: 2})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "Internal Server Error", status.Convert(err).Message(), "storage errors must only be logged")
+}
+
+func TestGRPCBotClient(t *testing.T) {
+	updates := scrapper.NewGRPCBotClient(time.Second)
+
+	server := scrapper.NewServer(contractStorage{}, updates)
+	server.Updates = updates
+
+	client := startGRPC(t, server)
+
+	batch := []bottypes.LinkUpdate{
+		{ID: 1, URL: "https://github.com/a/b", TgChatIDs: []int64{1}, IdempotencyKey: "outbox-1"},
+		{ID: 2, URL: "https://github.com/a/b", IdempotencyKey: "outbox-2"},
+		{ID: 3, URL: "https://github.com/c/d", TgChatIDs: []int64{3}, IdempotencyKey: "outbox-3"},
+	}
+
+	for _, err := range updates.SendUpdates(context.Background(), batch) {
+		assert.ErrorIs(t, err, e.ErrNotConnected)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamUpdates(ctx)
+	require.NoError(t, err)
+
+	statuses := map[int64]protov1.UpdateStatus{
+		1: protov1.UpdateStatus_UPDATE_STATUS_DELIVERED,
+		2: protov1.UpdateStatus_UPDATE_STATUS_INVALID,
+		3: protov1.UpdateStatus_UPDATE_STATUS_FAILED,
+	}
+
+	// The bot acknowledges the updates in reverse order, acks are matched by delivery id.
+	go func() {
+		var received []*protov1.StreamUpdatesResponse
+
+		for range batch {
+			msg, errRecv := stream.Recv()
+			if errRecv != nil {
+				return
+			}
+
+			received = append(received, msg)
+		}
+
+		for i := len(received) - 1; i >= 0; i-- {
+			msg := received[i]
+
+			_ = stream.Send(&protov1.StreamUpdatesRequest{
+				DeliveryId: msg.GetDeliveryId(),
+				Status:     statuses[msg.GetUpdate().GetId()],
+				Error:      fmt.Sprintf("update %d", msg.GetUpdate().GetId()),
+			})
+		}
+	}()
+
+	require.Eventually(t, func() bool { return updates.Connected() == 1 }, time.Second, 10*time.Millisecond)
+
+	errs := updates.SendUpdates(context.Background(), batch)
+
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], e.ErrMalformedMsg)
+	assert.True(t, errors.Is(errs[2], e.ErrAPI) && !errors.Is(errs[2], e.ErrMalformedMsg), "got error %v", errs[2])
+
+	// Nobody acknowledges this one, it fails when the timeout runs out.
+	assert.ErrorIs(t, updates.SendUpdate(context.Background(), batch[0]), e.ErrDoRequest)
+
+	updates.Close()
+
+	// The update nobody acknowledged is still waiting on the stream.
+	for err == nil {
+		_, err = stream.Recv()
+	}
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	require.Eventually(t, func() bool { return updates.Connected() == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,146 @@
+package scrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/formatter"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// checkLink processes the link and keeps track of its health. A link that is gone
+// is disabled at once, other links after BrokenLinkThreshold failures in a row.
+func (s *Server) checkLink(ctx context.Context, link *scrappertypes.LinkResponse) bool {
+	ctx, span := tracer.Start(ctx, "link.check", trace.WithAttributes(
+		attribute.Int64("link.id", link.ID),
+		attribute.String("link.provider", providerName(link.URL))))
+	defer span.End()
+
+	hadUpdates, err := s.processLink(ctx, link)
+	linksChecked.WithLabelValues(providerName(link.URL), checkResult(hadUpdates, err)).Inc()
+	span.SetAttributes(attribute.String("link.result", checkResult(hadUpdates, err)))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if err == nil {
+		if errSave := s.Storage.SaveCheckSuccess(ctx, link.ID); errSave != nil {
+			slog.Error("Failed to save check result",
+				slog.Int64("link id", link.ID),
+				slog.String("error", errSave.Error()))
+		}
+
+		return hadUpdates
+	}
+
+	slog.Error("Failed to check link",
+		slog.String("url", link.URL),
+		slog.String("error", err.Error()))
+
+	// Rate limit of the provider and errors of the storage say nothing about the link itself.
+	if errors.Is(err, e.ErrRateLimited) || errors.Is(err, e.ErrWrite) {
+		return false
+	}
+
+	gone := errors.Is(err, e.ErrResourceNotFound) || errors.Is(err, e.ErrWrongURLFormat)
+
+	becameBroken, errSave := s.Storage.SaveCheckFailure(ctx, link.ID, err.Error(), gone, s.config.BrokenLinkThreshold)
+	if errSave != nil {
+		slog.Error("Failed to save check result",
+			slog.Int64("link id", link.ID),
+			slog.String("error", errSave.Error()))
+
+		return false
+	}
+
+	if becameBroken {
+		s.notifyBroken(ctx, link, err)
+	}
+
+	return false
+}
+
+func (s *Server) notifyBroken(ctx context.Context, link *scrappertypes.LinkResponse, cause error) {
+	slog.Warn("Link is broken, it won't be checked until enabled",
+		slog.String("url", link.URL),
+		slog.String("error", cause.Error()))
+
+	subscribers := s.Storage.GetSubscribers(ctx, link.ID)
+	if len(subscribers) == 0 {
+		return
+	}
+
+	chatIDs := make([]int64, 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		chatIDs = append(chatIDs, subscriber.TgChatID)
+	}
+
+	update := bottypes.LinkUpdate{
+		ID:          link.ID,
+		URL:         link.URL,
+		Description: formatter.FormatBrokenLinkMessage(link.URL, cause),
+		TgChatIDs:   chatIDs,
+	}
+
+	if err := s.Storage.AddNotifications(ctx, []bottypes.LinkUpdate{update}); err != nil {
+		slog.Error("Failed to queue notification",
+			slog.String("url", link.URL),
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	s.kickOutbox()
+}
+
+// EnableLink makes a broken link of the chat tracked again.
+func (s *Server) EnableLink(w http.ResponseWriter, r *http.Request, params scrapperapi.EnableLinkParams) {
+	ctx := r.Context()
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+
+		return
+	}
+
+	var request scrappertypes.EnableLinkRequest
+	if errDecode := json.NewDecoder(r.Body).Decode(&request); errDecode != nil {
+		sendError(w, invalidRequest(errDecode.Error()))
+
+		return
+	}
+
+	errEnable := withStoredLink(request.Link, func(link string) error {
+		return s.Storage.EnableLink(ctx, id, link)
+	})
+	if errEnable != nil {
+		sendError(w, errEnable)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func checkResult(hadUpdates bool, err error) string {
+	switch {
+	case err != nil:
+		return "failed"
+	case hadUpdates:
+		return "updated"
+	default:
+		return "unchanged"
+	}
+}
@@ -0,0 +1,103 @@
+package scrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/tracing"
+	"go-progira/pkg/e"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const kafkaWriteTimeout = 10 * time.Second
+
+// KafkaBotClient publishes link updates to a topic that the bot consumes.
+// Messages are keyed by link id, so updates of one link keep their order.
+type KafkaBotClient struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaBotClient(brokers []string, topic string) *KafkaBotClient {
+	return &KafkaBotClient{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (c *KafkaBotClient) SendUpdate(ctx context.Context, update bottypes.LinkUpdate) (err error) {
+	return c.SendUpdates(ctx, []bottypes.LinkUpdate{update})[0]
+}
+
+// SendUpdates publishes the updates in one write.
+func (c *KafkaBotClient) SendUpdates(ctx context.Context, updates []bottypes.LinkUpdate) []error {
+	errs := make([]error, len(updates))
+	messages := make([]kafka.Message, 0, len(updates))
+	indexes := make([]int, 0, len(updates))
+
+	for i, update := range updates {
+		jsonData, err := json.Marshal(update)
+		if err != nil {
+			slog.Error(
+				e.ErrMarshalJSON.Error(),
+				slog.String("error", err.Error()),
+			)
+
+			errs[i] = e.ErrMarshalJSON
+
+			continue
+		}
+
+		message := kafka.Message{
+			Key:   []byte(strconv.FormatInt(update.ID, 10)),
+			Value: jsonData,
+		}
+		tracing.InjectMessage(ctx, &message)
+
+		messages = append(messages, message)
+		indexes = append(indexes, i)
+	}
+
+	if len(messages) == 0 {
+		return errs
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kafkaWriteTimeout)
+	defer cancel()
+
+	err := c.writer.WriteMessages(ctx, messages...)
+	if err == nil {
+		return errs
+	}
+
+	slog.Error(
+		e.ErrPublish.Error(),
+		slog.String("error", err.Error()),
+		slog.String("topic", c.writer.Topic),
+	)
+
+	var writeErrs kafka.WriteErrors
+
+	for n, i := range indexes {
+		if errors.As(err, &writeErrs) && writeErrs[n] == nil {
+			continue
+		}
+
+		errs[i] = e.ErrPublish
+	}
+
+	return errs
+}
+
+func (c *KafkaBotClient) Close() error {
+	return c.writer.Close()
+}
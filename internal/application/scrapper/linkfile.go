@@ -0,0 +1,206 @@
+package scrapper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"go-progira/internal/domain/types/scrappertypes"
+	"strings"
+)
+
+// linkFile is a format the links of a chat are exported to and imported from.
+type linkFile struct {
+	contentType string
+	encode      func(links []scrappertypes.ExportedLink) ([]byte, error)
+	decode      func(data []byte) ([]fileRow, error)
+}
+
+var linkFiles = map[string]linkFile{
+	scrappertypes.FormatJSON: {"application/json", encodeJSONLinks, decodeJSONLinks},
+	scrappertypes.FormatCSV:  {"text/csv", encodeCSVLinks, decodeCSVLinks},
+	scrappertypes.FormatOPML: {"text/x-opml", encodeOPMLLinks, decodeOPMLLinks},
+}
+
+// fileRow is the link of a row of an imported file, reason tells why the row can't be read.
+type fileRow struct {
+	link   scrappertypes.ExportedLink
+	reason string
+}
+
+var csvHeader = []string{"url", "tags", "filters"}
+
+func encodeJSONLinks(links []scrappertypes.ExportedLink) ([]byte, error) {
+	return json.MarshalIndent(links, "", "  ")
+}
+
+// decodeJSONLinks reads an array of links, an element that is not a link is an invalid row.
+func decodeJSONLinks(data []byte) ([]fileRow, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+
+	rows := make([]fileRow, 0, len(elements))
+
+	for _, element := range elements {
+		var row fileRow
+		if err := json.Unmarshal(element, &row.link); err != nil {
+			row.reason = "row is not a link"
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// encodeCSVLinks writes a row of url, tags and filters for every link. Tags and filters are separated by spaces,
+// like the bot reads them.
+func encodeCSVLinks(links []scrappertypes.ExportedLink) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		err := writer.Write([]string{link.URL, strings.Join(link.Tags, " "), strings.Join(link.Filters, " ")})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// decodeCSVLinks reads the rows encodeCSVLinks writes, the header and the tags and filters are optional.
+func decodeCSVLinks(data []byte) ([]fileRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) != 0 && strings.EqualFold(records[0][0], csvHeader[0]) {
+		records = records[1:]
+	}
+
+	rows := make([]fileRow, 0, len(records))
+
+	for _, record := range records {
+		if len(record) > len(csvHeader) {
+			rows = append(rows, fileRow{link: scrappertypes.ExportedLink{URL: record[0]}, reason: "row has more than 3 columns"})
+
+			continue
+		}
+
+		record = append(record, make([]string, len(csvHeader)-len(record))...)
+
+		rows = append(rows, fileRow{link: scrappertypes.ExportedLink{
+			URL:     record[0],
+			Tags:    strings.Fields(record[1]),
+			Filters: strings.Fields(record[2]),
+		}})
+	}
+
+	return rows, nil
+}
+
+type opmlFile struct {
+	XMLName  xml.Name      `xml:"opml"`
+	Version  string        `xml:"version,attr"`
+	Title    string        `xml:"head>title"`
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline keeps tags in the category attribute and filters in a filters attribute, both separated by commas.
+// Outlines of feed readers have xmlUrl or htmlUrl instead of url, folders have neither of them.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	URL      string        `xml:"url,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Filters  string        `xml:"filters,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline,omitempty"`
+}
+
+func encodeOPMLLinks(links []scrappertypes.ExportedLink) ([]byte, error) {
+	file := opmlFile{Version: "2.0", Title: "Tracked links"}
+
+	for _, link := range links {
+		file.Outlines = append(file.Outlines, opmlOutline{
+			Text:     link.URL,
+			Type:     "link",
+			URL:      link.URL,
+			Category: strings.Join(link.Tags, ","),
+			Filters:  strings.Join(link.Filters, ","),
+		})
+	}
+
+	data, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// decodeOPMLLinks reads the outlines with links, the ones nested in folders too.
+func decodeOPMLLinks(data []byte) ([]fileRow, error) {
+	var file opmlFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.XMLName.Local != "opml" {
+		return nil, errors.New("file is not OPML")
+	}
+
+	return appendOutlines(nil, file.Outlines), nil
+}
+
+func appendOutlines(rows []fileRow, outlines []opmlOutline) []fileRow {
+	for _, outline := range outlines {
+		url := outline.URL
+		for _, alternative := range []string{outline.HTMLURL, outline.XMLURL} {
+			if url == "" {
+				url = alternative
+			}
+		}
+
+		if url != "" {
+			rows = append(rows, fileRow{link: scrappertypes.ExportedLink{
+				URL:     url,
+				Tags:    splitList(outline.Category),
+				Filters: splitList(outline.Filters),
+			}})
+		}
+
+		rows = appendOutlines(rows, outline.Outlines)
+	}
+
+	return rows
+}
+
+func splitList(list string) []string {
+	var items []string
+
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,81 @@
+package scrapper
+
+import (
+	"errors"
+	"go-progira/internal/application/scrapper/api"
+	"go-progira/pkg/e"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	linksChecked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scrapper_links_checked_total",
+		Help: "Link checks, by provider and result: updated, unchanged or failed.",
+	}, []string{"provider", "result"})
+
+	updatesFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scrapper_updates_found_total",
+		Help: "New events found on the links, by provider.",
+	}, []string{"provider"})
+
+	providerLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scrapper_provider_request_duration_seconds",
+		Help:    "Time spent getting the updates of a link from its provider API.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	providerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scrapper_provider_errors_total",
+		Help: "Errors returned by provider APIs, by provider and reason.",
+	}, []string{"provider", "reason"})
+
+	schedulerRunDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scrapper_scheduler_run_duration_seconds",
+		Help:    "Duration of the monitoring runs started by the scheduler.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 14),
+	})
+
+	claimedBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scrapper_claimed_batch_size",
+		Help:    "Number of links claimed for checking at once.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 11),
+	})
+
+	deliveredBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scrapper_delivered_batch_size",
+		Help:    "Number of updates sent to the bot at once.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 11),
+	})
+
+	notificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scrapper_notifications_total",
+		Help: "Outbox notifications sent to the bot, by result: delivered, retried or dead.",
+	}, []string{"result"})
+)
+
+// providerName labels a link with the host of its provider, links no provider supports share one label.
+func providerName(link string) string {
+	if _, ok := api.GetUpdater(link); !ok {
+		return "unknown"
+	}
+
+	return linkHost(link)
+}
+
+// errorReason turns a provider error into a label with a small set of values.
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, e.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, e.ErrResourceNotFound):
+		return "not_found"
+	case errors.Is(err, e.ErrResourcePrivate):
+		return "private"
+	case errors.Is(err, e.ErrWrongURLFormat):
+		return "wrong_url"
+	default:
+		return "other"
+	}
+}
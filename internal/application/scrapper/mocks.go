@@ -1,6 +1,7 @@
 package scrapper
 
 import (
+	"context"
 	"go-progira/internal/domain/types/bottypes"
 
 	"github.com/stretchr/testify/mock"
@@ -10,7 +11,7 @@ type MockBotClient struct {
 	mock.Mock
 }
 
-func (m *MockBotClient) SendUpdate(update bottypes.LinkUpdate) (err error) {
+func (m *MockBotClient) SendUpdate(_ context.Context, update bottypes.LinkUpdate) (err error) {
 	args := m.Called(update)
 
 	return args.Error(0)
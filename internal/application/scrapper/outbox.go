@@ -0,0 +1,163 @@
+package scrapper
+
+import (
+	"context"
+	"errors"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	outboxBatch = 100
+	outboxLease = time.Minute
+)
+
+// RetryPolicy decides when a failed notification is sent again.
+type RetryPolicy struct {
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	MaxAttempts int
+}
+
+func NewRetryPolicy(config *config.Config) RetryPolicy {
+	return RetryPolicy{
+		BaseBackoff: config.OutboxBaseBackoff,
+		MaxBackoff:  config.OutboxMaxBackoff,
+		MaxAttempts: config.OutboxMaxAttempts,
+	}
+}
+
+// Backoff returns the delay after the given number of failed attempts, doubling it each time.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := p.BaseBackoff
+
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, p.MaxBackoff)
+}
+
+// Dead reports whether the notification should not be sent anymore after the given number of failed attempts.
+func (p RetryPolicy) Dead(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+// runOutbox delivers notifications every interval and right after new ones are queued.
+// On shutdown it makes one more pass to flush what is left. The pass has its own ShutdownTimeout,
+// since the checks may have used up the time of the shutdown and got canceled.
+func (s *Server) runOutbox(interval time.Duration) {
+	defer close(s.outboxDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.outboxStop:
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+			defer cancel()
+
+			s.DeliverNotifications(ctx)
+
+			return
+		case <-ticker.C:
+		case <-s.outboxKick:
+		}
+
+		s.DeliverNotifications(s.checksCtx)
+	}
+}
+
+// kickOutbox wakes up the delivery without waiting for the next tick.
+func (s *Server) kickOutbox() {
+	select {
+	case s.outboxKick <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverNotifications sends due notifications in batches. Every notification carries its outbox id
+// as the idempotency key, so a retry after a lost response does not message the chats again.
+func (s *Server) DeliverNotifications(ctx context.Context) {
+	for ctx.Err() == nil {
+		notifications := s.Storage.ClaimNotifications(ctx, outboxBatch, outboxLease)
+		if len(notifications) == 0 {
+			return
+		}
+
+		for len(notifications) > 0 {
+			n := min(max(s.BotBatch, 1), len(notifications))
+
+			s.deliverBatch(ctx, notifications[:n])
+			notifications = notifications[n:]
+		}
+	}
+}
+
+func (s *Server) deliverBatch(ctx context.Context, notifications []scrappertypes.Notification) {
+	ctx, span := tracer.Start(ctx, "outbox.deliver", trace.WithAttributes(
+		attribute.Int("outbox.batch_size", len(notifications))))
+	defer span.End()
+
+	updates := make([]bottypes.LinkUpdate, len(notifications))
+
+	for i, notification := range notifications {
+		updates[i] = notification.Update
+		updates[i].IdempotencyKey = "outbox-" + strconv.FormatInt(notification.ID, 10)
+	}
+
+	deliveredBatchSize.Observe(float64(len(updates)))
+
+	errs := sendUpdates(ctx, s.BotClient, updates)
+
+	for i, notification := range notifications {
+		if errs[i] == nil {
+			if err := s.Storage.MarkNotificationDelivered(ctx, notification.ID); err != nil {
+				slog.Error("Failed to mark notification as delivered",
+					slog.Int64("notification id", notification.ID),
+					slog.String("error", err.Error()))
+			}
+
+			notificationsSent.WithLabelValues("delivered").Inc()
+
+			continue
+		}
+
+		s.deliveryFailed(ctx, notification, errs[i])
+	}
+}
+
+func (s *Server) deliveryFailed(ctx context.Context, notification scrappertypes.Notification, errSend error) {
+	attempts := notification.Attempts + 1
+	dead := s.Retry.Dead(attempts) || errors.Is(errSend, e.ErrMalformedMsg)
+
+	if dead {
+		notificationsSent.WithLabelValues("dead").Inc()
+		slog.Error("Notification was not delivered, giving up",
+			slog.Int64("notification id", notification.ID),
+			slog.Int("attempts", attempts),
+			slog.String("error", errSend.Error()))
+	} else {
+		notificationsSent.WithLabelValues("retried").Inc()
+		slog.Warn("Notification was not delivered, will retry",
+			slog.Int64("notification id", notification.ID),
+			slog.Int("attempts", attempts),
+			slog.String("error", errSend.Error()))
+	}
+
+	err := s.Storage.MarkNotificationFailed(ctx, notification.ID, errSend.Error(), s.Retry.Backoff(attempts), dead)
+	if err != nil {
+		slog.Error("Failed to save delivery failure",
+			slog.Int64("notification id", notification.ID),
+			slog.String("error", err.Error()))
+	}
+}
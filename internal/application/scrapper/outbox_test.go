@@ -0,0 +1,115 @@
+package scrapper_test
+
+import (
+	"context"
+	"errors"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := scrapper.RetryPolicy{
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+		MaxAttempts: 3,
+	}
+
+	type TestCase struct {
+		attempts        int
+		expectedBackoff time.Duration
+		expectedDead    bool
+	}
+
+	testCases := []TestCase{
+		{attempts: 1, expectedBackoff: time.Second},
+		{attempts: 2, expectedBackoff: 2 * time.Second},
+		{attempts: 3, expectedBackoff: 4 * time.Second, expectedDead: true},
+		{attempts: 10, expectedBackoff: time.Minute, expectedDead: true},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedBackoff, policy.Backoff(testCase.attempts), "backoff after %d attempts",
+			testCase.attempts)
+		assert.Equal(t, testCase.expectedDead, policy.Dead(testCase.attempts), "dead after %d attempts",
+			testCase.attempts)
+	}
+}
+
+// outboxStorage hands out the notifications once and records what happened to each of them.
+type outboxStorage struct {
+	contractStorage
+	notifications []scrappertypes.Notification
+	results       map[int64]string
+	backoffs      map[int64]time.Duration
+}
+
+func (s *outboxStorage) ClaimNotifications(_ context.Context, _ int, _ time.Duration) []scrappertypes.Notification {
+	notifications := s.notifications
+	s.notifications = nil
+
+	return notifications
+}
+
+func (s *outboxStorage) MarkNotificationDelivered(_ context.Context, id int64) error {
+	s.results[id] = "delivered"
+
+	return nil
+}
+
+func (s *outboxStorage) MarkNotificationFailed(_ context.Context, id int64, _ string, backoff time.Duration, dead bool) error {
+	s.results[id] = "retried"
+	if dead {
+		s.results[id] = "dead"
+	}
+
+	s.backoffs[id] = backoff
+
+	return nil
+}
+
+func TestServer_DeliverNotifications(t *testing.T) {
+	storage := &outboxStorage{
+		notifications: []scrappertypes.Notification{
+			{ID: 1, Update: bottypes.LinkUpdate{ID: 1, URL: "https://github.com/a/b"}},
+			{ID: 2, Update: bottypes.LinkUpdate{ID: 2, URL: "https://github.com/a/b"}, Attempts: 1},
+			{ID: 3, Update: bottypes.LinkUpdate{ID: 3, URL: "https://github.com/a/b"}},
+			{ID: 4, Update: bottypes.LinkUpdate{ID: 4, URL: "https://github.com/a/b"}, Attempts: 2},
+		},
+		results:  map[int64]string{},
+		backoffs: map[int64]time.Duration{},
+	}
+
+	client := &scrapper.MockBotClient{}
+	client.On("SendUpdate", mock.MatchedBy(func(update bottypes.LinkUpdate) bool {
+		return update.IdempotencyKey == "outbox-1"
+	})).Return(nil)
+	client.On("SendUpdate", mock.MatchedBy(func(update bottypes.LinkUpdate) bool {
+		return update.IdempotencyKey == "outbox-2" || update.IdempotencyKey == "outbox-4"
+	})).Return(errors.New("bot is down"))
+	client.On("SendUpdate", mock.MatchedBy(func(update bottypes.LinkUpdate) bool {
+		return update.IdempotencyKey == "outbox-3"
+	})).Return(e.ErrMalformedMsg)
+
+	server := scrapper.NewServer(storage, client)
+	server.Configure(&config.Config{
+		OutboxBaseBackoff: time.Second,
+		OutboxMaxBackoff:  time.Minute,
+		OutboxMaxAttempts: 3,
+		BotBatchSize:      2,
+	})
+
+	server.DeliverNotifications(context.Background())
+
+	assert.Equal(t, map[int64]string{1: "delivered", 2: "retried", 3: "dead", 4: "dead"}, storage.results,
+		"malformed notifications and the ones out of attempts must not be retried")
+	assert.Equal(t, 2*time.Second, storage.backoffs[2])
+	client.AssertNumberOfCalls(t, "SendUpdate", 4)
+}
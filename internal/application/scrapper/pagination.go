@@ -0,0 +1,91 @@
+package scrapper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go-progira/internal/domain/types/scrappertypes"
+)
+
+const maxPageSize = 100
+
+// linksQuery checks the request for a page of links and decodes its cursor.
+// Sort and order default to the ones of the cursor, a cursor can't be used with others.
+func linksQuery(request scrappertypes.GetLinksRequest) (scrappertypes.LinksQuery, error) {
+	if request.Limit < 0 || request.Limit > maxPageSize {
+		return scrappertypes.LinksQuery{}, invalidRequest("limit is out of range")
+	}
+
+	query := scrappertypes.LinksQuery{Sort: scrappertypes.SortAdded, Limit: request.Limit}
+
+	if request.Cursor != "" {
+		cursor, err := decodeCursor(request.Cursor)
+		if err != nil || request.Limit == 0 {
+			return scrappertypes.LinksQuery{}, invalidRequest("cursor is malformed or has no limit")
+		}
+
+		query.Sort, query.Desc, query.Cursor = cursor.Sort, cursor.Desc, &cursor
+	}
+
+	switch request.Sort {
+	case "":
+	case scrappertypes.SortAdded, scrappertypes.SortActivity, scrappertypes.SortURL, scrappertypes.SortProvider:
+		if query.Cursor != nil && query.Sort != request.Sort {
+			return scrappertypes.LinksQuery{}, invalidRequest("cursor was made for another sort")
+		}
+
+		query.Sort = request.Sort
+	default:
+		return scrappertypes.LinksQuery{}, invalidRequest("unknown sort " + request.Sort)
+	}
+
+	switch request.Order {
+	case "":
+	case scrappertypes.OrderAsc, scrappertypes.OrderDesc:
+		desc := request.Order == scrappertypes.OrderDesc
+		if query.Cursor != nil && query.Desc != desc {
+			return scrappertypes.LinksQuery{}, invalidRequest("cursor was made for another order")
+		}
+
+		query.Desc = desc
+	default:
+		return scrappertypes.LinksQuery{}, invalidRequest("unknown order " + request.Order)
+	}
+
+	return query, nil
+}
+
+// listResponse answers with the page and the opaque cursors of its neighbours.
+func listResponse(page scrappertypes.LinksPage) scrappertypes.ListLinksResponse {
+	return scrappertypes.ListLinksResponse{
+		Links:      page.Links,
+		Size:       len(page.Links),
+		NextCursor: encodeCursor(page.Next),
+		PrevCursor: encodeCursor(page.Prev),
+	}
+}
+
+func encodeCursor(cursor *scrappertypes.LinksCursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (scrappertypes.LinksCursor, error) {
+	var cursor scrappertypes.LinksCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(data, &cursor)
+
+	return cursor, err
+}
@@ -0,0 +1,52 @@
+package scrapper
+
+import (
+	"go-progira/pkg/config"
+	"time"
+)
+
+// PollingPolicy decides how often a link is checked. Links with frequent
+// updates are checked more often, dormant links back off up to Max.
+type PollingPolicy struct {
+	Min     time.Duration
+	Max     time.Duration
+	Default time.Duration
+}
+
+func NewPollingPolicy(config *config.Config) PollingPolicy {
+	return PollingPolicy{
+		Min:     config.MinCheckInterval,
+		Max:     config.MaxCheckInterval,
+		Default: config.CheckInterval,
+	}
+}
+
+// NextInterval returns the interval until the next check of a link. An interval
+// set by subscribers takes precedence over the adaptive one.
+func (p PollingPolicy) NextInterval(current, userInterval time.Duration, hadUpdates bool) time.Duration {
+	if userInterval > 0 {
+		return p.clamp(userInterval)
+	}
+
+	if current <= 0 {
+		return p.clamp(p.Default)
+	}
+
+	if hadUpdates {
+		return p.clamp(current / 2)
+	}
+
+	return p.clamp(current * 2)
+}
+
+func (p PollingPolicy) clamp(interval time.Duration) time.Duration {
+	if interval < p.Min {
+		return p.Min
+	}
+
+	if p.Max > 0 && interval > p.Max {
+		return p.Max
+	}
+
+	return interval
+}
@@ -0,0 +1,74 @@
+package scrapper_test
+
+import (
+	"go-progira/internal/application/scrapper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingPolicy_NextInterval(t *testing.T) {
+	policy := scrapper.PollingPolicy{
+		Min:     time.Minute,
+		Max:     time.Hour,
+		Default: 2 * time.Minute,
+	}
+
+	type TestCase struct {
+		name         string
+		current      time.Duration
+		userInterval time.Duration
+		hadUpdates   bool
+		expected     time.Duration
+	}
+
+	testCases := []TestCase{
+		{
+			name:     "link without interval gets default",
+			expected: 2 * time.Minute,
+		},
+		{
+			name:       "active link is checked more often",
+			current:    10 * time.Minute,
+			hadUpdates: true,
+			expected:   5 * time.Minute,
+		},
+		{
+			name:     "dormant link backs off",
+			current:  10 * time.Minute,
+			expected: 20 * time.Minute,
+		},
+		{
+			name:       "interval does not go below minimum",
+			current:    time.Minute,
+			hadUpdates: true,
+			expected:   time.Minute,
+		},
+		{
+			name:     "interval does not go above maximum",
+			current:  45 * time.Minute,
+			expected: time.Hour,
+		},
+		{
+			name:         "interval set by user takes precedence",
+			current:      45 * time.Minute,
+			userInterval: 10 * time.Minute,
+			hadUpdates:   true,
+			expected:     10 * time.Minute,
+		},
+		{
+			name:         "interval set by user is not shorter than minimum",
+			userInterval: time.Second,
+			expected:     time.Minute,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := policy.NextInterval(testCase.current, testCase.userInterval, testCase.hadUpdates)
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package scrapper
+
+import (
+	"go-progira/internal/domain/types/scrappertypes"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WorkerPool checks links with at most workers checks at a time in total
+// and at most hostLimits[host] checks at a time against one host.
+// Links of every host go to their own queue, so a slow host does not hold back the others.
+// Submit, WaitFree and Wait must be called from one goroutine.
+type WorkerPool struct {
+	workers    int
+	hostLimits map[string]int
+	process    func(link scrappertypes.LinkResponse)
+
+	slots  chan struct{}
+	mu     sync.Mutex
+	queues map[string]*hostQueue
+	wg     sync.WaitGroup
+
+	// pending counts the links submitted and not processed yet, freed is signaled when one is processed.
+	pending int
+	freed   *sync.Cond
+}
+
+// hostQueue holds the links of one host that wait for a worker.
+type hostQueue struct {
+	links   []scrappertypes.LinkResponse
+	running int
+	limit   int
+}
+
+func NewWorkerPool(workers int, hostLimits map[string]int, process func(link scrappertypes.LinkResponse)) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	pool := &WorkerPool{
+		workers:    workers,
+		hostLimits: hostLimits,
+		process:    process,
+		slots:      make(chan struct{}, workers),
+		queues:     make(map[string]*hostQueue),
+	}
+	pool.freed = sync.NewCond(&pool.mu)
+
+	return pool
+}
+
+// Submit queues the link. It never blocks, so a full queue of one host does not hold back the links of the others.
+func (p *WorkerPool) Submit(link scrappertypes.LinkResponse) {
+	host := linkHost(link.URL)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queue, ok := p.queues[host]
+	if !ok {
+		queue = &hostQueue{limit: p.hostWorkers(host)}
+		p.queues[host] = queue
+	}
+
+	queue.links = append(queue.links, link)
+	p.pending++
+
+	if queue.running < queue.limit {
+		queue.running++
+		p.wg.Add(1)
+
+		go p.work(queue)
+	}
+}
+
+// WaitFree blocks until fewer links than workers are submitted and not processed yet
+// and returns how many more links can be submitted without making them wait.
+func (p *WorkerPool) WaitFree() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.pending >= p.workers {
+		p.freed.Wait()
+	}
+
+	return p.workers - p.pending
+}
+
+// Wait blocks until all submitted links are processed. The pool cannot be used afterwards.
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) work(queue *hostQueue) {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+
+		if len(queue.links) == 0 {
+			queue.running--
+			p.mu.Unlock()
+
+			return
+		}
+
+		link := queue.links[0]
+		queue.links = queue.links[1:]
+
+		p.mu.Unlock()
+
+		p.slots <- struct{}{}
+		p.process(link)
+		<-p.slots
+
+		p.mu.Lock()
+		p.pending--
+		p.freed.Signal()
+		p.mu.Unlock()
+	}
+}
+
+func (p *WorkerPool) hostWorkers(host string) int {
+	limit, ok := p.hostLimits[host]
+	if !ok || limit <= 0 || limit > p.workers {
+		return p.workers
+	}
+
+	return limit
+}
+
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
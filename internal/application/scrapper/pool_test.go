@@ -0,0 +1,175 @@
+package scrapper_test
+
+import (
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/scrappertypes"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type concurrencyCounter struct {
+	mu      sync.Mutex
+	current map[string]int
+	max     map[string]int
+}
+
+func (c *concurrencyCounter) change(key string, delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current[key] += delta
+	if c.current[key] > c.max[key] {
+		c.max[key] = c.current[key]
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	type TestCase struct {
+		name        string
+		workers     int
+		hostLimits  map[string]int
+		links       []string
+		expectedMax map[string]int
+	}
+
+	var many []string
+
+	for i := range 20 {
+		many = append(many, "https://github.com/a/"+strconv.Itoa(i)+"/pulls")
+		many = append(many, "https://stackoverflow.com/questions/"+strconv.Itoa(i+1)+"/answers")
+	}
+
+	testCases := []TestCase{
+		{
+			name:        "fewer links than workers",
+			workers:     8,
+			links:       []string{"https://github.com/a/b/pulls"},
+			expectedMax: map[string]int{"all": 1},
+		},
+		{
+			name:        "global limit",
+			workers:     3,
+			links:       many,
+			expectedMax: map[string]int{"all": 3},
+		},
+		{
+			name:        "per-host limit",
+			workers:     4,
+			hostLimits:  map[string]int{"github.com": 1},
+			links:       many,
+			expectedMax: map[string]int{"all": 4, "github.com": 1, "stackoverflow.com": 4},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			counter := concurrencyCounter{current: map[string]int{}, max: map[string]int{}}
+
+			var processed sync.Map
+
+			pool := scrapper.NewWorkerPool(testCase.workers, testCase.hostLimits, func(link scrappertypes.LinkResponse) {
+				host := "github.com"
+				if link.ID%2 == 1 {
+					host = "stackoverflow.com"
+				}
+
+				counter.change("all", 1)
+				counter.change(host, 1)
+				time.Sleep(5 * time.Millisecond)
+				counter.change(host, -1)
+				counter.change("all", -1)
+
+				processed.Store(link.ID, true)
+			})
+
+			for i, link := range testCase.links {
+				pool.Submit(scrappertypes.LinkResponse{ID: int64(i), URL: link})
+			}
+
+			pool.Wait()
+
+			for i := range testCase.links {
+				_, ok := processed.Load(int64(i))
+				assert.True(t, ok, "link %d was not processed", i)
+			}
+
+			for key, expected := range testCase.expectedMax {
+				assert.LessOrEqual(t, counter.max[key], expected, "too many concurrent checks for %s", key)
+			}
+		})
+	}
+}
+
+func TestWorkerPool_SubmitDoesNotWaitForBusyHost(t *testing.T) {
+	release := make(chan struct{})
+	checked := make(chan string, 1)
+
+	pool := scrapper.NewWorkerPool(2, map[string]int{"github.com": 1}, func(link scrappertypes.LinkResponse) {
+		if link.URL == "https://stackoverflow.com/questions/1/answers" {
+			checked <- link.URL
+
+			return
+		}
+
+		<-release
+	})
+
+	for i := range 10 {
+		pool.Submit(scrappertypes.LinkResponse{ID: int64(i), URL: "https://github.com/a/" + strconv.Itoa(i) + "/pulls"})
+	}
+
+	pool.Submit(scrappertypes.LinkResponse{ID: 10, URL: "https://stackoverflow.com/questions/1/answers"})
+
+	select {
+	case link := <-checked:
+		assert.Equal(t, "https://stackoverflow.com/questions/1/answers", link)
+	case <-time.After(time.Second):
+		t.Error("link of another host waits for the busy host")
+	}
+
+	close(release)
+	pool.Wait()
+}
+
+func TestWorkerPool_WaitFree(t *testing.T) {
+	release := make(chan struct{})
+
+	pool := scrapper.NewWorkerPool(2, nil, func(scrappertypes.LinkResponse) {
+		<-release
+	})
+
+	assert.Equal(t, 2, pool.WaitFree())
+
+	pool.Submit(scrappertypes.LinkResponse{ID: 1, URL: "https://github.com/a/b/pulls"})
+	assert.Equal(t, 1, pool.WaitFree())
+
+	pool.Submit(scrappertypes.LinkResponse{ID: 2, URL: "https://github.com/a/c/pulls"})
+
+	free := make(chan int, 1)
+
+	go func() {
+		free <- pool.WaitFree()
+	}()
+
+	select {
+	case <-free:
+		t.Fatal("pool has free workers while all of them are busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release <- struct{}{}
+
+	select {
+	case got := <-free:
+		assert.Equal(t, 1, got)
+	case <-time.After(time.Second):
+		t.Error("pool has no free workers after a link was processed")
+	}
+
+	close(release)
+	pool.Wait()
+}
@@ -0,0 +1,217 @@
+package scrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RefreshLimiter allows a chat to refresh its links at most once per cooldown.
+type RefreshLimiter struct {
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	last map[int64]time.Time
+}
+
+func NewRefreshLimiter(cooldown time.Duration) *RefreshLimiter {
+	return &RefreshLimiter{
+		cooldown: cooldown,
+		last:     make(map[int64]time.Time),
+	}
+}
+
+// Allow reports whether the chat may refresh now, otherwise it returns how long to wait.
+func (l *RefreshLimiter) Allow(chatID int64, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.last[chatID]; ok && now.Sub(last) < l.cooldown {
+		return l.cooldown - now.Sub(last), false
+	}
+
+	l.last[chatID] = now
+
+	return 0, true
+}
+
+func (s *Server) RefreshLinks(w http.ResponseWriter, r *http.Request, params scrapperapi.RefreshLinksParams) {
+	ctx := r.Context()
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+
+		return
+	}
+
+	var request scrappertypes.RefreshRequest
+	if errDecode := json.NewDecoder(r.Body).Decode(&request); errDecode != nil && !errors.Is(errDecode, io.EOF) {
+		sendError(w, invalidRequest(errDecode.Error()))
+
+		return
+	}
+
+	response, retryAfter, err := s.refreshChat(ctx, id, request.Link)
+	if err != nil {
+		if errors.Is(err, e.ErrRefreshTooOften) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		}
+
+		sendError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if errEncode := json.NewEncoder(w).Encode(response); errEncode != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", errEncode.Error()),
+		)
+	}
+}
+
+// refreshChat checks the link of the chat, or all its links if link is empty.
+// If the chat refreshed its links recently, it returns e.ErrRefreshTooOften and how long to wait.
+func (s *Server) refreshChat(ctx context.Context, id int64, link string) (scrappertypes.RefreshResponse, time.Duration, error) {
+	page, errGet := s.Storage.GetLinks(ctx, id, scrappertypes.LinksQuery{})
+	if errGet != nil {
+		slog.Error("Error getting link",
+			slog.String("error", errGet.Error()))
+
+		return scrappertypes.RefreshResponse{}, 0, errGet
+	}
+
+	links := page.Links
+
+	if link != "" {
+		links = linksWithURL(links, link)
+		if len(links) == 0 {
+			return scrappertypes.RefreshResponse{}, 0, e.ErrLinkNotFound
+		}
+	}
+
+	if retryAfter, ok := s.refreshLimiter.Allow(id, time.Now()); !ok {
+		return scrappertypes.RefreshResponse{}, retryAfter, e.ErrRefreshTooOften
+	}
+
+	// Checks outlive the request, so they are not canceled with it, but stay in its trace.
+	checksCtx := trace.ContextWithSpan(s.checksCtx, trace.SpanFromContext(ctx))
+
+	return s.refresh(checksCtx, links), 0, nil
+}
+
+// refresh checks the links right away. Links that are being checked by the scheduler are skipped.
+// It waits for the checks at most RefreshTimeout, the links that are still being checked then are
+// reported in progress and their updates are sent when the checks finish.
+func (s *Server) refresh(ctx context.Context, links []scrappertypes.LinkResponse) scrappertypes.RefreshResponse {
+	var (
+		mu       sync.Mutex
+		finished int
+	)
+
+	response := scrappertypes.RefreshResponse{Updated: []string{}}
+
+	active := make([]scrappertypes.LinkResponse, 0, len(links))
+
+	for _, link := range links {
+		if link.Broken {
+			response.Broken = append(response.Broken, link.URL)
+		} else {
+			active = append(active, link)
+		}
+	}
+
+	pool := NewWorkerPool(s.config.Workers, s.config.HostConcurrency, func(link scrappertypes.LinkResponse) {
+		claimed, ok := s.Storage.ClaimLink(ctx, s.InstanceID, link.ID, s.config.LinkLease)
+		if !ok {
+			mu.Lock()
+			response.InProgress++
+			finished++
+			mu.Unlock()
+
+			return
+		}
+
+		hadUpdates := s.checkLink(ctx, &claimed)
+		s.releaseLink(ctx, &claimed, hadUpdates)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		response.Checked++
+		finished++
+
+		if hadUpdates {
+			response.Updated = append(response.Updated, claimed.URL)
+		}
+	})
+
+	for _, link := range active {
+		pool.Submit(link)
+	}
+
+	s.waitRefresh(pool)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	result := response
+	result.Updated = append([]string{}, response.Updated...)
+	result.InProgress += len(active) - finished
+
+	return result
+}
+
+// waitRefresh waits until the pool checks its links, but at most RefreshTimeout. The checks go on after that.
+func (s *Server) waitRefresh(pool *WorkerPool) {
+	done := make(chan struct{})
+
+	go func() {
+		pool.Wait()
+		s.kickOutbox()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if s.config.RefreshTimeout > 0 {
+		timeout = time.After(s.config.RefreshTimeout)
+	}
+
+	select {
+	case <-done:
+	case <-timeout:
+	}
+}
+
+func linksWithURL(links []scrappertypes.LinkResponse, link string) []scrappertypes.LinkResponse {
+	var found []scrappertypes.LinkResponse
+
+	// A link saved before links were canonicalized is stored in the form it was sent in.
+	_ = withStoredLink(link, func(stored string) error {
+		for _, l := range links {
+			if l.URL == stored {
+				found = []scrappertypes.LinkResponse{l}
+
+				return nil
+			}
+		}
+
+		return e.ErrLinkNotFound
+	})
+
+	return found
+}
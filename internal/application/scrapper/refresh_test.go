@@ -0,0 +1,98 @@
+package scrapper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshLimiter_Allow(t *testing.T) {
+	limiter := scrapper.NewRefreshLimiter(time.Minute)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, ok := limiter.Allow(1, now)
+	assert.True(t, ok, "first refresh must be allowed")
+
+	retryAfter, ok := limiter.Allow(1, now.Add(20*time.Second))
+	assert.False(t, ok, "refresh within cooldown must be rejected")
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	_, ok = limiter.Allow(2, now.Add(20*time.Second))
+	assert.True(t, ok, "other chats are not limited")
+
+	_, ok = limiter.Allow(1, now.Add(time.Minute))
+	assert.True(t, ok, "refresh after cooldown must be allowed")
+}
+
+// refreshStorage keeps the first link busy with another check and holds the claims of the others until release is closed.
+type refreshStorage struct {
+	contractStorage
+	release chan struct{}
+}
+
+func (s refreshStorage) GetLinks(ctx context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error) {
+	if id == 2 {
+		return scrappertypes.LinksPage{}, errors.New("connection to the database is lost")
+	}
+
+	return s.contractStorage.GetLinks(ctx, id, query)
+}
+
+func (s refreshStorage) ClaimLink(_ context.Context, _ string, linkID int64, _ time.Duration) (scrappertypes.LinkResponse, bool) {
+	if linkID != 1 {
+		<-s.release
+	}
+
+	return scrappertypes.LinkResponse{}, false
+}
+
+func TestServer_RefreshLinks(t *testing.T) {
+	storage := refreshStorage{release: make(chan struct{})}
+	defer close(storage.release)
+
+	server := scrapper.NewServer(storage, &scrapper.MockBotClient{})
+	server.Configure(&config.Config{Workers: 4, RefreshTimeout: 50 * time.Millisecond})
+
+	handler := server.Handler()
+
+	t.Run("links that are not checked before the timeout are in progress", func(t *testing.T) {
+		start := time.Now()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/links/refresh?Tg-Chat-Id=1", http.NoBody))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Less(t, time.Since(start), time.Second)
+
+		var response scrappertypes.RefreshResponse
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 0, response.Checked)
+		assert.Equal(t, 3, response.InProgress)
+	})
+
+	t.Run("storage errors are not reported as an unknown chat", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/links/refresh?Tg-Chat-Id=2", http.NoBody))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("unknown chat", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/links/refresh?Tg-Chat-Id=3", http.NoBody))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
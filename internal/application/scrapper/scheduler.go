@@ -0,0 +1,70 @@
+package scrapper
+
+import (
+	"sync"
+	"time"
+)
+
+// SchedulerStats describes the monitoring runs started by the scheduler.
+type SchedulerStats struct {
+	Running             bool      `json:"running"`
+	Runs                int64     `json:"runs"`
+	SkippedRuns         int64     `json:"skipped_runs"`
+	LastStartedAt       time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt      time.Time `json:"last_finished_at,omitempty"`
+	LastDurationSeconds float64   `json:"last_duration_seconds"`
+	LastLagSeconds      float64   `json:"last_lag_seconds"`
+}
+
+// RunTracker lets only one monitoring run be active at a time and collects its stats.
+type RunTracker struct {
+	mu    sync.Mutex
+	stats SchedulerStats
+}
+
+// TryStart marks a run as started and returns the lag behind its scheduled time.
+// It returns false if the previous run is still active, in which case the run is skipped.
+func (t *RunTracker) TryStart(scheduledAt, now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stats.Running {
+		t.stats.SkippedRuns++
+
+		return 0, false
+	}
+
+	var lag time.Duration
+
+	if !scheduledAt.IsZero() && now.After(scheduledAt) {
+		lag = now.Sub(scheduledAt)
+	}
+
+	t.stats.Running = true
+	t.stats.Runs++
+	t.stats.LastStartedAt = now
+	t.stats.LastLagSeconds = lag.Seconds()
+
+	return lag, true
+}
+
+// Finish marks the active run as finished and returns its duration.
+func (t *RunTracker) Finish(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	duration := now.Sub(t.stats.LastStartedAt)
+
+	t.stats.Running = false
+	t.stats.LastFinishedAt = now
+	t.stats.LastDurationSeconds = duration.Seconds()
+
+	return duration
+}
+
+func (t *RunTracker) Stats() SchedulerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stats
+}
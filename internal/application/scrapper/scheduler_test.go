@@ -0,0 +1,36 @@
+package scrapper_test
+
+import (
+	"go-progira/internal/application/scrapper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTracker(t *testing.T) {
+	var tracker scrapper.RunTracker
+
+	scheduledAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := scheduledAt.Add(3 * time.Second)
+
+	lag, ok := tracker.TryStart(scheduledAt, startedAt)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, lag)
+
+	_, ok = tracker.TryStart(scheduledAt.Add(time.Minute), startedAt.Add(time.Minute))
+	assert.False(t, ok, "run must be skipped while the previous one is active")
+
+	duration := tracker.Finish(startedAt.Add(90 * time.Second))
+	assert.Equal(t, 90*time.Second, duration)
+
+	_, ok = tracker.TryStart(scheduledAt.Add(2*time.Minute), startedAt.Add(2*time.Minute))
+	assert.True(t, ok, "run must start after the previous one finished")
+
+	stats := tracker.Stats()
+	assert.True(t, stats.Running)
+	assert.Equal(t, int64(2), stats.Runs)
+	assert.Equal(t, int64(1), stats.SkippedRuns)
+	assert.InDelta(t, 90.0, stats.LastDurationSeconds, 1e-9)
+	assert.InDelta(t, 3.0, stats.LastLagSeconds, 1e-9)
+}
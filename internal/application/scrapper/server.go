@@ -4,135 +4,348 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
 	"go-progira/internal/application/scrapper/api"
-	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/auth"
+	"go-progira/internal/domain/types/apitypes"
 	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/internal/health"
+	"go-progira/internal/metrics"
 	repository "go-progira/internal/repository/dictionary_storage"
+	"go-progira/internal/tracing"
 	"go-progira/pkg/config"
 	"go-progira/pkg/e"
+	"io"
 	"log/slog"
-	"math"
 	"net/http"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-co-op/gocron"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
 )
 
+var tracer = tracing.Tracer("go-progira/scrapper")
+
+var _ scrapperapi.ServerInterface = (*Server)(nil)
+
 type Server struct {
 	Storage   repository.LinkService
 	BotClient HTTPBotClient
+	// Updates serves the streams of the bots when updates are delivered over gRPC.
+	Updates    *GRPCBotClient
+	Polling    PollingPolicy
+	InstanceID string
+	runs       RunTracker
+
+	config         *config.Config
+	refreshLimiter *RefreshLimiter
+
+	Retry      RetryPolicy
+	BotBatch   int
+	outboxKick chan struct{}
+	outboxStop chan struct{}
+	outboxDone chan struct{}
+
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	grpcHealth *grpchealth.Server
+	scheduler  *gocron.Scheduler
+	errs       chan error
+	probes     *health.Probes
+	drainDelay time.Duration
+	// importTimeout bounds the time an import asks the providers about new links, zero means no limit.
+	importTimeout time.Duration
+
+	// checksCtx is canceled when in-flight checks have to be abandoned on shutdown.
+	checksCtx    context.Context
+	cancelChecks context.CancelFunc
+	// draining is closed on shutdown, after it no new links are claimed.
+	draining chan struct{}
+
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 func NewServer(storage repository.LinkService, client HTTPBotClient) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Server{
-		Storage:   storage,
-		BotClient: client,
+		Storage:      storage,
+		BotClient:    client,
+		errs:         make(chan error, 1),
+		checksCtx:    ctx,
+		cancelChecks: cancel,
+		draining:     make(chan struct{}),
+		outboxKick:   make(chan struct{}, 1),
+		outboxStop:   make(chan struct{}),
+		probes:       health.NewProbes(),
 	}
 }
 
+// Configure sets up the policies of the server from config without starting anything.
+func (s *Server) Configure(config *config.Config) {
+	s.Polling = NewPollingPolicy(config)
+	s.InstanceID = config.InstanceID
+	s.config = config
+	s.refreshLimiter = NewRefreshLimiter(config.RefreshCooldown)
+	s.Retry = NewRetryPolicy(config)
+	s.BotBatch = config.BotBatchSize
+	s.drainDelay = config.ShutdownDrainDelay
+	s.importTimeout = config.ImportTimeout
+}
+
+// Start starts the scheduler and the HTTP server in the background.
+// Errors of the HTTP server are reported through Errors.
 func (s *Server) Start(config *config.Config) {
-	http.HandleFunc("/tg-chat/{id}", s.ChatHandler)
-	http.HandleFunc("/links", s.LinksHandler)
-	http.HandleFunc("/tags", s.TagsHandler)
+	s.registerAPI(http.DefaultServeMux)
+	http.HandleFunc("/scheduler", s.SchedulerHandler)
+	http.HandleFunc("/delivery", s.DeliveryHandler)
+	http.Handle("/metrics", metrics.Handler())
+	s.probes.Register(http.DefaultServeMux)
+	s.probes.AddCheck("database", s.Storage.Ping)
+	s.probes.AddCheck("scheduler", s.schedulerCheck)
+	s.Configure(config)
+	s.outboxDone = make(chan struct{})
+
+	go s.runOutbox(config.OutboxInterval)
+
 	s.startScheduler(config)
 	api.InitUpdaters(config.StackoverflowAPIKey, config.GithubAPIKey)
 
 	slog.Info("Starting scrapper server on",
 		slog.String("address", config.ScrapperHost))
 
-	srv := &http.Server{
+	handler := auth.Middleware(config.ServiceSecret, config.SignatureMaxAge, rejectUnauthorized, http.DefaultServeMux)
+
+	s.httpServer = &http.Server{
 		Addr:         config.ScrapperHost,
-		Handler:      nil,
+		Handler:      tracing.Middleware(metrics.Middleware(handler)),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
-		slog.Error(
-			e.ErrServerFailed.Error(),
-			slog.String("error", err.Error()),
-		)
-	}
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(
+				e.ErrServerFailed.Error(),
+				slog.String("error", err.Error()),
+			)
+
+			s.errs <- err
+		}
+	}()
+
+	s.startGRPC(config)
+
+	s.probes.SetReady(true)
+	s.grpcHealth.Resume()
 }
 
-func (s *Server) processLink(ctx context.Context, link *scrappertypes.LinkResponse) {
-	prevTime := s.Storage.GetPreviousUpdate(ctx, link.ID)
+// Handler returns the handler of the API described by the OpenAPI spec.
+func (s *Server) Handler() http.Handler {
+	return s.registerAPI(http.NewServeMux())
+}
 
-	var lastUpdateTime time.Time
+func (s *Server) registerAPI(mux *http.ServeMux) http.Handler {
+	return scrapperapi.HandlerWithOptions(s, scrapperapi.StdHTTPServerOptions{
+		BaseRouter:       mux,
+		ErrorHandlerFunc: sendParamError,
+	})
+}
 
-	var msg string
+func (s *Server) Errors() <-chan error {
+	return s.errs
+}
 
-	if updater, ok := api.GetUpdater(link.URL); ok {
-		msg, lastUpdateTime = updater.GetUpdates(link.URL, prevTime)
-		if msg == "" {
-			return
-		}
-	} else {
-		slog.Error(
-			e.ErrWrongURLFormat.Error(),
-			slog.String("url", link.URL),
-		)
+// Shutdown fails readiness and keeps serving for the drain delay, so that the instance is taken
+// out of rotation first. Then it stops accepting requests and new monitoring runs, waits for
+// the running checks to finish and flushes the outbox. The update streams of the bots are closed
+// only after the outbox is flushed. When ctx expires, the checks are canceled;
+// their links stay leased and are claimed again once the lease expires.
+// The flush is bounded by ShutdownTimeout on its own, undelivered notifications stay in the outbox.
+// Storage is closed at the end. Later calls wait for the first one and return its result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.shutdown(ctx)
+	})
 
-		return
+	return s.shutdownErr
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
+	slog.Info("Shutting down scrapper server")
+
+	s.probes.SetReady(false)
+	close(s.draining)
+
+	if s.grpcHealth != nil {
+		s.grpcHealth.Shutdown()
 	}
 
-	errSave := s.saveLastUpdate(ctx, link.ID, lastUpdateTime)
-	if errSave != nil {
-		slog.Error("Error saving update")
+	var errs []error
 
-		return
+	if s.httpServer != nil && s.drainDelay > 0 {
+		select {
+		case <-time.After(s.drainDelay):
+		case <-ctx.Done():
+		}
 	}
 
-	IDs := s.Storage.GetTgChatIDsForLink(ctx, link.URL)
+	grpcStopped := make(chan struct{})
 
-	if len(IDs) == 0 {
-		return
+	if s.grpcServer != nil {
+		// New calls are refused right away, open streams keep working until the outbox is flushed.
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
 	}
 
-	updForBot := bottypes.LinkUpdate{
-		URL:         link.URL,
-		Description: msg,
-		TgChatIDs:   IDs,
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop http server: %w", err))
+		}
 	}
 
-	errSend := s.BotClient.SendUpdate(updForBot)
-	if errSend != nil {
-		return
+	if s.scheduler != nil {
+		stopped := make(chan struct{})
+
+		go func() {
+			s.scheduler.Stop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			slog.Warn("Link checks did not finish before the deadline, canceling them")
+			s.cancelChecks()
+			<-stopped
+			errs = append(errs, fmt.Errorf("wait for link checks: %w", ctx.Err()))
+		}
 	}
-}
 
-func splitIntoChunks(links []scrappertypes.LinkResponse, numChunks int) [][]scrappertypes.LinkResponse {
-	var chunks [][]scrappertypes.LinkResponse
+	if s.outboxDone != nil {
+		close(s.outboxStop)
 
-	chunkSize := int(math.Ceil(float64(len(links)) / float64(numChunks)))
+		select {
+		case <-s.outboxDone:
+		case <-ctx.Done():
+			// Only a pass that is still running is canceled, the last flush has its own deadline.
+			s.cancelChecks()
+			<-s.outboxDone
+		}
+	}
+
+	s.cancelChecks()
+
+	if s.grpcServer != nil {
+		if s.Updates != nil {
+			s.Updates.Close()
+		}
 
-	for i := 0; i < len(links); i += chunkSize {
-		end := i + chunkSize
-		if end > len(links) {
-			end = len(links)
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			s.grpcServer.Stop()
+			<-grpcStopped
+			errs = append(errs, fmt.Errorf("stop grpc server: %w", ctx.Err()))
 		}
+	}
 
-		chunks = append(chunks, links[i:end])
+	if closer, ok := s.BotClient.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close bot client: %w", err))
+		}
 	}
 
-	return chunks
+	s.Storage.Close()
+
+	slog.Info("Scrapper server stopped")
+
+	return errors.Join(errs...)
 }
 
-func (s *Server) processChunk(ctx context.Context, chunk []scrappertypes.LinkResponse, wg *sync.WaitGroup) {
-	defer wg.Done()
+func (s *Server) isDraining() bool {
+	select {
+	case <-s.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// processLink sends new events of the link to its subscribers and reports whether there were any.
+// The returned error tells that the link could not be checked, it is wrapped with e.ErrWrite
+// when the events could not be saved, so they are found again by the next check.
+func (s *Server) processLink(ctx context.Context, link *scrappertypes.LinkResponse) (bool, error) {
+	prevTime := s.Storage.GetPreviousUpdate(ctx, link.ID)
+
+	updater, ok := api.GetUpdater(link.URL)
+	if !ok {
+		slog.Error(
+			e.ErrWrongURLFormat.Error(),
+			slog.String("url", link.URL),
+		)
+
+		return false, e.ErrWrongURLFormat
+	}
+
+	provider := providerName(link.URL)
+	start := time.Now()
+
+	events, lastUpdateTime, err := updater.GetUpdates(ctx, link.URL, prevTime)
+
+	providerLatency.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		providerErrors.WithLabelValues(provider, errorReason(err)).Inc()
+
+		return false, err
+	}
+
+	if len(events) == 0 {
+		return false, nil
+	}
+
+	updatesFound.WithLabelValues(provider).Add(float64(len(events)))
+
+	subscribers := s.Storage.GetSubscribers(ctx, link.ID)
+	updates := SplitEventsBySubscribers(link, events, subscribers)
+
+	errSave := s.Storage.SaveUpdateWithNotifications(ctx, link.ID, lastUpdateTime, updates)
+	if errSave != nil {
+		slog.Error("Failed to save last update time",
+			slog.Int64("link id", link.ID),
+			slog.String("error", errSave.Error()))
 
-	for _, link := range chunk {
-		s.processLink(ctx, &link)
+		return false, fmt.Errorf("%w: %w", e.ErrWrite, errSave)
 	}
+
+	return true, nil
 }
 
-func (s *Server) monitorLinks(config *config.Config) {
-	ctx := context.Background()
+// releaseLink schedules the next check of the link and gives its lease back.
+func (s *Server) releaseLink(ctx context.Context, link *scrappertypes.LinkResponse, hadUpdates bool) {
+	interval := s.Polling.NextInterval(link.CheckInterval, link.UserInterval, hadUpdates)
+
+	err := s.Storage.ReleaseLink(ctx, s.InstanceID, link.ID, interval)
+	if errors.Is(err, e.ErrLeaseLost) {
+		slog.Warn("Link lease expired before the check finished",
+			slog.Int64("link id", link.ID),
+			slog.String("instance", s.InstanceID))
+	} else if err != nil {
+		slog.Error("Failed to release link",
+			slog.Int64("link id", link.ID),
+			slog.String("error", err.Error()))
+	}
+}
 
+func (s *Server) monitorLinks(ctx context.Context, config *config.Config) {
 	if config.Workers <= 0 {
 		slog.Error("Invalid number of workers, it must be greater than zero",
 			slog.Int("given number of workers", config.Workers),
@@ -141,125 +354,174 @@ func (s *Server) monitorLinks(config *config.Config) {
 		return
 	}
 
-	links, lastID := s.Storage.GetBatchOfLinks(ctx, config.Batch, int64(0))
+	pool := NewWorkerPool(config.Workers, config.HostConcurrency, func(link scrappertypes.LinkResponse) {
+		if ctx.Err() != nil {
+			return
+		}
 
-	for len(links) != 0 {
-		chunks := splitIntoChunks(links, config.Workers)
+		// The link may have waited in the queue of its host, so its lease is renewed for the check.
+		if err := s.Storage.ExtendLease(ctx, s.InstanceID, link.ID, config.LinkLease); err != nil {
+			slog.Warn("Link is not checked, its lease can't be extended",
+				slog.Int64("link id", link.ID),
+				slog.String("error", err.Error()))
 
-		var wg sync.WaitGroup
+			return
+		}
 
-		wg.Add(config.Workers)
+		hadUpdates := s.checkLink(ctx, &link)
+		s.releaseLink(ctx, &link, hadUpdates)
+	})
 
-		for _, chunk := range chunks {
-			go s.processChunk(ctx, chunk, &wg)
+	// Links are claimed only for free workers, so the leases do not run out in the queue
+	// and the links that can't be checked yet are left to the other instances.
+	for {
+		links := s.Storage.ClaimLinks(ctx, s.InstanceID, min(config.Batch, pool.WaitFree()), config.LinkLease)
+		if len(links) == 0 {
+			break
 		}
 
-		wg.Wait()
+		claimedBatchSize.Observe(float64(len(links)))
 
-		links, lastID = s.Storage.GetBatchOfLinks(ctx, config.Batch, lastID)
-	}
-}
-
-func (s *Server) saveLastUpdate(ctx context.Context, linkID int64, lastUpdateTime time.Time) error {
-	err := s.Storage.SaveLastUpdate(ctx, linkID, lastUpdateTime)
-	if err != nil {
-		slog.Error("Failed to save last update time",
-			slog.Int("link id", int(linkID)))
+		for _, link := range links {
+			pool.Submit(link)
+		}
 
-		return err
+		if s.isDraining() {
+			break
+		}
 	}
 
-	return nil
+	pool.Wait()
+
+	// Updates found by the run are sent together instead of one request per link.
+	s.kickOutbox()
 }
 
 func (s *Server) startScheduler(config *config.Config) {
-	slog.Info("Scheduler started")
-
 	sc := gocron.NewScheduler(time.UTC)
 
-	_, err := sc.Every(2).Minutes().Do(func() {
-		go s.monitorLinks(config)
+	if config.SchedulerCron != "" {
+		sc.Cron(config.SchedulerCron)
+	} else {
+		sc.Every(config.SchedulerInterval)
+	}
+
+	_, err := sc.DoWithJobDetails(func(job gocron.Job) {
+		s.runMonitoring(config, job.LastRun())
 	})
 	if err != nil {
 		slog.Error(
 			e.ErrScheduler.Error(),
 			slog.String("error", err.Error()),
 		)
+
+		return
 	}
 
 	sc.StartAsync()
+
+	s.scheduler = sc
+
+	slog.Info("Scheduler started",
+		slog.String("interval", config.SchedulerInterval.String()),
+		slog.String("cron", config.SchedulerCron))
 }
 
-func (s *Server) LinksHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		s.GetLinks(w, r)
-	case http.MethodPost:
-		s.AddLink(w, r)
-	case http.MethodDelete:
-		s.RemoveLink(w, r)
-	default:
+// runMonitoring runs monitorLinks unless the previous run is still active.
+func (s *Server) runMonitoring(config *config.Config, scheduledAt time.Time) {
+	lag, ok := s.runs.TryStart(scheduledAt, time.Now())
+	if !ok {
+		slog.Warn("Previous monitoring run is still active, skipping this one",
+			slog.Time("scheduled at", scheduledAt))
+
+		return
+	}
+
+	ctx, span := tracer.Start(s.checksCtx, "scheduler.run")
+	s.monitorLinks(ctx, config)
+	span.End()
+
+	duration := s.runs.Finish(time.Now())
+	schedulerRunDuration.Observe(duration.Seconds())
+
+	slog.Info("Monitoring run finished",
+		slog.Duration("duration", duration),
+		slog.Duration("lag", lag))
+}
+
+func (s *Server) SchedulerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, e.ErrMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.runs.Stats()); err != nil {
 		slog.Error(
-			e.ErrMethodNotAllowed.Error(),
-			slog.String("method", r.Method),
-			slog.String("allowed methods", strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodDelete}, " ")),
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", err.Error()),
 		)
-
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (s *Server) ChatHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		s.RegisterChat(w, r)
-	case http.MethodDelete:
-		s.DeleteChat(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// schedulerCheck fails once the scheduler is stopped or the server is draining.
+func (s *Server) schedulerCheck(_ context.Context) error {
+	if s.scheduler == nil || !s.scheduler.IsRunning() || s.isDraining() {
+		return e.ErrNotRunning
 	}
+
+	return nil
 }
 
-func (s *Server) TagsHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		s.GetLinksByTag(w, r)
-	case http.MethodDelete:
-		s.DeleteTag(w, r)
-	default:
+// DeliveryHandler reports which transports delivered the updates to the bot.
+func (s *Server) DeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, e.ErrMethodNotAllowed)
+
+		return
+	}
+
+	reporter, ok := s.BotClient.(DeliveryReporter)
+	if !ok {
+		sendError(w, e.ErrFallbackDisabled)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(reporter.DeliveryStats()); err != nil {
 		slog.Error(
-			e.ErrMethodNotAllowed.Error(),
-			slog.String("method", r.Method),
-			slog.String("allowed methods", strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodDelete}, " ")),
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", err.Error()),
 		)
-
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (s *Server) RegisterChat(w http.ResponseWriter, r *http.Request) {
+func (s *Server) RegisterChat(w http.ResponseWriter, r *http.Request, id int64) {
 	ctx := r.Context()
-	idStr := r.URL.Path[len("/tg-chat/"):]
 
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
 		return
 	}
 
 	errCreate := s.Storage.CreateChat(ctx, id)
 	if errCreate != nil {
-		slog.Error("Error creating chate",
+		slog.Error("Error creating chat",
 			slog.String("error", errCreate.Error()))
-		http.Error(w, "Chat already exists.", http.StatusBadRequest)
+		sendError(w, errCreate)
 
 		return
 	}
 
 	slog.Info("Registered chat")
 
-	response := map[string]interface{}{"message": "Chat registered successfully", "id": id}
+	response := scrapperapi.ChatRegisteredResponse{Message: "Chat registered successfully", Id: id}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	errEncoding := json.NewEncoder(w).Encode(response)
@@ -268,210 +530,403 @@ func (s *Server) RegisterChat(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) DeleteChat(w http.ResponseWriter, r *http.Request) {
+func (s *Server) DeleteChat(w http.ResponseWriter, r *http.Request, id int64) {
 	ctx := r.Context()
-	idStr := r.URL.Path[len("/tg-chat/"):]
 
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
 		return
 	}
 
 	errDelete := s.Storage.DeleteChat(ctx, id)
 	if errDelete != nil {
-		http.Error(w, "Chat not found.", http.StatusNotFound)
+		sendError(w, errDelete)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (s *Server) GetLinks(w http.ResponseWriter, r *http.Request) {
+func (s *Server) GetLinks(w http.ResponseWriter, r *http.Request, params scrapperapi.GetLinksParams) {
 	ctx := r.Context()
-	chatIDStr := r.URL.Query().Get("Tg-Chat-Id")
+	id := params.TgChatId
 
-	id, err := strconv.ParseInt(chatIDStr, 10, 64)
-	if err != nil || id <= 0 {
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
 		return
 	}
 
-	links, errGet := s.Storage.GetLinks(ctx, id)
-	if errGet != nil {
-		slog.Error("Error getting link",
-			slog.String("error", errGet.Error()))
-		http.Error(w, "Chat not found.", http.StatusNotFound)
+	response, errList := s.listLinks(ctx, id, scrappertypes.GetLinksRequest{
+		Sort:   string(deref(params.Sort)),
+		Order:  string(deref(params.Order)),
+		Cursor: deref(params.Cursor),
+		Limit:  deref(params.Limit),
+	})
+	if errList != nil {
+		sendError(w, errList)
 
 		return
 	}
 
-	response1 := scrappertypes.ListLinksResponse{Links: links, Size: len(links)}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	err = json.NewEncoder(w).Encode(response1)
+	err := json.NewEncoder(w).Encode(response)
 	if err != nil {
 		return
 	}
 }
 
-func (s *Server) AddLink(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// listLinks selects the page of the links of the chat the request asks for.
+func (s *Server) listLinks(ctx context.Context, id int64, request scrappertypes.GetLinksRequest) (
+	scrappertypes.ListLinksResponse, error) {
+	query, err := linksQuery(request)
+	if err != nil {
+		return scrappertypes.ListLinksResponse{}, err
+	}
 
-	chatIDStr := r.URL.Query().Get("Tg-Chat-Id")
-	id, err := strconv.ParseInt(chatIDStr, 10, 64)
+	page, err := s.Storage.GetLinks(ctx, id, query)
+	if err != nil {
+		return scrappertypes.ListLinksResponse{}, err
+	}
 
-	if err != nil || id <= 0 {
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+	return listResponse(page), nil
+}
+
+func deref[T any](value *T) T {
+	if value == nil {
+		var zero T
+
+		return zero
+	}
+
+	return *value
+}
+
+func (s *Server) AddLink(w http.ResponseWriter, r *http.Request, params scrapperapi.AddLinkParams) {
+	ctx := r.Context()
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
 		return
 	}
 
 	var request scrappertypes.AddLinkRequest
 	if errDecode := json.NewDecoder(r.Body).Decode(&request); errDecode != nil {
 		slog.Info("BadRequest on Add Link")
-		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		sendError(w, invalidRequest(errDecode.Error()))
 
 		return
 	}
 
-	errAppend := s.Storage.AddLink(ctx, id, request.Link, request.Tags, request.Filters)
+	if request.IntervalSeconds < 0 {
+		sendError(w, invalidRequest("check interval is negative"))
+
+		return
+	}
 
-	if errors.Is(errAppend, e.ErrLinkAlreadyExists) {
-		slog.Info(e.ErrLinkAlreadyExists.Error())
-		http.Error(w, e.ErrLinkAlreadyExists.Error(), http.StatusConflict)
+	link, errParse := api.CanonicalLink(request.Link)
+	if errParse != nil {
+		slog.Info(e.ErrWrongURLFormat.Error(),
+			slog.String("link", request.Link))
+		sendError(w, e.ErrWrongURLFormat)
 
 		return
-	} else if errAppend != nil {
-		slog.Error("Error adding link" + errAppend.Error())
-		http.Error(w, e.ErrAddLink.Error(), http.StatusInternalServerError)
+	}
+
+	resource, ok := s.resolveLink(r.Context(), w, link)
+	if !ok {
+		return
+	}
+
+	response, errAppend := s.trackLink(ctx, id, link, request, resource)
+	if errAppend != nil {
+		sendError(w, errAppend)
 
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+
+	if errEncode := json.NewEncoder(w).Encode(response); errEncode != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", errEncode.Error()),
+		)
+	}
 }
 
-func (s *Server) RemoveLink(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// trackLink adds the link the provider resolved to the chat.
+func (s *Server) trackLink(ctx context.Context, id int64, link string, request scrappertypes.AddLinkRequest,
+	resource apitypes.Resource) (scrappertypes.LinkResponse, error) {
+	// A link saved before links were canonicalized is stored in the form it was sent in.
+	if request.Link != link && s.Storage.IsURLInAdded(ctx, id, request.Link) {
+		return scrappertypes.LinkResponse{}, e.ErrLinkAlreadyExists
+	}
+
+	if err := s.Storage.AddLink(ctx, id, link, request.Tags, request.Filters, resource.LastActivity); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	if request.IntervalSeconds > 0 {
+		errInterval := s.Storage.SetCheckInterval(ctx, id, link, time.Duration(request.IntervalSeconds)*time.Second)
+		if errInterval != nil {
+			slog.Error("Error setting check interval",
+				slog.String("link", link),
+				slog.String("error", errInterval.Error()))
+
+			// The link is not tracked without the interval the chat asked for, so that adding it again sets the interval.
+			if errRemove := s.Storage.RemoveLink(ctx, id, link); errRemove != nil {
+				slog.Error("Error removing link without check interval",
+					slog.String("link", link),
+					slog.String("error", errRemove.Error()))
+			}
+
+			return scrappertypes.LinkResponse{}, errInterval
+		}
+	}
+
+	return scrappertypes.LinkResponse{
+		URL:     link,
+		Tags:    request.Tags,
+		Filters: request.Filters,
+		Title:   resource.Title,
+	}, nil
+}
+
+// resolveLink asks the provider whether the resource behind link exists.
+// On failure it writes the error response and returns false.
+func (s *Server) resolveLink(ctx context.Context, w http.ResponseWriter, link string) (apitypes.Resource, bool) {
+	resource, err := resolve(ctx, link)
+	if err != nil {
+		sendError(w, providerError(err))
+
+		return apitypes.Resource{}, false
+	}
 
-	chatIDStr := r.URL.Query().Get("Tg-Chat-Id")
-	id, err := strconv.ParseInt(chatIDStr, 10, 64)
+	return resource, true
+}
+
+// resolve asks the provider whether the resource behind link exists.
+func resolve(ctx context.Context, link string) (apitypes.Resource, error) {
+	updater, ok := api.GetUpdater(link)
+	if !ok {
+		return apitypes.Resource{}, e.ErrWrongURLFormat
+	}
 
+	resource, err := updater.Resolve(ctx, link)
 	if err != nil {
-		slog.Error("Error parsing id string",
+		slog.Info("Resource cannot be tracked",
+			slog.String("link", link),
 			slog.String("error", err.Error()))
-		return
 	}
 
+	return resource, err
+}
+
+// sendParamError answers the requests whose parameters don't match the spec.
+func sendParamError(w http.ResponseWriter, _ *http.Request, err error) {
+	sendError(w, invalidRequest(err.Error()))
+}
+
+// rejectUnauthorized answers the requests that were not signed by the bot.
+func rejectUnauthorized(w http.ResponseWriter, err error) {
+	slog.Warn("Rejected unauthorized request",
+		slog.String("error", err.Error()))
+
+	sendErrorResponse(w, http.StatusUnauthorized, "Request is not authorized",
+		scrappertypes.CodeUnauthorized, "AuthError", err.Error())
+}
+
+func sendErrorResponse(w http.ResponseWriter, statusCode int, desc, code, exceptionName, exceptionMsg string) {
+	apiError := scrappertypes.APIErrorResponse{
+		Description:      desc,
+		Code:             code,
+		ExceptionName:    exceptionName,
+		ExceptionMessage: exceptionMsg,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if errEncode := json.NewEncoder(w).Encode(apiError); errEncode != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", errEncode.Error()),
+		)
+	}
+}
+
+func (s *Server) RemoveLink(w http.ResponseWriter, r *http.Request, params scrapperapi.RemoveLinkParams) {
+	ctx := r.Context()
+	id := params.TgChatId
+
 	if id <= 0 {
 		slog.Error("Error invalid id(less than zero)",
 			slog.Int("id", int(id)))
 
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+		sendError(w, invalidRequest("invalid chat ID"))
 
 		return
 	}
 
 	var request scrappertypes.RemoveLinkRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		sendError(w, invalidRequest(err.Error()))
 
 		return
 	}
 
-	errRemove := s.Storage.RemoveLink(ctx, id, request.Link)
+	errRemove := withStoredLink(request.Link, func(link string) error {
+		return s.Storage.RemoveLink(ctx, id, link)
+	})
+	if errRemove != nil {
+		sendError(w, errRemove)
 
-	switch {
-	case errRemove == nil:
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-	case errors.Is(errRemove, e.ErrLinkNotFound):
-		http.Error(w, "Link not found.", http.StatusNotFound)
-	default:
-		http.Error(w, "Error while deleting link", http.StatusInternalServerError)
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 }
 
-func (s *Server) GetLinksByTag(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	chatIDStr := r.URL.Query().Get("Tg-Chat-Id")
+// UpdateLink adds and removes tags and filters of a tracked link.
+func (s *Server) UpdateLink(w http.ResponseWriter, r *http.Request, params scrapperapi.UpdateLinkParams) {
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
 
-	id, err := strconv.ParseInt(chatIDStr, 10, 64)
-	if err != nil || id <= 0 {
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
 		return
 	}
 
-	links, errGet := s.Storage.GetLinks(ctx, id)
-
-	if errGet != nil {
-		slog.Error("Error getting link",
-			slog.String("error", errGet.Error()))
-		http.Error(w, "Chat not found.", http.StatusNotFound)
+	var request scrappertypes.UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendError(w, invalidRequest(err.Error()))
 
 		return
 	}
 
-	var request scrappertypes.GetLinksByTagsRequest
-	if errDecode := json.NewDecoder(r.Body).Decode(&request); errDecode != nil {
-		http.Error(w, "Invalid request.", http.StatusBadRequest)
+	response, errUpdate := s.updateLink(r.Context(), id, request)
+	if errUpdate != nil {
+		sendError(w, errUpdate)
+
 		return
 	}
 
-	if len(request.Tags) != 0 {
-		links = filterLinksByTags(links, request.Tags)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if errEncode := json.NewEncoder(w).Encode(response); errEncode != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", errEncode.Error()),
+		)
 	}
+}
 
-	response1 := scrappertypes.ListLinksResponse{Links: links, Size: len(links)}
+// updateLink checks the change the request asks for and applies it to the link of the chat.
+func (s *Server) updateLink(ctx context.Context, id int64, request scrappertypes.UpdateLinkRequest) (
+	scrappertypes.LinkResponse, error) {
+	change := request.LinkChange
 
-	w.Header().Set("Content-Type", "application/json")
+	if len(change.AddTags)+len(change.RemoveTags)+len(change.AddFilters)+len(change.RemoveFilters) == 0 {
+		return scrappertypes.LinkResponse{}, invalidRequest("nothing to change")
+	}
 
-	err = json.NewEncoder(w).Encode(response1)
-	if err != nil {
-		return
+	if err := checkLabels(change.AddTags, change.RemoveTags); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	if err := checkLabels(change.AddFilters, change.RemoveFilters); err != nil {
+		return scrappertypes.LinkResponse{}, err
 	}
+
+	var response scrappertypes.LinkResponse
+
+	err := withStoredLink(request.Link, func(link string) error {
+		var errUpdate error
+
+		response, errUpdate = s.Storage.UpdateLink(ctx, id, link, change)
+
+		return errUpdate
+	})
+
+	return response, err
 }
 
-func (s *Server) DeleteTag(w http.ResponseWriter, r *http.Request) {
+// checkLabels rejects empty tags or filters and the ones that are both added and removed.
+func checkLabels(added, removed []string) error {
+	for _, label := range added {
+		if label == "" || contains(removed, label) {
+			return invalidRequest(fmt.Sprintf("%q can't be added", label))
+		}
+	}
+
+	if contains(removed, "") {
+		return invalidRequest("empty name can't be removed")
+	}
+
+	return nil
+}
+
+func (s *Server) GetLinksByTags(w http.ResponseWriter, r *http.Request, params scrapperapi.GetLinksByTagsParams) {
 	ctx := r.Context()
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+		return
+	}
+
+	page, errGet := s.Storage.GetLinks(ctx, id, scrappertypes.LinksQuery{})
+	if errGet != nil {
+		sendError(w, errGet)
 
-	chatIDStr := r.URL.Query().Get("Tg-Chat-Id")
-	id, err := strconv.ParseInt(chatIDStr, 10, 64)
+		return
+	}
+
+	links := page.Links
+
+	if params.Tag != nil && len(*params.Tag) != 0 {
+		links = filterLinksByTags(links, *params.Tag)
+	}
+
+	response1 := scrappertypes.ListLinksResponse{Links: links, Size: len(links)}
+
+	w.Header().Set("Content-Type", "application/json")
 
+	err := json.NewEncoder(w).Encode(response1)
 	if err != nil {
-		slog.Error("Error parsing id string",
-			slog.String("error", err.Error()))
 		return
 	}
+}
+
+func (s *Server) DeleteTag(w http.ResponseWriter, r *http.Request, params scrapperapi.DeleteTagParams) {
+	ctx := r.Context()
+	id := params.TgChatId
 
 	if id <= 0 {
 		slog.Error("Error invalid id(less than zero)",
 			slog.Int("id", int(id)))
 
-		http.Error(w, "Invalid chat ID", http.StatusBadRequest)
+		sendError(w, invalidRequest("invalid chat ID"))
 
 		return
 	}
 
 	var request scrappertypes.DeleteTagRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		sendError(w, invalidRequest(err.Error()))
 
 		return
 	}
 
 	errDelete := s.Storage.DeleteTag(ctx, id, request.Tag)
 	if errDelete != nil {
-		if errors.Is(errDelete, e.ErrTagNotFound) {
-			http.Error(w, "Error deleting tag", http.StatusNotFound)
-
-			return
-		}
-
-		http.Error(w, "Error deleting tag", http.StatusInternalServerError)
+		sendError(w, errDelete)
 
 		return
 	}
@@ -1,5 +1,23 @@
 package scrapper_test
 
+import (
+	"context"
+	"encoding/json"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/apitypes"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
 // func TestRegisterChat(t *testing.T) {
 //	dict := &repository.DictionaryStorage{Chats: make(map[int64]*scrappertypes.Chat)}
 //	s := &scrapper.Server{
@@ -357,3 +375,337 @@ package scrapper_test
 //		}
 //	}
 //}
+
+func TestSplitEventsBySubscribers(t *testing.T) {
+	base := time.Date(2025, time.May, 5, 18, 0, 0, 0, time.UTC)
+
+	link := &scrappertypes.LinkResponse{ID: 1, URL: "https://github.com/a/b/pulls"}
+
+	events := []apitypes.Event{
+		{CreatedAt: base.Add(time.Minute), Message: "first"},
+		{CreatedAt: base.Add(2 * time.Minute), Message: "second"},
+		{CreatedAt: base.Add(3 * time.Minute), Message: "third"},
+	}
+
+	type TestCase struct {
+		name        string
+		subscribers []scrappertypes.Subscriber
+		expected    []bottypes.LinkUpdate
+	}
+
+	testCases := []TestCase{
+		{
+			name: "all subscribers joined before events share one update",
+			subscribers: []scrappertypes.Subscriber{
+				{TgChatID: 10, SubscribedAt: base},
+				{TgChatID: 20, SubscribedAt: base.Add(-time.Hour)},
+			},
+			expected: []bottypes.LinkUpdate{
+				{ID: 1, URL: link.URL, Description: "first\nsecond\nthird", TgChatIDs: []int64{10, 20}},
+			},
+		},
+		{
+			name: "subscriber joined later gets only events after it joined",
+			subscribers: []scrappertypes.Subscriber{
+				{TgChatID: 10, SubscribedAt: base},
+				{TgChatID: 20, SubscribedAt: base.Add(2 * time.Minute)},
+			},
+			expected: []bottypes.LinkUpdate{
+				{ID: 1, URL: link.URL, Description: "first\nsecond\nthird", TgChatIDs: []int64{10}},
+				{ID: 1, URL: link.URL, Description: "third", TgChatIDs: []int64{20}},
+			},
+		},
+		{
+			name: "subscriber joined after all events gets nothing",
+			subscribers: []scrappertypes.Subscriber{
+				{TgChatID: 10, SubscribedAt: base.Add(3 * time.Minute)},
+			},
+			expected: []bottypes.LinkUpdate{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := scrapper.SplitEventsBySubscribers(link, events, testCase.subscribers)
+
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestServer_ErrorResponses(t *testing.T) {
+	handler := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "invalid chat id",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=0",
+			body:       `{"link":"https://github.com/a/b"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+			wantMsg:    "invalid chat ID",
+		},
+		{
+			name:       "chat id is not a number",
+			method:     http.MethodGet,
+			path:       "/links?Tg-Chat-Id=abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name:       "malformed body",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name:       "negative check interval",
+			method:     http.MethodPost,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b","interval_seconds":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name:       "tag is both added and removed",
+			method:     http.MethodPatch,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b","add_tags":["go"],"remove_tags":["go"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+			wantMsg:    `"go" can't be added`,
+		},
+		{
+			name:       "empty filter is removed",
+			method:     http.MethodPatch,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b","remove_filters":[""]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+		},
+		{
+			name:       "tag is renamed to itself",
+			method:     http.MethodPost,
+			path:       "/tags/rename?Tg-Chat-Id=1",
+			body:       `{"tag":"work","new_name":"work"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+			wantMsg:    "tag is renamed to itself",
+		},
+		{
+			name:       "new tag name has spaces",
+			method:     http.MethodPost,
+			path:       "/tags/rename?Tg-Chat-Id=1",
+			body:       `{"tag":"work","new_name":"day job"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+			wantMsg:    "tag name has spaces",
+		},
+		{
+			name:       "tag is merged into an empty name",
+			method:     http.MethodPost,
+			path:       "/tags/merge?Tg-Chat-Id=1",
+			body:       `{"tag":"work","into":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   scrappertypes.CodeInvalidRequest,
+			wantMsg:    "tag name is empty",
+		},
+		{
+			name:       "details of internal errors are not sent",
+			method:     http.MethodDelete,
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/down"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   scrappertypes.CodeInternal,
+			wantMsg:    http.StatusText(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var apiErr scrappertypes.APIErrorResponse
+
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+			assert.Contains(t, apiErr.ExceptionMessage, tt.wantMsg)
+		})
+	}
+}
+
+// legacyStorage keeps a link saved before links were canonicalized in the form it was sent in.
+type legacyStorage struct {
+	contractStorage
+}
+
+func (legacyStorage) RemoveLink(_ context.Context, _ int64, link string) error {
+	if link != "https://github.com/A/B/pulls/" {
+		return e.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+func TestServer_RemoveLegacyLink(t *testing.T) {
+	handler := scrapper.NewServer(legacyStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		name       string
+		link       string
+		wantStatus int
+	}{
+		{name: "link is removed in the form it was saved in", link: "https://github.com/A/B/pulls/", wantStatus: http.StatusOK},
+		{name: "canonical form of the link is not saved", link: "https://github.com/a/b/pulls", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/links?Tg-Chat-Id=1", strings.NewReader(`{"link":"`+tt.link+`"}`)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// closingStorage counts how many times the storage is closed.
+type closingStorage struct {
+	contractStorage
+	closed *int
+}
+
+func (s closingStorage) Close() {
+	*s.closed++
+}
+
+func TestServer_ShutdownTwice(t *testing.T) {
+	var closed int
+
+	server := scrapper.NewServer(closingStorage{closed: &closed}, &scrapper.MockBotClient{})
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, server.Shutdown(context.Background()))
+
+	assert.Equal(t, 1, closed)
+}
+
+func TestServer_GetLinksPages(t *testing.T) {
+	handler := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	get := func(t *testing.T, query string) (int, scrappertypes.ListLinksResponse) {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/links?Tg-Chat-Id=1&"+query, http.NoBody))
+
+		var response scrappertypes.ListLinksResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+
+		return rec.Code, response
+	}
+
+	urls := func(response scrappertypes.ListLinksResponse) []string {
+		result := make([]string, 0, len(response.Links))
+		for _, link := range response.Links {
+			result = append(result, link.URL)
+		}
+
+		return result
+	}
+
+	t.Run("pages are walked forth and back", func(t *testing.T) {
+		_, first := get(t, "limit=2&sort=url")
+		assert.Equal(t, []string{"https://github.com/a/b", "https://github.com/a/c"}, urls(first))
+		assert.Empty(t, first.PrevCursor)
+		require.NotEmpty(t, first.NextCursor)
+
+		_, second := get(t, "limit=2&cursor="+first.NextCursor)
+		assert.Equal(t, []string{"https://stackoverflow.com/questions/1"}, urls(second))
+		assert.Empty(t, second.NextCursor)
+		require.NotEmpty(t, second.PrevCursor)
+
+		_, back := get(t, "limit=2&cursor="+second.PrevCursor)
+		assert.Equal(t, urls(first), urls(back))
+		assert.Empty(t, back.PrevCursor)
+		assert.NotEmpty(t, back.NextCursor)
+	})
+
+	t.Run("links are sorted in descending order", func(t *testing.T) {
+		_, page := get(t, "limit=2&sort=added&order=desc")
+		assert.Equal(t, []string{"https://github.com/a/c", "https://stackoverflow.com/questions/1"}, urls(page))
+
+		_, next := get(t, "limit=2&cursor="+page.NextCursor)
+		assert.Equal(t, []string{"https://github.com/a/b"}, urls(next))
+	})
+
+	t.Run("all links are listed without a limit", func(t *testing.T) {
+		_, all := get(t, "")
+		assert.Equal(t, 3, all.Size)
+		assert.Empty(t, all.NextCursor)
+	})
+
+	_, first := get(t, "limit=1&sort=url")
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown sort", query: "limit=1&sort=stars"},
+		{name: "limit is too big", query: "limit=1000"},
+		{name: "malformed cursor", query: "limit=1&cursor=abc"},
+		{name: "cursor of another sort", query: "limit=1&sort=added&cursor=" + first.NextCursor},
+		{name: "cursor without a limit", query: "cursor=" + first.NextCursor},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := get(t, tt.query)
+			assert.Equal(t, http.StatusBadRequest, status)
+		})
+	}
+}
+
+func TestServer_ListLinksErrors(t *testing.T) {
+	handler := scrapper.NewServer(refreshStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode string
+	}{
+		{name: "links of unknown chat", path: "/links?Tg-Chat-Id=3", wantCode: scrappertypes.CodeChatNotFound},
+		{name: "links on storage error", path: "/links?Tg-Chat-Id=2", wantCode: scrappertypes.CodeInternal},
+		{name: "links by tags of unknown chat", path: "/tags?Tg-Chat-Id=3", wantCode: scrappertypes.CodeChatNotFound},
+		{name: "links by tags on storage error", path: "/tags?Tg-Chat-Id=2", wantCode: scrappertypes.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			var response scrappertypes.APIErrorResponse
+
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
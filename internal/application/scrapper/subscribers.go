@@ -0,0 +1,54 @@
+package scrapper
+
+import (
+	"go-progira/internal/domain/types/apitypes"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"sort"
+	"strings"
+)
+
+// SplitEventsBySubscribers builds updates for the bot so that every subscriber
+// gets only the events created after it subscribed. Events must be sorted by
+// creation time; subscribers that see the same events share one update.
+func SplitEventsBySubscribers(link *scrappertypes.LinkResponse, events []apitypes.Event,
+	subscribers []scrappertypes.Subscriber) []bottypes.LinkUpdate {
+	chatsByFirstEvent := make(map[int][]int64)
+
+	for _, subscriber := range subscribers {
+		first := sort.Search(len(events), func(i int) bool {
+			return events[i].CreatedAt.After(subscriber.SubscribedAt)
+		})
+
+		if first == len(events) {
+			continue
+		}
+
+		chatsByFirstEvent[first] = append(chatsByFirstEvent[first], subscriber.TgChatID)
+	}
+
+	firsts := make([]int, 0, len(chatsByFirstEvent))
+	for first := range chatsByFirstEvent {
+		firsts = append(firsts, first)
+	}
+
+	sort.Ints(firsts)
+
+	updates := make([]bottypes.LinkUpdate, 0, len(firsts))
+
+	for _, first := range firsts {
+		messages := make([]string, 0, len(events)-first)
+		for _, event := range events[first:] {
+			messages = append(messages, event.Message)
+		}
+
+		updates = append(updates, bottypes.LinkUpdate{
+			ID:          link.ID,
+			URL:         link.URL,
+			Description: strings.Join(messages, "\n"),
+			TgChatIDs:   chatsByFirstEvent[first],
+		})
+	}
+
+	return updates
+}
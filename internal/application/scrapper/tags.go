@@ -0,0 +1,98 @@
+package scrapper
+
+import (
+	"context"
+	"encoding/json"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// GetTags lists the tags of the chat with the number of their links.
+func (s *Server) GetTags(w http.ResponseWriter, r *http.Request, params scrapperapi.GetTagsParams) {
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+
+		return
+	}
+
+	tags, err := s.Storage.ListTags(r.Context(), id)
+	if err != nil {
+		sendError(w, err)
+
+		return
+	}
+
+	sendJSON(w, scrappertypes.ListTagsResponse{Tags: tags, Size: len(tags)})
+}
+
+// RenameTag gives the tag of the chat a name the chat doesn't use yet.
+func (s *Server) RenameTag(w http.ResponseWriter, r *http.Request, params scrapperapi.RenameTagParams) {
+	var request scrappertypes.RenameTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendError(w, invalidRequest(err.Error()))
+
+		return
+	}
+
+	tag, err := s.renameTag(r.Context(), params.TgChatId, request.Tag, request.NewName, false)
+	if err != nil {
+		sendError(w, err)
+
+		return
+	}
+
+	sendJSON(w, tag)
+}
+
+// MergeTags moves the links of the tag to another tag of the chat and drops the tag.
+func (s *Server) MergeTags(w http.ResponseWriter, r *http.Request, params scrapperapi.MergeTagsParams) {
+	var request scrappertypes.MergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendError(w, invalidRequest(err.Error()))
+
+		return
+	}
+
+	tag, err := s.renameTag(r.Context(), params.TgChatId, request.Tag, request.Into, true)
+	if err != nil {
+		sendError(w, err)
+
+		return
+	}
+
+	sendJSON(w, tag)
+}
+
+// renameTag checks the names of a rename, or of a merge, and moves the links of the chat from tag to newName.
+func (s *Server) renameTag(ctx context.Context, id int64, tag, newName string, merge bool) (scrappertypes.TagResponse, error) {
+	switch {
+	case id <= 0:
+		return scrappertypes.TagResponse{}, invalidRequest("invalid chat ID")
+	case strings.TrimSpace(tag) == "" || strings.TrimSpace(newName) == "":
+		return scrappertypes.TagResponse{}, invalidRequest("tag name is empty")
+	case strings.ContainsAny(newName, " \t\n"):
+		return scrappertypes.TagResponse{}, invalidRequest("tag name has spaces")
+	case tag == newName:
+		return scrappertypes.TagResponse{}, invalidRequest("tag is renamed to itself")
+	}
+
+	return s.Storage.RenameTag(ctx, id, tag, newName, merge)
+}
+
+func sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", err.Error()),
+		)
+	}
+}
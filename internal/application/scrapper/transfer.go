@@ -0,0 +1,246 @@
+package scrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-progira/internal/api/openapi/v1/scrapperapi"
+	"go-progira/internal/application/scrapper/api"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// maxImportRows keeps imports from hitting the rate limits of the providers, every new link is resolved.
+const maxImportRows = 200
+
+// importTimeoutReason is the reason of the rows left when the import runs out of time. They are skipped,
+// so sending the file again imports them, the links added before are skipped then.
+const importTimeoutReason = "import ran out of time, send the file again to import the rest"
+
+// ExportLinks sends the links of the chat with their tags and filters as a file.
+func (s *Server) ExportLinks(w http.ResponseWriter, r *http.Request, params scrapperapi.ExportLinksParams) {
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+
+		return
+	}
+
+	format := string(deref(params.Format))
+	if format == "" {
+		format = scrappertypes.FormatJSON
+	}
+
+	data, contentType, err := s.exportLinks(r.Context(), id, format)
+	if err != nil {
+		sendError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "links."+format))
+	w.WriteHeader(http.StatusOK)
+
+	if _, errWrite := w.Write(data); errWrite != nil {
+		slog.Error("Error writing export",
+			slog.String("error", errWrite.Error()))
+	}
+}
+
+// ImportLinks adds the links of the file to the chat and reports what happened to every row.
+func (s *Server) ImportLinks(w http.ResponseWriter, r *http.Request, params scrapperapi.ImportLinksParams) {
+	id := params.TgChatId
+
+	if id <= 0 {
+		sendError(w, invalidRequest("invalid chat ID"))
+
+		return
+	}
+
+	var request scrappertypes.ImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendError(w, invalidRequest(err.Error()))
+
+		return
+	}
+
+	response, err := s.importLinks(r.Context(), id, request)
+	if err != nil {
+		sendError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if errEncode := json.NewEncoder(w).Encode(response); errEncode != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", errEncode.Error()),
+		)
+	}
+}
+
+// exportLinks encodes the links of the chat in the order they were added, returning the content type of the file.
+func (s *Server) exportLinks(ctx context.Context, id int64, format string) (data []byte, contentType string, err error) {
+	file, ok := linkFiles[format]
+	if !ok {
+		return nil, "", invalidRequest("unknown format " + format)
+	}
+
+	page, err := s.Storage.GetLinks(ctx, id, scrappertypes.LinksQuery{Sort: scrappertypes.SortAdded})
+	if err != nil {
+		return nil, "", err
+	}
+
+	links := make([]scrappertypes.ExportedLink, 0, len(page.Links))
+	for _, link := range page.Links {
+		links = append(links, scrappertypes.ExportedLink{URL: link.URL, Tags: link.Tags, Filters: link.Filters})
+	}
+
+	data, err = file.encode(links)
+
+	return data, file.contentType, err
+}
+
+// importLinks reads the file of the request and imports its rows one by one.
+// The providers are asked about new links for at most importTimeout, so the answer is sent before the write timeout.
+func (s *Server) importLinks(ctx context.Context, id int64, request scrappertypes.ImportRequest) (
+	scrappertypes.ImportResponse, error) {
+	file, ok := linkFiles[request.Format]
+	if !ok {
+		return scrappertypes.ImportResponse{}, invalidRequest("unknown format " + request.Format)
+	}
+
+	rows, err := file.decode([]byte(request.Data))
+	if err != nil {
+		return scrappertypes.ImportResponse{}, invalidRequest("file can't be read: " + err.Error())
+	}
+
+	if len(rows) > maxImportRows {
+		return scrappertypes.ImportResponse{}, invalidRequest(fmt.Sprintf("file has more than %d links", maxImportRows))
+	}
+
+	response := scrappertypes.ImportResponse{Rows: make([]scrappertypes.ImportRow, 0, len(rows)), DryRun: request.DryRun}
+	seen := make(map[string]bool, len(rows))
+
+	resolveCtx := ctx
+
+	if s.importTimeout > 0 {
+		var cancel context.CancelFunc
+
+		resolveCtx, cancel = context.WithTimeout(ctx, s.importTimeout)
+		defer cancel()
+	}
+
+	for i, row := range rows {
+		result := s.importRow(ctx, resolveCtx, id, row, seen, request.DryRun)
+		result.Row = i + 1
+
+		switch result.Status {
+		case scrappertypes.ImportAdded:
+			response.Added++
+		case scrappertypes.ImportSkipped:
+			response.Skipped++
+		default:
+			response.Invalid++
+		}
+
+		response.Rows = append(response.Rows, result)
+	}
+
+	return response, nil
+}
+
+// importRow adds the link of the row to the chat unless it is invalid or tracked already.
+// A dry run stops before asking the provider about the link, which is done with resolveCtx.
+func (s *Server) importRow(ctx, resolveCtx context.Context, id int64, row fileRow, seen map[string]bool,
+	dryRun bool) scrappertypes.ImportRow {
+	result := scrappertypes.ImportRow{Link: row.link.URL, Status: scrappertypes.ImportInvalid, Reason: row.reason}
+	if row.reason != "" {
+		return result
+	}
+
+	link, err := api.CanonicalLink(row.link.URL)
+	if err != nil {
+		result.Reason = e.ErrWrongURLFormat.Error()
+
+		return result
+	}
+
+	result.Link = link
+
+	if slices.Contains(row.link.Tags, "") || slices.Contains(row.link.Filters, "") {
+		result.Reason = "tag or filter is empty"
+
+		return result
+	}
+
+	result.Status = scrappertypes.ImportSkipped
+
+	switch {
+	case seen[link]:
+		result.Reason = "link is repeated in the file"
+
+		return result
+	case s.Storage.IsURLInAdded(ctx, id, link), row.link.URL != link && s.Storage.IsURLInAdded(ctx, id, row.link.URL):
+		result.Reason = e.ErrLinkAlreadyExists.Error()
+
+		return result
+	}
+
+	seen[link] = true
+	result.Status, result.Reason = scrappertypes.ImportAdded, ""
+
+	if dryRun {
+		return result
+	}
+
+	result.Status, result.Reason = s.addImported(ctx, resolveCtx, id, link, row.link)
+
+	return result
+}
+
+// addImported asks the provider about the link and tracks it, returning the status and the reason of its row.
+// The links the provider is not asked about before resolveCtx is done are skipped.
+func (s *Server) addImported(ctx, resolveCtx context.Context, id int64, link string, imported scrappertypes.ExportedLink) (
+	status, reason string) {
+	if resolveCtx.Err() != nil {
+		return scrappertypes.ImportSkipped, importTimeoutReason
+	}
+
+	resource, err := resolve(resolveCtx, link)
+
+	switch {
+	case err != nil && resolveCtx.Err() != nil:
+		return scrappertypes.ImportSkipped, importTimeoutReason
+	case err != nil:
+		return scrappertypes.ImportInvalid, providerError(err).Error()
+	}
+
+	_, err = s.trackLink(ctx, id, link, scrappertypes.AddLinkRequest{
+		Link:    link,
+		Tags:    imported.Tags,
+		Filters: imported.Filters,
+	}, resource)
+
+	switch {
+	case errors.Is(err, e.ErrLinkAlreadyExists):
+		return scrappertypes.ImportSkipped, err.Error()
+	case err != nil:
+		slog.Error("Error importing link",
+			slog.String("link", link),
+			slog.String("error", err.Error()))
+
+		return scrappertypes.ImportInvalid, "link can't be saved"
+	}
+
+	return scrappertypes.ImportAdded, ""
+}
@@ -0,0 +1,136 @@
+package scrapper_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-progira/internal/application/scrapper"
+	"go-progira/internal/domain/types/scrappertypes"
+	"go-progira/pkg/config"
+	"go-progira/pkg/e"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ExportImportLinks(t *testing.T) {
+	handler := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		format      string
+		contentType string
+	}{
+		{format: scrappertypes.FormatJSON, contentType: "application/json"},
+		{format: scrappertypes.FormatCSV, contentType: "text/csv"},
+		{format: scrappertypes.FormatOPML, contentType: "text/x-opml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/links/export?Tg-Chat-Id=1&format="+tt.format, http.NoBody))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+
+			data, err := io.ReadAll(rec.Body)
+			require.NoError(t, err)
+
+			report := importLinks(t, handler, scrappertypes.ImportRequest{Format: tt.format, Data: string(data), DryRun: true})
+
+			links := make([]string, 0, len(report.Rows))
+			for _, row := range report.Rows {
+				links = append(links, row.Link)
+			}
+
+			assert.Equal(t, []string{"https://github.com/a/b", "https://stackoverflow.com/questions/1", "https://github.com/a/c"}, links)
+		})
+	}
+}
+
+func TestServer_ImportLinksReport(t *testing.T) {
+	handler := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	data := "url,tags,filters\n" +
+		"https://github.com/a/b/pulls,work\n" +
+		"github.com/C/D/issues,go,user=a\n" +
+		"https://github.com/c/d/issues/\n" +
+		"not a link\n" +
+		"https://github.com/c/e/pulls,go,,extra\n"
+
+	report := importLinks(t, handler, scrappertypes.ImportRequest{Format: scrappertypes.FormatCSV, Data: data, DryRun: true})
+
+	assert.Equal(t, []scrappertypes.ImportRow{
+		{Row: 1, Link: "https://github.com/a/b/pulls", Status: scrappertypes.ImportSkipped, Reason: e.ErrLinkAlreadyExists.Error()},
+		{Row: 2, Link: "https://github.com/c/d/issues", Status: scrappertypes.ImportAdded},
+		{Row: 3, Link: "https://github.com/c/d/issues", Status: scrappertypes.ImportSkipped, Reason: "link is repeated in the file"},
+		{Row: 4, Link: "not a link", Status: scrappertypes.ImportInvalid, Reason: e.ErrWrongURLFormat.Error()},
+		{Row: 5, Link: "https://github.com/c/e/pulls", Status: scrappertypes.ImportInvalid, Reason: "row has more than 3 columns"},
+	}, report.Rows)
+	assert.Equal(t, 1, report.Added)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 2, report.Invalid)
+}
+
+func TestServer_ImportLinksTimeout(t *testing.T) {
+	server := scrapper.NewServer(contractStorage{}, &scrapper.MockBotClient{})
+	server.Configure(&config.Config{ImportTimeout: time.Nanosecond})
+
+	data := "url,tags,filters\n" +
+		"https://github.com/c/d/issues\n" +
+		"https://github.com/a/b/pulls\n" +
+		"not a link\n"
+
+	report := importLinks(t, server.Handler(), scrappertypes.ImportRequest{Format: scrappertypes.FormatCSV, Data: data})
+
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, scrappertypes.ImportSkipped, report.Rows[0].Status, "links that are not resolved in time are skipped")
+	assert.Contains(t, report.Rows[0].Reason, "send the file again")
+	assert.Equal(t, e.ErrLinkAlreadyExists.Error(), report.Rows[1].Reason)
+	assert.Equal(t, scrappertypes.ImportInvalid, report.Rows[2].Status)
+	assert.Equal(t, 0, report.Added)
+}
+
+func TestServer_ExportLinksErrors(t *testing.T) {
+	handler := scrapper.NewServer(refreshStorage{}, &scrapper.MockBotClient{}).Handler()
+
+	tests := []struct {
+		name   string
+		chatID string
+		status int
+	}{
+		{name: "unknown chat", chatID: "3", status: http.StatusNotFound},
+		{name: "storage error", chatID: "2", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/links/export?Tg-Chat-Id="+tt.chatID, http.NoBody))
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func importLinks(t *testing.T, handler http.Handler, request scrappertypes.ImportRequest) scrappertypes.ImportResponse {
+	t.Helper()
+
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/links/import?Tg-Chat-Id=1", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var report scrappertypes.ImportResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+
+	return report
+}
@@ -0,0 +1,133 @@
+// Package auth signs the requests the bot and the scrapper send to each other and verifies them.
+//
+// A request carries the unix time it was sent at in X-Timestamp and the hex HMAC-SHA256 of
+// the timestamp, method, path with query and body in X-Signature. Requests older than
+// the allowed age are rejected, so a captured request can't be replayed later.
+// gRPC calls carry the same values in x-timestamp and x-signature metadata.
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-progira/pkg/e"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Timestamp"
+	SignatureHeader = "X-Signature"
+
+	maxSignedBody = 10 << 20
+)
+
+// publicPaths are served without a signature, probes and Prometheus don't know the secret.
+var publicPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// RejectFunc writes the response to a request that failed verification.
+type RejectFunc func(w http.ResponseWriter, err error)
+
+// Sign sets the timestamp and signature headers of req for the given body.
+func Sign(req *http.Request, body []byte, secret string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, signature(secret, timestamp, req.Method, req.URL.RequestURI(), body))
+}
+
+// Verify checks the signature of r and that it was sent no more than maxAge away from now.
+// The body of r is read and replaced, so handlers can still read it.
+// A body larger than maxSignedBody is not signed as a whole, so it's rejected with e.ErrBodyTooLarge.
+func Verify(r *http.Request, secret string, maxAge time.Duration, now time.Time) error {
+	timestamp := r.Header.Get(TimestampHeader)
+	got := r.Header.Get(SignatureHeader)
+
+	if err := checkTimestamp(timestamp, got, maxAge, now); err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+	if err != nil {
+		return fmt.Errorf("%w: %w", e.ErrReadBody, err)
+	}
+
+	if len(body) > maxSignedBody {
+		return fmt.Errorf("%w: more than %d bytes", e.ErrBodyTooLarge, maxSignedBody)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return checkSignature(got, signature(secret, timestamp, r.Method, r.URL.RequestURI(), body))
+}
+
+// checkTimestamp checks that the request is signed and was sent no more than maxAge away from now.
+func checkTimestamp(timestamp, got string, maxAge time.Duration, now time.Time) error {
+	if timestamp == "" || got == "" {
+		return e.ErrUnsigned
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return e.ErrBadSignature
+	}
+
+	if age := now.Sub(time.Unix(sentAt, 0)).Abs(); age > maxAge {
+		return fmt.Errorf("%w: sent %s away from now", e.ErrStaleRequest, age)
+	}
+
+	return nil
+}
+
+func checkSignature(got, want string) error {
+	if !hmac.Equal([]byte(got), []byte(want)) {
+		return e.ErrBadSignature
+	}
+
+	return nil
+}
+
+// Middleware lets through only the requests signed with secret, apart from the probes and metrics.
+// Bodies too large to be verified are answered with 413, the other failures with reject.
+func Middleware(secret string, maxAge time.Duration, reject RejectFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		err := Verify(r, secret, maxAge, time.Now())
+
+		switch {
+		case errors.Is(err, e.ErrBodyTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+			return
+		case err != nil:
+			reject(w, err)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func signature(secret, timestamp, method, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + uri + "\n"))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,149 @@
+package auth_test
+
+import (
+	"bytes"
+	"errors"
+	"go-progira/internal/auth"
+	"go-progira/pkg/e"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "test-secret"
+
+func TestMiddleware(t *testing.T) {
+	var rejected error
+
+	handler := auth.Middleware(secret, time.Minute, func(w http.ResponseWriter, err error) {
+		rejected = err
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	sign := func(secret string, sentAt time.Time) func(*http.Request, []byte) {
+		return func(req *http.Request, body []byte) {
+			auth.Sign(req, body, secret, sentAt)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		sign       func(*http.Request, []byte)
+		tamper     bool
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:       "signed request is served",
+			path:       "/links?Tg-Chat-Id=1",
+			body:       `{"link":"https://github.com/a/b"}`,
+			sign:       sign(secret, time.Now()),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsigned request is rejected",
+			path:       "/links?Tg-Chat-Id=1",
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    e.ErrUnsigned,
+		},
+		{
+			name:       "request signed with another secret is rejected",
+			path:       "/links?Tg-Chat-Id=1",
+			sign:       sign("other-secret", time.Now()),
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    e.ErrBadSignature,
+		},
+		{
+			name:       "replayed request is rejected",
+			path:       "/links?Tg-Chat-Id=1",
+			sign:       sign(secret, time.Now().Add(-time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    e.ErrStaleRequest,
+		},
+		{
+			name:       "request with changed body is rejected",
+			path:       "/updates",
+			body:       `{"tgChatIds":[1]}`,
+			sign:       sign(secret, time.Now()),
+			tamper:     true,
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    e.ErrBadSignature,
+		},
+		{
+			name:       "request with too large body is rejected",
+			path:       "/links/import?Tg-Chat-Id=1",
+			body:       strings.Repeat("a", 10<<20+1),
+			sign:       sign(secret, time.Now()),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "probes don't need a signature",
+			path:       "/readyz",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected = nil
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			if tt.sign != nil {
+				tt.sign(req, []byte(tt.body))
+			}
+
+			if tt.tamper {
+				req.Body = io.NopCloser(bytes.NewBufferString(`{"tgChatIds":[2]}`))
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.True(t, errors.Is(rejected, tt.wantErr), "got error %v", rejected)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(auth.Middleware(secret, time.Minute, func(w http.ResponseWriter, _ error) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	})))
+	defer server.Close()
+
+	client := http.Client{Transport: auth.NewTransport(secret)}
+
+	for _, body := range []string{"", `{"link":"https://github.com/a/b"}`} {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/links?Tg-Chat-Id=1", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		got, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, body, string(got))
+		assert.Empty(t, req.Header.Get(auth.SignatureHeader), "request of the caller must not be changed")
+	}
+}
@@ -0,0 +1,107 @@
+package auth_test
+
+import (
+	"context"
+	protov1 "go-progira/internal/api/proto/v1"
+	"go-progira/internal/auth"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const method = "/api.proto.v1.ScrapperService/RemoveLink"
+
+// signedContext runs the client interceptor and returns the context the server would see.
+func signedContext(t *testing.T, secret, fullMethod string, req any) context.Context {
+	t.Helper()
+
+	var outgoing metadata.MD
+
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+
+		return nil
+	}
+
+	err := auth.UnaryClientInterceptor(secret)(context.Background(), fullMethod, req, nil, nil, invoker)
+	assert.NoError(t, err)
+
+	return metadata.NewIncomingContext(context.Background(), outgoing)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := auth.UnaryServerInterceptor(secret, time.Minute)
+	request := &protov1.RemoveLinkRequest{ChatId: 1, Link: "https://github.com/a/b"}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		req      any
+		wantCode codes.Code
+	}{
+		{
+			name:     "signed call is served",
+			ctx:      signedContext(t, secret, method, request),
+			method:   method,
+			req:      request,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "unsigned call is rejected",
+			ctx:      context.Background(),
+			method:   method,
+			req:      request,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "call signed with another secret is rejected",
+			ctx:      signedContext(t, "other-secret", method, request),
+			method:   method,
+			req:      request,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "call with changed request is rejected",
+			ctx:      signedContext(t, secret, method, request),
+			method:   method,
+			req:      &protov1.RemoveLinkRequest{ChatId: 2, Link: "https://github.com/a/b"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "signature of another method is rejected",
+			ctx:      signedContext(t, secret, "/api.proto.v1.ScrapperService/EnableLink", request),
+			method:   method,
+			req:      request,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "health checks don't need a signature",
+			ctx:      context.Background(),
+			method:   "/grpc.health.v1.Health/Check",
+			req:      request,
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served := false
+
+			_, err := interceptor(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(_ context.Context, _ any) (any, error) {
+					served = true
+
+					return nil, nil
+				})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, served)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Transport signs every request before passing it to Base, http.DefaultTransport if it is nil.
+type Transport struct {
+	Secret string
+	Base   http.RoundTripper
+}
+
+func NewTransport(secret string) *Transport {
+	return &Transport{Secret: secret}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A RoundTripper must not modify the request it was given.
+	signed := req.Clone(req.Context())
+	signed.Body = http.NoBody
+
+	if len(body) > 0 {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+		signed.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	Sign(signed, body, t.Secret, time.Now())
+
+	return t.base().RoundTrip(signed)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
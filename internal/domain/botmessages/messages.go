@@ -20,18 +20,85 @@ https://github.com/author/repository/issues
 	MsgErrAddLink         = "Произошла ошибка при сохранении ссылки"
 	MsgErrDeleteLink      = "Произошла ошибка при удалении ссылки"
 	MsgSaved              = "Сохранил!"
+	MsgSavedWithTitle     = "Сохранил! Отслеживаю: %s"
+	MsgResourceNotFound   = "Не нашёл такой репозиторий или вопрос. Проверьте ссылку."
+	MsgResourcePrivate    = "Нет доступа к ресурсу: возможно, он приватный."
+	MsgRateLimited        = "Превышен лимит запросов к сервису. Попробуйте позже."
+	MsgProviderDown       = "Сервис, на который ведёт ссылка, сейчас недоступен. Попробуйте позже."
+	MsgChatNotRegistered  = "Чат не зарегистрирован. Отправьте /start, чтобы начать."
+	MsgErrGetLinks        = "Не удалось получить список ссылок"
 	MsgDeleted            = "Удалил!"
 	MsgLinkAlreadyExists  = "В списке отслеживаемых уже есть эта ссылка "
 	MsgAddTags            = "Введите теги через пробел"
 	MsgAddFilters         = "Введите фильтры через пробел"
+	MsgWrongInterval      = "Интервал проверки задаётся так: /track ссылка every 10m. Минимальный интервал - 1 минута."
+	MsgRefreshNothingNew  = "Проверил, ничего нового."
+	MsgRefreshUpdated     = "Нашёл новые события, сейчас пришлю их по ссылкам:\n%s"
+	MsgRefreshInProgress  = "Ссылки уже проверяются, новые события придут сами."
+	MsgRefreshTooOften    = "Слишком частые проверки. Попробуйте чуть позже."
+	MsgErrRefresh         = "Не удалось проверить ссылки"
+	MsgRefreshBroken      = "Ссылка отключена из-за ошибок. Включить её снова можно командой /enable ссылка."
+	MsgEnabled            = "Снова отслеживаю!"
+	MsgErrEnableLink      = "Произошла ошибка при включении ссылки"
+	MsgUnknownListSort    = "Ссылки можно отсортировать так: /list added|activity|url|provider asc|desc"
+	MsgListExpired        = "Список устарел. Отправьте /list, чтобы получить его заново."
+	MsgTagUsage           = "Теги ссылки меняются так: /tag ссылка +новый -старый"
+	MsgFilterUsage        = "Фильтры ссылки меняются так: /filter ссылка +новый -старый"
+	MsgLinkUpdated        = "Обновил!\nТеги: %s\nФильтры: %s"
+	MsgNoLabels           = "нет"
+	MsgErrUpdateLink      = "Произошла ошибка при изменении ссылки"
+	BtnPrevPage           = "« Назад"
+	BtnNextPage           = "Вперёд »"
+)
+
+const (
+	MsgExportUsage         = "Ссылки выгружаются так: /export json|csv|opml"
+	MsgExportCaption       = "Отслеживаемые ссылки"
+	MsgErrExport           = "Не удалось выгрузить ссылки"
+	MsgImportUsage         = "Пришлите файл .json, .csv или .opml, например из /export. Перед импортом я покажу, что добавлю."
+	MsgImportUnknownFormat = "Я читаю только файлы .json, .csv и .opml"
+	MsgImportTooLarge      = "Файл слишком большой. Пришлите файл меньше 1 МБ."
+	MsgImportBadFile       = "Не удалось прочитать файл. Проверьте его формат."
+	MsgErrImport           = "Произошла ошибка при импорте ссылок"
+	MsgImportPreview       = "Что я сделаю с файлом %s:\n"
+	MsgImportDone          = "Импортировал файл %s:\n"
+	MsgImportSummary       = "Добавлено: %d, пропущено: %d, с ошибками: %d\n"
+	MsgImportMoreRows      = "...и ещё строк: %d\n"
+	MsgImportExpired       = "Импорт устарел. Пришлите файл заново."
+	MsgImportCanceled      = "Импорт отменён"
+	BtnImportConfirm       = "Импортировать"
+	BtnImportCancel        = "Отмена"
+)
+
+const (
+	MsgNoTagsSaved      = "Тегов пока нет"
+	MsgTagsHeader       = "Теги и число ссылок с ними:\n"
+	MsgTagCount         = "%s — %d\n"
+	MsgErrGetTags       = "Не удалось получить список тегов"
+	MsgRenameTagUsage   = "Тег переименовывается так: /renametag старый новый"
+	MsgMergeTagsUsage   = "Теги объединяются так: /mergetags откуда куда"
+	MsgTagRenamed       = "Переименовал! Ссылок с тегом %s: %d"
+	MsgTagsMerged       = "Объединил! Ссылок с тегом %s: %d"
+	MsgTagAlreadyExists = "Такой тег уже есть. Чтобы перенести в него ссылки, отправьте /mergetags откуда куда."
+	MsgMergeTagNotFound = "Нет ссылок с одним из этих тегов"
+	MsgErrRenameTag     = "Не удалось изменить тег"
 )
 
 const MsgHelp = `Я могу сохранять твои ссылки для отслеживания. 
 Если хочешь начать отслеживать изменения по ссылке, отправь мне её в формате /track ссылка.
+Чтобы проверять ссылку с заданным интервалом, отправь /track ссылка every 10m.
 Чтобы прекратить отслеживание ссылки, отправь /untrack ссылка. 
-Чтобы просмотреть все отслеживаемые ссылки, отправь /list,
+Чтобы просмотреть все отслеживаемые ссылки, отправь /list. Их можно отсортировать по дате добавления,
+последней проверке, адресу или сервису: /list added|activity|url|provider, добавив desc для обратного порядка,
 а если хочешь просмотреть ссылки  только с определёнными тегами - отправь /listbytags список тегов через пробел.
 Чтобы удалить тег, воспользуйся командой /deletetag тег.
+Чтобы увидеть свои теги и число ссылок с ними, отправь /tags. Тег можно переименовать командой /renametag старый новый,
+а ссылки одного тега перенести в другой - командой /mergetags откуда куда.
+Чтобы поменять теги ссылки, не теряя её истории, отправь /tag ссылка +новый -старый, а для фильтров - /filter ссылка +новый -старый.
+Чтобы проверить ссылки прямо сейчас, отправь /refresh, а для одной ссылки - /refresh ссылка.
+Если ссылка перестала отслеживаться из-за ошибок, включи её снова командой /enable ссылка.
+Чтобы выгрузить ссылки с тегами и фильтрами в файл, отправь /export json|csv|opml.
+Чтобы добавить ссылки из такого файла, просто пришли его мне: сначала я покажу, что добавлю, а потом спрошу подтверждение.
 `
 
 const MsgHello = "Добро пожаловать! 👾\n\n" + MsgHelp
@@ -0,0 +1,17 @@
+package apitypes
+
+import "time"
+
+// Resource describes a tracked entity as the provider sees it.
+type Resource struct {
+	Title string
+	// LastActivity is the provider's own timestamp of the latest tracked event,
+	// or of the resource creation when there are no events yet.
+	LastActivity time.Time
+}
+
+// Event is a single update of a tracked resource, formatted for subscribers.
+type Event struct {
+	CreatedAt time.Time
+	Message   string
+}
@@ -5,6 +5,27 @@ type LinkUpdate struct {
 	URL         string  `json:"url"`
 	Description string  `json:"description"`
 	TgChatIDs   []int64 `json:"tgChatIds"`
+	// IdempotencyKey identifies the update across retries, so the bot sends it to every chat only once.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// Statuses of an update delivered by the bot.
+const (
+	UpdateDelivered = "delivered"
+	UpdateDuplicate = "duplicate"
+	UpdateInvalid   = "invalid"
+	UpdateFailed    = "failed"
+)
+
+type LinkUpdateResult struct {
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+// BatchUpdateResponse holds the results in the order of the updates in the request.
+type BatchUpdateResponse struct {
+	Results []LinkUpdateResult `json:"results"`
 }
 
 type APIErrorResponse struct {
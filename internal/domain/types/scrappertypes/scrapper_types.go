@@ -1,11 +1,16 @@
 package scrappertypes
 
-import "time"
+import (
+	"go-progira/internal/domain/types/bottypes"
+	"time"
+)
 
 type AddLinkRequest struct {
 	Link    string   `json:"link"`
 	Tags    []string `json:"tags"`
 	Filters []string `json:"filters"`
+	// IntervalSeconds is the check interval requested by the user, zero means adaptive polling.
+	IntervalSeconds int64 `json:"interval_seconds,omitempty"`
 }
 
 type GetLinksByTagsRequest struct {
@@ -16,10 +21,65 @@ type DeleteTagRequest struct {
 	Tag string `json:"tag"`
 }
 
+// TagResponse is a tag of the chat with the number of its links that have it.
+type TagResponse struct {
+	Name  string `json:"name"`
+	Links int    `json:"links"`
+}
+
+type ListTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+	Size int           `json:"size"`
+}
+
+// RenameTagRequest gives Tag a name the chat doesn't use yet.
+type RenameTagRequest struct {
+	Tag     string `json:"tag"`
+	NewName string `json:"new_name"`
+}
+
+// MergeTagsRequest moves the links of Tag to the tag Into, Tag is gone after it.
+type MergeTagsRequest struct {
+	Tag  string `json:"tag"`
+	Into string `json:"into"`
+}
+
 type RemoveLinkRequest struct {
 	Link string `json:"link"`
 }
 
+// RefreshRequest asks to check a link of the chat right now, or all its links if Link is empty.
+type RefreshRequest struct {
+	Link string `json:"link,omitempty"`
+}
+
+type RefreshResponse struct {
+	Checked int      `json:"checked"`
+	Updated []string `json:"updated"`
+	// InProgress is the number of links skipped because they were being checked already.
+	InProgress int `json:"in_progress"`
+	// Broken links are not checked, they have to be enabled first.
+	Broken []string `json:"broken,omitempty"`
+}
+
+// LinkChange lists the tags and filters to add to a tracked link and to remove from it.
+type LinkChange struct {
+	AddTags       []string `json:"add_tags,omitempty"`
+	RemoveTags    []string `json:"remove_tags,omitempty"`
+	AddFilters    []string `json:"add_filters,omitempty"`
+	RemoveFilters []string `json:"remove_filters,omitempty"`
+}
+
+// UpdateLinkRequest changes tags and filters of a tracked link without resetting its history.
+type UpdateLinkRequest struct {
+	Link string `json:"link"`
+	LinkChange
+}
+
+type EnableLinkRequest struct {
+	Link string `json:"link"`
+}
+
 type LinkResponse struct {
 	ID          int64     `json:"id"`
 	URL         string    `json:"url"`
@@ -27,11 +87,116 @@ type LinkResponse struct {
 	Filters     []string  `json:"filters"`
 	LastChecked time.Time `json:"last_checked"`
 	LastVersion string    `json:"last_version"`
+	Title       string    `json:"title,omitempty"`
+	// AddedAt is when the chat started tracking the link.
+	AddedAt time.Time `json:"added_at"`
+	// Broken links are not checked until a subscriber enables them again.
+	Broken              bool       `json:"broken,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	// CheckInterval and UserInterval are used only by the scheduler of scrapper.
+	CheckInterval time.Duration `json:"-"`
+	UserInterval  time.Duration `json:"-"`
 }
 
 type ListLinksResponse struct {
 	Links []LinkResponse `json:"links"`
 	Size  int            `json:"size"`
+	// NextCursor and PrevCursor select the neighbouring pages, they are empty at the ends of the list.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Orders the links of a chat can be listed in.
+const (
+	SortAdded    = "added"
+	SortActivity = "activity"
+	SortURL      = "url"
+	SortProvider = "provider"
+)
+
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// GetLinksRequest asks for a page of the links of a chat. Zero Limit asks for all links.
+type GetLinksRequest struct {
+	Sort   string
+	Order  string
+	Cursor string
+	Limit  int
+}
+
+// LinksQuery selects a page of the links of a chat in the storage. Zero Limit selects all links.
+type LinksQuery struct {
+	Sort   string
+	Desc   bool
+	Cursor *LinksCursor
+	Limit  int
+}
+
+// LinksCursor points at the link the page starts after, or ends before if Backward is set.
+// Key is the value of the link the links are sorted by, ID breaks the ties.
+type LinksCursor struct {
+	Sort     string `json:"s"`
+	Desc     bool   `json:"d,omitempty"`
+	Key      string `json:"k"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// LinksPage is a page of the links of a chat with the cursors of the neighbouring pages.
+type LinksPage struct {
+	Links []LinkResponse
+	Next  *LinksCursor
+	Prev  *LinksCursor
+}
+
+// Formats the links of a chat are exported to and imported from.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatOPML = "opml"
+)
+
+// ExportedLink is a link as it is written to an export file.
+type ExportedLink struct {
+	URL     string   `json:"url"`
+	Tags    []string `json:"tags,omitempty"`
+	Filters []string `json:"filters,omitempty"`
+}
+
+// ImportRequest asks to add the links of a file in Format to the chat.
+// DryRun only checks the rows, so the user can preview what would be added.
+type ImportRequest struct {
+	Format string `json:"format"`
+	Data   string `json:"data"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// What happened to a row of an imported file.
+const (
+	ImportAdded   = "added"
+	ImportSkipped = "skipped"
+	ImportInvalid = "invalid"
+)
+
+// ImportRow reports the link of a row, counted from 1, of an imported file.
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Link   string `json:"link"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ImportResponse struct {
+	Rows    []ImportRow `json:"rows"`
+	Added   int         `json:"added"`
+	Skipped int         `json:"skipped"`
+	Invalid int         `json:"invalid"`
+	DryRun  bool        `json:"dry_run,omitempty"`
 }
 
 type APIErrorResponse struct {
@@ -42,6 +207,38 @@ type APIErrorResponse struct {
 	Stacktrace       []string `json:"stacktrace,omitempty"`
 }
 
+const (
+	CodeResourceNotFound    = "RESOURCE_NOT_FOUND"
+	CodeResourcePrivate     = "RESOURCE_PRIVATE"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+	CodeRefreshTooOften     = "REFRESH_TOO_OFTEN"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeInvalidRequest      = "INVALID_REQUEST"
+	CodeInvalidLink         = "INVALID_LINK"
+	CodeChatNotFound        = "CHAT_NOT_FOUND"
+	CodeChatAlreadyExists   = "CHAT_ALREADY_EXISTS"
+	CodeLinkNotFound        = "LINK_NOT_FOUND"
+	CodeLinkAlreadyExists   = "LINK_ALREADY_EXISTS"
+	CodeTagNotFound         = "TAG_NOT_FOUND"
+	CodeTagAlreadyExists    = "TAG_ALREADY_EXISTS"
+	CodeInternal            = "INTERNAL_ERROR"
+	CodeMethodNotAllowed    = "METHOD_NOT_ALLOWED"
+	CodeFallbackDisabled    = "FALLBACK_DISABLED"
+)
+
+// Notification is an update for the bot waiting in the outbox.
+type Notification struct {
+	ID       int64
+	Update   bottypes.LinkUpdate
+	Attempts int
+}
+
+type Subscriber struct {
+	TgChatID     int64
+	SubscribedAt time.Time
+}
+
 type Chat struct {
 	ID    int64          `json:"id"`
 	Links []LinkResponse `json:"links"`
@@ -5,15 +5,49 @@ type UpdatesResponse struct {
 	Result []Update `json:"result"`
 }
 
+// Response is the part of every answer of the Bot API telling whether the request succeeded.
+type Response struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
 type Update struct {
-	ID      int      `json:"update_id"`
-	Message *Message `json:"message"`
+	ID            int            `json:"update_id"`
+	Message       *Message       `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
 }
 
 type Message struct {
-	Text string `json:"text"`
-	From From   `json:"from"`
-	Chat Chat   `json:"chat"`
+	MessageID int       `json:"message_id"`
+	Text      string    `json:"text"`
+	From      From      `json:"from"`
+	Chat      Chat      `json:"chat"`
+	Document  *Document `json:"document"`
+}
+
+// Document is a file sent to the bot, its content is downloaded by FileID.
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+	FileSize int    `json:"file_size"`
+}
+
+// FileResponse is the answer of getFile, FilePath is where the file is downloaded from.
+type FileResponse struct {
+	Ok     bool `json:"ok"`
+	Result struct {
+		FilePath string `json:"file_path"`
+	} `json:"result"`
+}
+
+// CallbackQuery is sent when a button of an inline keyboard is pressed.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    From     `json:"from"`
+	Message *Message `json:"message"`
+	Data    string   `json:"data"`
 }
 
 type From struct {
@@ -28,3 +62,12 @@ type BotCommand struct {
 	Command     string `json:"command"`
 	Description string `json:"description"`
 }
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
@@ -1,8 +1,10 @@
 package formatter
 
 import (
+	"errors"
 	"fmt"
 	"go-progira/internal/domain/types/apitypes"
+	"go-progira/pkg/e"
 	"strings"
 	"time"
 )
@@ -68,3 +70,19 @@ func FormatMessageForGithub(updates []apitypes.GithubUpdate) string {
 
 	return content.String()
 }
+
+func FormatBrokenLinkMessage(link string, cause error) string {
+	var reason string
+
+	switch {
+	case errors.Is(cause, e.ErrResourceNotFound):
+		reason = "ресурс не найден, возможно, он был удалён"
+	case errors.Is(cause, e.ErrResourcePrivate):
+		reason = "нет доступа к ресурсу"
+	default:
+		reason = "проверка много раз подряд заканчивалась ошибкой"
+	}
+
+	return fmt.Sprintf("Перестал отслеживать ссылку %s: %s.\n"+
+		"Чтобы снова включить отслеживание, отправь /enable %s", link, reason, link)
+}
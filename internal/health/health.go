@@ -0,0 +1,150 @@
+// Package health serves the liveness and readiness probes of the scrapper and the bot.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/pkg/e"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+var errNotReady = errors.New("service is starting or shutting down")
+
+// Check tells whether a dependency of the service is usable.
+type Check func(ctx context.Context) error
+
+// Report is the body of the probe responses. Checks holds "ok" or the error of every check.
+type Report struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Probes answers /healthz as long as the process serves requests, and /readyz only while
+// the service is marked ready and all of its checks pass.
+type Probes struct {
+	ready atomic.Bool
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+func NewProbes() *Probes {
+	return &Probes{checks: make(map[string]Check)}
+}
+
+func (p *Probes) AddCheck(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checks[name] = check
+}
+
+// SetReady is called with true once the service has started and with false when it starts shutting down.
+func (p *Probes) SetReady(ready bool) {
+	p.ready.Store(ready)
+}
+
+func (p *Probes) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", p.Liveness)
+	mux.HandleFunc("/readyz", p.Readiness)
+}
+
+func (p *Probes) Liveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	writeReport(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+func (p *Probes) Readiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if !p.ready.Load() {
+		writeReport(w, http.StatusServiceUnavailable, Report{Status: StatusUnavailable, Error: errNotReady.Error()})
+
+		return
+	}
+
+	report := p.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, report)
+}
+
+// Run runs the checks concurrently, each of them is given checkTimeout.
+func (p *Probes) Run(ctx context.Context) Report {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]string, len(p.checks))}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for name, check := range p.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			result := StatusOK
+			if err := check(checkCtx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error(
+			e.ErrEncodeToJSON.Error(),
+			slog.String("error", err.Error()),
+		)
+	}
+}
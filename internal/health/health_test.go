@@ -0,0 +1,100 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-progira/internal/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbes(t *testing.T) {
+	failing := func(_ context.Context) error { return errors.New("connection refused") }
+	passing := func(_ context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		ready      bool
+		checks     map[string]health.Check
+		path       string
+		wantStatus int
+		wantReport health.Report
+	}{
+		{
+			name:       "liveness does not run checks",
+			checks:     map[string]health.Check{"database": failing},
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			wantReport: health.Report{Status: health.StatusOK},
+		},
+		{
+			name:       "not ready while starting",
+			checks:     map[string]health.Check{"database": passing},
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: health.Report{Status: health.StatusUnavailable, Error: "service is starting or shutting down"},
+		},
+		{
+			name:       "ready when all checks pass",
+			ready:      true,
+			checks:     map[string]health.Check{"database": passing, "scheduler": passing},
+			path:       "/readyz",
+			wantStatus: http.StatusOK,
+			wantReport: health.Report{Status: health.StatusOK, Checks: map[string]string{"database": "ok", "scheduler": "ok"}},
+		},
+		{
+			name:       "not ready when a check fails",
+			ready:      true,
+			checks:     map[string]health.Check{"database": failing, "scheduler": passing},
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: health.Report{
+				Status: health.StatusUnavailable,
+				Checks: map[string]string{"database": "connection refused", "scheduler": "ok"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := health.NewProbes()
+			probes.SetReady(tt.ready)
+
+			for name, check := range tt.checks {
+				probes.AddCheck(name, check)
+			}
+
+			mux := http.NewServeMux()
+			probes.Register(mux)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var report health.Report
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+			assert.Equal(t, tt.wantReport, report)
+		})
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var heartbeat health.Heartbeat
+
+	check := heartbeat.Check(time.Minute)
+
+	assert.Error(t, check(context.Background()))
+
+	heartbeat.Beat(time.Now().Add(-2 * time.Minute))
+	assert.Error(t, check(context.Background()))
+
+	heartbeat.Beat(time.Now())
+	assert.NoError(t, check(context.Background()))
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat remembers when a recurring job last succeeded. The zero value has never beaten.
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+func (h *Heartbeat) Beat(now time.Time) {
+	if h == nil {
+		return
+	}
+
+	h.last.Store(now.UnixNano())
+}
+
+func (h *Heartbeat) Last() time.Time {
+	if h == nil || h.last.Load() == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, h.last.Load())
+}
+
+// Check fails when the job has not succeeded yet or its last success is older than maxAge.
+func (h *Heartbeat) Check(maxAge time.Duration) Check {
+	return func(_ context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			return fmt.Errorf("no successful run yet")
+		}
+
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last successful run %s ago", age.Truncate(time.Second))
+		}
+
+		return nil
+	}
+}
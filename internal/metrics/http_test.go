@@ -0,0 +1,44 @@
+package metrics_test
+
+import (
+	"go-progira/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tg-chat/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /links", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/metrics", metrics.Handler())
+
+	handler := metrics.Middleware(mux)
+
+	for _, path := range []string{"/tg-chat/1", "/tg-chat/2", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/links", nil))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/tg-chat/{id}",status="404"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/links",status="200"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/tg-chat/{id}"} 2`)
+	assert.False(t, strings.Contains(body, `route="/tg-chat/1"`), "paths must not be used as labels")
+}
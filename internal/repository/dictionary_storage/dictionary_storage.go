@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"go-progira/internal/domain/types/bottypes"
 	"go-progira/internal/domain/types/scrappertypes"
 	"go-progira/pkg/e"
 	"log/slog"
@@ -16,24 +17,54 @@ type ChatStorage interface {
 }
 
 type LinkStorage interface {
-	AddLink(ctx context.Context, id int64, url string, tags, filters []string) error
+	AddLink(ctx context.Context, id int64, url string, tags, filters []string, lastActivity time.Time) error
 	RemoveLink(ctx context.Context, id int64, link string) error
-	GetLinks(ctx context.Context, id int64) ([]scrappertypes.LinkResponse, error)
+	// GetLinks selects the page of query from the links of the chat.
+	GetLinks(ctx context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error)
 	IsURLInAdded(ctx context.Context, id int64, u string) bool
-	GetBatchOfLinks(context.Context, int, int64) ([]scrappertypes.LinkResponse, int64)
+	ClaimLinks(ctx context.Context, owner string, batch int, lease time.Duration) []scrappertypes.LinkResponse
+	ClaimLink(ctx context.Context, owner string, linkID int64, lease time.Duration) (scrappertypes.LinkResponse, bool)
 	DeleteTag(ctx context.Context, id int64, tag string) error
+	// ListTags returns the tags of the chat with the number of links that have each of them, the most used first.
+	ListTags(ctx context.Context, id int64) ([]scrappertypes.TagResponse, error)
+	// RenameTag moves the links of the chat from tag to newName. The chat must not use newName yet,
+	// unless merge is set: then it must use it already.
+	RenameTag(ctx context.Context, id int64, tag, newName string, merge bool) (scrappertypes.TagResponse, error)
+	SetCheckInterval(ctx context.Context, id int64, link string, interval time.Duration) error
+	EnableLink(ctx context.Context, id int64, link string) error
+	// UpdateLink applies change to the link of the chat and returns its tags and filters after it.
+	UpdateLink(ctx context.Context, id int64, link string, change scrappertypes.LinkChange) (scrappertypes.LinkResponse, error)
 }
 
 type UpdateStorage interface {
 	GetPreviousUpdate(ctx context.Context, ID int64) time.Time
 	SaveLastUpdate(ctx context.Context, ID int64, updTime time.Time) error
 	GetTgChatIDsForLink(ctx context.Context, link string) []int64
+	GetSubscribers(ctx context.Context, linkID int64) []scrappertypes.Subscriber
+	ReleaseLink(ctx context.Context, owner string, linkID int64, interval time.Duration) error
+	// ExtendLease gives owner lease more time to check the link it has claimed.
+	ExtendLease(ctx context.Context, owner string, linkID int64, lease time.Duration) error
+	SaveCheckSuccess(ctx context.Context, linkID int64) error
+	SaveCheckFailure(ctx context.Context, linkID int64, checkErr string, disable bool, threshold int) (bool, error)
+}
+
+// OutboxStorage keeps notifications for the bot until they are delivered.
+type OutboxStorage interface {
+	SaveUpdateWithNotifications(ctx context.Context, linkID int64, updTime time.Time, updates []bottypes.LinkUpdate) error
+	AddNotifications(ctx context.Context, updates []bottypes.LinkUpdate) error
+	ClaimNotifications(ctx context.Context, batch int, lease time.Duration) []scrappertypes.Notification
+	MarkNotificationDelivered(ctx context.Context, id int64) error
+	// MarkNotificationFailed schedules the next attempt to send the notification backoff after now on the database clock.
+	MarkNotificationFailed(ctx context.Context, id int64, deliveryErr string, backoff time.Duration, dead bool) error
 }
 
 type LinkService interface {
 	ChatStorage
 	LinkStorage
 	UpdateStorage
+	OutboxStorage
+	Ping(ctx context.Context) error
+	Close()
 }
 
 type DictionaryStorage struct {
@@ -73,7 +104,7 @@ func (d *DictionaryStorage) DeleteChat(_ context.Context, id int64) error {
 	return nil
 }
 
-func (d *DictionaryStorage) GetLinks(_ context.Context, id int64) ([]scrappertypes.LinkResponse, error) {
+func (d *DictionaryStorage) GetLinks(_ context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error) {
 	d.mutex.RLock()
 
 	defer d.mutex.RUnlock()
@@ -83,10 +114,10 @@ func (d *DictionaryStorage) GetLinks(_ context.Context, id int64) ([]scrappertyp
 		slog.Error(e.ErrChatNotFound.Error())
 		slog.String("id", strconv.FormatInt(id, 10))
 
-		return nil, e.ErrChatNotFound
+		return scrappertypes.LinksPage{}, e.ErrChatNotFound
 	}
 
-	return chat.Links, nil
+	return PaginateLinks(chat.Links, query), nil
 }
 
 func (d *DictionaryStorage) AddLink(ctx context.Context, id int64, url string, tags, filters []string, content string) error {
@@ -107,6 +138,7 @@ func (d *DictionaryStorage) AddLink(ctx context.Context, id int64, url string, t
 		Filters:     filters,
 		LastVersion: content,
 		LastChecked: time.Now(),
+		AddedAt:     time.Now(),
 	}
 
 	return d.AppendLinkToLinks(ctx, id, link)
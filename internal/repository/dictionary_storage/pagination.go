@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"cmp"
+	"go-progira/internal/domain/types/scrappertypes"
+	"slices"
+	"strings"
+)
+
+// keyTimeLayout keeps the precision of the database and sorts like the time it formats.
+const keyTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// SortKey returns the value of link the links are sorted by.
+func SortKey(link *scrappertypes.LinkResponse, sort string) string {
+	switch sort {
+	case scrappertypes.SortActivity:
+		return link.LastChecked.UTC().Format(keyTimeLayout)
+	case scrappertypes.SortURL:
+		return link.URL
+	case scrappertypes.SortProvider:
+		return Provider(link.URL)
+	default:
+		return link.AddedAt.UTC().Format(keyTimeLayout)
+	}
+}
+
+// Provider returns the host of link, like the database does for sorting.
+func Provider(link string) string {
+	_, rest, ok := strings.Cut(link, "://")
+	if !ok {
+		return ""
+	}
+
+	host, _, _ := strings.Cut(rest, "/")
+
+	return host
+}
+
+// Ascending tells whether the storage selects the links of query in ascending order.
+// Links before a cursor are selected from the cursor backwards.
+func Ascending(query scrappertypes.LinksQuery) bool {
+	backward := query.Cursor != nil && query.Cursor.Backward
+
+	return query.Desc == backward
+}
+
+// NewLinksPage makes the page of query from the links the storage selected after the cursor
+// in the order of Ascending, with one link more than the limit if there are more of them.
+func NewLinksPage(links []scrappertypes.LinkResponse, query scrappertypes.LinksQuery) scrappertypes.LinksPage {
+	if query.Limit <= 0 {
+		return scrappertypes.LinksPage{Links: links}
+	}
+
+	backward := query.Cursor != nil && query.Cursor.Backward
+	more := len(links) > query.Limit
+
+	if more {
+		links = links[:query.Limit]
+	}
+
+	if backward {
+		slices.Reverse(links)
+	}
+
+	page := scrappertypes.LinksPage{Links: links}
+	if len(links) == 0 {
+		return page
+	}
+
+	if more || backward {
+		page.Next = cursorAt(&links[len(links)-1], query, false)
+	}
+
+	if more && backward || !backward && query.Cursor != nil {
+		page.Prev = cursorAt(&links[0], query, true)
+	}
+
+	return page
+}
+
+// PaginateLinks selects the page of query from all links of a chat.
+func PaginateLinks(links []scrappertypes.LinkResponse, query scrappertypes.LinksQuery) scrappertypes.LinksPage {
+	sorted := slices.Clone(links)
+	ascending := Ascending(query)
+
+	slices.SortFunc(sorted, func(a, b scrappertypes.LinkResponse) int {
+		order := compareLink(&a, SortKey(&b, query.Sort), b.ID, query.Sort)
+		if !ascending {
+			return -order
+		}
+
+		return order
+	})
+
+	if query.Cursor != nil {
+		sorted = slices.DeleteFunc(sorted, func(link scrappertypes.LinkResponse) bool {
+			order := compareLink(&link, query.Cursor.Key, query.Cursor.ID, query.Sort)
+
+			return ascending && order <= 0 || !ascending && order >= 0
+		})
+	}
+
+	if query.Limit > 0 && len(sorted) > query.Limit+1 {
+		sorted = sorted[:query.Limit+1]
+	}
+
+	return NewLinksPage(sorted, query)
+}
+
+func compareLink(link *scrappertypes.LinkResponse, key string, id int64, sort string) int {
+	return cmp.Or(strings.Compare(SortKey(link, sort), key), cmp.Compare(link.ID, id))
+}
+
+func cursorAt(link *scrappertypes.LinkResponse, query scrappertypes.LinksQuery, backward bool) *scrappertypes.LinksCursor {
+	return &scrappertypes.LinksCursor{
+		Sort:     query.Sort,
+		Desc:     query.Desc,
+		Key:      SortKey(link, query.Sort),
+		ID:       link.ID,
+		Backward: backward,
+	}
+}
@@ -8,4 +8,5 @@ var (
 	ErrDeleteChat = errors.New("error deleting chat")
 	ErrExecQuery  = errors.New("error executing query")
 	ErrRemoveLink = errors.New("error removing link")
+	ErrScanRow    = errors.New("error scanning row")
 )
@@ -3,7 +3,10 @@ package repository_test
 import (
 	"context"
 	"fmt"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
 	repository "go-progira/internal/repository/sql_database"
+	"go-progira/pkg/e"
 	"log/slog"
 	"testing"
 	"time"
@@ -73,12 +76,14 @@ func TestNewLinkService(t *testing.T) {
 		svc, err := repository.NewLinkService("orm", dbURL)
 		assert.NoError(t, err)
 		assert.IsType(t, &repository.ORMLinkService{}, svc)
+		assert.NoError(t, svc.Ping(context.Background()))
 	})
 
 	t.Run("returns SQLLinkService", func(t *testing.T) {
 		svc, err := repository.NewLinkService("sql", dbURL)
 		assert.NoError(t, err)
 		assert.IsType(t, &repository.SQLLinkService{}, svc)
+		assert.NoError(t, svc.Ping(context.Background()))
 	})
 
 	t.Run("invalid type returns error", func(t *testing.T) {
@@ -211,6 +216,7 @@ func TestAddLink(t *testing.T) {
 	CREATE TABLE IF NOT EXISTS link_users (
 		user_id INT REFERENCES users(id),
 		link_id INT REFERENCES links(id),
+		subscribed_at TIMESTAMP NOT NULL DEFAULT now(),
 		UNIQUE(user_id, link_id)
 	);
 	CREATE TABLE IF NOT EXISTS tags (
@@ -259,17 +265,27 @@ func TestAddLink(t *testing.T) {
 			_, err = db.Exec(ctx, `INSERT INTO users (telegram_id) VALUES ($1) ON CONFLICT DO NOTHING`, telegramID)
 			require.NoError(t, err)
 
-			url := "https://example.com"
+			url := "https://example.com/" + tt.typ
 			tags := []string{"tag1", "tag2"}
 			filters := []string{"filter1", "filter2"}
 
-			err = svc.AddLink(ctx, telegramID, url, tags, filters)
+			lastActivity := time.Date(2025, time.May, 5, 18, 24, 0, 0, time.UTC)
+
+			err = svc.AddLink(ctx, telegramID, url, tags, filters, lastActivity)
 			require.NoError(t, err)
 
 			var linkID int
-			err = db.QueryRow(ctx, `SELECT id FROM links WHERE url = $1`, url).Scan(&linkID)
+
+			var changedAt time.Time
+			err = db.QueryRow(ctx, `SELECT id, changed_at FROM links WHERE url = $1`, url).Scan(&linkID, &changedAt)
 			assert.NoError(t, err)
 			assert.Greater(t, linkID, 0)
+			assert.Equal(t, lastActivity, changedAt)
+
+			subscribers := svc.GetSubscribers(ctx, int64(linkID))
+			require.Len(t, subscribers, 1)
+			assert.Equal(t, telegramID, subscribers[0].TgChatID)
+			assert.Equal(t, lastActivity, subscribers[0].SubscribedAt)
 
 			var count int
 			err = db.QueryRow(ctx, `
@@ -459,3 +475,670 @@ func TestDeleteTag(t *testing.T) {
 		})
 	}
 }
+
+func TestClaimLinks(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now(),
+			check_interval_seconds INT NOT NULL DEFAULT 120,
+			next_check_at TIMESTAMP NOT NULL DEFAULT now(),
+			locked_until TIMESTAMP,
+			locked_by TEXT,
+			broken BOOLEAN NOT NULL DEFAULT false
+		);
+		CREATE TABLE IF NOT EXISTS link_users (
+			user_id INT,
+			link_id BIGINT REFERENCES links(id),
+			user_interval_seconds INT
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE link_users, links")
+			require.NoError(t, err)
+
+			var dueID, userIntervalID int64
+
+			err = db.QueryRow(ctx, `INSERT INTO links (url, next_check_at) VALUES ('https://due.com', now() - interval '1 minute')
+									RETURNING id`).Scan(&dueID)
+			require.NoError(t, err)
+
+			err = db.QueryRow(ctx, `INSERT INTO links (url, next_check_at) VALUES ('https://user.com', now())
+									RETURNING id`).Scan(&userIntervalID)
+			require.NoError(t, err)
+
+			_, err = db.Exec(ctx, `INSERT INTO link_users (user_id, link_id, user_interval_seconds)
+									VALUES (1, $1, 600), (2, $1, 300)`, userIntervalID)
+			require.NoError(t, err)
+
+			_, err = db.Exec(ctx, `INSERT INTO links (url, next_check_at) VALUES ('https://later.com', now() + interval '1 hour')`)
+			require.NoError(t, err)
+
+			var expiredID int64
+
+			err = db.QueryRow(ctx, `INSERT INTO links (url, next_check_at, locked_until, locked_by)
+									VALUES ('https://expired.com', now() - interval '1 hour', now() - interval '1 minute', 'dead')
+									RETURNING id`).Scan(&expiredID)
+			require.NoError(t, err)
+
+			_, err = db.Exec(ctx, `INSERT INTO links (url, next_check_at, locked_until, locked_by)
+									VALUES ('https://leased.com', now() - interval '1 hour', now() + interval '1 hour', 'other')`)
+			require.NoError(t, err)
+
+			links := svc.ClaimLinks(ctx, "first", 2, time.Minute)
+			require.Len(t, links, 2)
+			assert.ElementsMatch(t, []int64{expiredID, dueID}, []int64{links[0].ID, links[1].ID},
+				"link of a dead instance must be reclaimed")
+			assert.Equal(t, 2*time.Minute, links[0].CheckInterval)
+
+			links = svc.ClaimLinks(ctx, "second", 10, time.Minute)
+			require.Len(t, links, 1, "links leased by other instances must be skipped")
+			assert.Equal(t, userIntervalID, links[0].ID)
+			assert.Equal(t, 5*time.Minute, links[0].UserInterval)
+
+			err = svc.ReleaseLink(ctx, "second", dueID, time.Hour)
+			assert.ErrorIs(t, err, e.ErrLeaseLost)
+
+			err = svc.ExtendLease(ctx, "second", dueID, time.Hour)
+			assert.ErrorIs(t, err, e.ErrLeaseLost)
+
+			err = svc.ExtendLease(ctx, "first", dueID, time.Hour)
+			require.NoError(t, err)
+
+			var extended bool
+
+			err = db.QueryRow(ctx, `SELECT locked_until > now() + interval '30 minutes' FROM links WHERE id = $1`, dueID).Scan(&extended)
+			require.NoError(t, err)
+			assert.True(t, extended, "lease must be extended")
+
+			err = svc.ReleaseLink(ctx, "first", dueID, time.Hour)
+			require.NoError(t, err)
+
+			var lockedBy *string
+
+			err = db.QueryRow(ctx, `SELECT locked_by FROM links WHERE id = $1`, dueID).Scan(&lockedBy)
+			require.NoError(t, err)
+			assert.Nil(t, lockedBy)
+
+			links = svc.ClaimLinks(ctx, "first", 10, time.Minute)
+			assert.Empty(t, links, "released link must wait for its next check")
+		})
+	}
+}
+
+func TestLinkHealth(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS users (
+			id SERIAL PRIMARY KEY,
+			telegram_id BIGINT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now(),
+			next_check_at TIMESTAMP NOT NULL DEFAULT now(),
+			consecutive_failures INT NOT NULL DEFAULT 0,
+			last_error TEXT,
+			last_success_at TIMESTAMP,
+			broken BOOLEAN NOT NULL DEFAULT false
+		);
+		CREATE TABLE IF NOT EXISTS link_users (
+			user_id INT REFERENCES users(id),
+			link_id BIGINT REFERENCES links(id),
+			added_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE link_users, links, users")
+			require.NoError(t, err)
+
+			var userID int
+
+			var linkID int64
+
+			err = db.QueryRow(ctx, `INSERT INTO users (telegram_id) VALUES (42) RETURNING id`).Scan(&userID)
+			require.NoError(t, err)
+
+			err = db.QueryRow(ctx, `INSERT INTO links (url) VALUES ('https://example.com') RETURNING id`).Scan(&linkID)
+			require.NoError(t, err)
+
+			_, err = db.Exec(ctx, `INSERT INTO link_users (user_id, link_id) VALUES ($1, $2)`, userID, linkID)
+			require.NoError(t, err)
+
+			broken, err := svc.SaveCheckFailure(ctx, linkID, "status 500", false, 2)
+			require.NoError(t, err)
+			assert.False(t, broken, "link must not be broken before the threshold")
+
+			broken, err = svc.SaveCheckFailure(ctx, linkID, "status 502", false, 2)
+			require.NoError(t, err)
+			assert.True(t, broken, "link must become broken at the threshold")
+
+			broken, err = svc.SaveCheckFailure(ctx, linkID, "status 502", false, 2)
+			require.NoError(t, err)
+			assert.False(t, broken, "already broken link must not be reported again")
+
+			page, err := svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{})
+			require.NoError(t, err)
+
+			links := page.Links
+			require.Len(t, links, 1)
+			assert.True(t, links[0].Broken)
+			assert.Equal(t, 3, links[0].ConsecutiveFailures)
+			assert.Equal(t, "status 502", links[0].LastError)
+
+			err = svc.EnableLink(ctx, 43, "https://example.com")
+			assert.ErrorIs(t, err, e.ErrLinkNotFound, "chat must be subscribed to the link")
+
+			err = svc.EnableLink(ctx, 42, "https://example.com")
+			require.NoError(t, err)
+
+			err = svc.SaveCheckSuccess(ctx, linkID)
+			require.NoError(t, err)
+
+			page, err = svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{})
+			require.NoError(t, err)
+
+			links = page.Links
+			require.Len(t, links, 1)
+			assert.False(t, links[0].Broken)
+			assert.Zero(t, links[0].ConsecutiveFailures)
+			assert.Empty(t, links[0].LastError)
+			assert.NotNil(t, links[0].LastSuccessAt)
+
+			broken, err = svc.SaveCheckFailure(ctx, linkID, "resource not found", true, 5)
+			require.NoError(t, err)
+			assert.True(t, broken, "gone link must be disabled at once")
+		})
+	}
+}
+
+func TestGetLinksPages(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS users (
+			id SERIAL PRIMARY KEY,
+			telegram_id BIGINT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now(),
+			consecutive_failures INT NOT NULL DEFAULT 0,
+			last_error TEXT,
+			last_success_at TIMESTAMP,
+			broken BOOLEAN NOT NULL DEFAULT false
+		);
+		CREATE TABLE IF NOT EXISTS link_users (
+			user_id INT REFERENCES users(id),
+			link_id BIGINT REFERENCES links(id),
+			added_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	urls := func(page scrappertypes.LinksPage) []string {
+		result := make([]string, 0, len(page.Links))
+		for _, link := range page.Links {
+			result = append(result, link.URL)
+		}
+
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE link_users, links, users")
+			require.NoError(t, err)
+
+			var userID int
+
+			err = db.QueryRow(ctx, `INSERT INTO users (telegram_id) VALUES (42) RETURNING id`).Scan(&userID)
+			require.NoError(t, err)
+
+			_, err = db.Exec(ctx, `
+				WITH added AS (
+					INSERT INTO links (url, changed_at) VALUES
+						('https://stackoverflow.com/questions/1', '2025-01-03'),
+						('https://github.com/a/b', '2025-01-01'),
+						('https://github.com/a/c', '2025-01-02')
+					RETURNING id, url
+				)
+				INSERT INTO link_users (user_id, link_id, added_at)
+				SELECT $1, id, '2025-02-01'::timestamp + id * interval '1 hour' FROM added`, userID)
+			require.NoError(t, err)
+
+			first, err := svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{Sort: scrappertypes.SortURL, Limit: 2})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"https://github.com/a/b", "https://github.com/a/c"}, urls(first))
+			assert.Nil(t, first.Prev)
+			require.NotNil(t, first.Next)
+
+			second, err := svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{Sort: scrappertypes.SortURL, Limit: 2, Cursor: first.Next})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"https://stackoverflow.com/questions/1"}, urls(second))
+			assert.Nil(t, second.Next)
+			require.NotNil(t, second.Prev)
+
+			back, err := svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{Sort: scrappertypes.SortURL, Limit: 2, Cursor: second.Prev})
+			require.NoError(t, err)
+			assert.Equal(t, urls(first), urls(back))
+			assert.Nil(t, back.Prev)
+
+			query := scrappertypes.LinksQuery{Sort: scrappertypes.SortActivity, Desc: true, Limit: 1}
+
+			latest, err := svc.GetLinks(ctx, 42, query)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"https://stackoverflow.com/questions/1"}, urls(latest))
+
+			query.Cursor = latest.Next
+
+			older, err := svc.GetLinks(ctx, 42, query)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"https://github.com/a/c"}, urls(older))
+
+			byAdded, err := svc.GetLinks(ctx, 42, scrappertypes.LinksQuery{Sort: scrappertypes.SortAdded})
+			require.NoError(t, err)
+			assert.Len(t, byAdded.Links, 3)
+			assert.True(t, byAdded.Links[0].AddedAt.Before(byAdded.Links[2].AddedAt))
+		})
+	}
+}
+
+func TestUpdateLink(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS users (
+			id SERIAL PRIMARY KEY,
+			telegram_id BIGINT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now()
+		);
+		CREATE TABLE IF NOT EXISTS link_users (
+			user_id INT REFERENCES users(id) ON DELETE CASCADE,
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			subscribed_at TIMESTAMP,
+			PRIMARY KEY (user_id, link_id)
+		);
+		CREATE TABLE IF NOT EXISTS tags (
+			id SERIAL PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS filters (
+			id SERIAL PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS link_tags (
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			tag_id INT REFERENCES tags(id) ON DELETE CASCADE,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+			PRIMARY KEY (link_id, tag_id, user_id),
+			FOREIGN KEY (link_id, user_id) REFERENCES link_users(link_id, user_id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS link_filters (
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			filter_id INT REFERENCES filters(id) ON DELETE CASCADE,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+			PRIMARY KEY (link_id, filter_id, user_id),
+			FOREIGN KEY (link_id, user_id) REFERENCES link_users(link_id, user_id) ON DELETE CASCADE
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE link_tags, link_filters, link_users, links, users")
+			require.NoError(t, err)
+
+			const (
+				tgID = int64(42)
+				url  = "https://github.com/a/b"
+			)
+
+			require.NoError(t, svc.CreateChat(ctx, tgID))
+			require.NoError(t, svc.AddLink(ctx, tgID, url, []string{"work", "old"}, []string{"user=a"}, time.Now()))
+
+			link, err := svc.UpdateLink(ctx, tgID, url, scrappertypes.LinkChange{
+				AddTags:       []string{"go"},
+				RemoveTags:    []string{"old"},
+				RemoveFilters: []string{"user=a"},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, url, link.URL)
+			assert.ElementsMatch(t, []string{"work", "go"}, link.Tags)
+			assert.Empty(t, link.Filters)
+
+			_, err = svc.UpdateLink(ctx, tgID, "https://github.com/a/c", scrappertypes.LinkChange{AddTags: []string{"go"}})
+			assert.ErrorIs(t, err, e.ErrLinkNotFound)
+		})
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS users (
+			id SERIAL PRIMARY KEY,
+			telegram_id BIGINT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now()
+		);
+		CREATE TABLE IF NOT EXISTS link_users (
+			user_id INT REFERENCES users(id) ON DELETE CASCADE,
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			subscribed_at TIMESTAMP,
+			PRIMARY KEY (user_id, link_id)
+		);
+		CREATE TABLE IF NOT EXISTS tags (
+			id SERIAL PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS filters (
+			id SERIAL PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS link_tags (
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			tag_id INT REFERENCES tags(id) ON DELETE CASCADE,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+			PRIMARY KEY (link_id, tag_id, user_id),
+			FOREIGN KEY (link_id, user_id) REFERENCES link_users(link_id, user_id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS link_filters (
+			link_id BIGINT REFERENCES links(id) ON DELETE CASCADE,
+			filter_id INT REFERENCES filters(id) ON DELETE CASCADE,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+			PRIMARY KEY (link_id, filter_id, user_id),
+			FOREIGN KEY (link_id, user_id) REFERENCES link_users(link_id, user_id) ON DELETE CASCADE
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE link_tags, link_filters, link_users, links, users")
+			require.NoError(t, err)
+
+			const (
+				tgID    = int64(42)
+				otherID = int64(43)
+			)
+
+			require.NoError(t, svc.CreateChat(ctx, tgID))
+			require.NoError(t, svc.CreateChat(ctx, otherID))
+			require.NoError(t, svc.AddLink(ctx, tgID, "https://github.com/a/b", []string{"work", "go"}, nil, time.Now()))
+			require.NoError(t, svc.AddLink(ctx, tgID, "https://github.com/a/c", []string{"job"}, nil, time.Now()))
+			require.NoError(t, svc.AddLink(ctx, otherID, "https://github.com/a/b", []string{"work"}, nil, time.Now()))
+
+			tags, err := svc.ListTags(ctx, tgID)
+			require.NoError(t, err)
+			assert.Equal(t, []scrappertypes.TagResponse{{Name: "go", Links: 1}, {Name: "job", Links: 1}, {Name: "work", Links: 1}}, tags)
+
+			_, err = svc.RenameTag(ctx, tgID, "work", "go", false)
+			assert.ErrorIs(t, err, e.ErrTagAlreadyExists)
+
+			_, err = svc.RenameTag(ctx, tgID, "home", "house", false)
+			assert.ErrorIs(t, err, e.ErrTagNotFound)
+
+			_, err = svc.RenameTag(ctx, tgID, "job", "home", true)
+			assert.ErrorIs(t, err, e.ErrTagNotFound)
+
+			tag, err := svc.RenameTag(ctx, tgID, "work", "job", true)
+			require.NoError(t, err)
+			assert.Equal(t, scrappertypes.TagResponse{Name: "job", Links: 2}, tag)
+
+			tag, err = svc.RenameTag(ctx, tgID, "job", "projects", false)
+			require.NoError(t, err)
+			assert.Equal(t, scrappertypes.TagResponse{Name: "projects", Links: 2}, tag)
+
+			tags, err = svc.ListTags(ctx, tgID)
+			require.NoError(t, err)
+			assert.Equal(t, []scrappertypes.TagResponse{{Name: "projects", Links: 2}, {Name: "go", Links: 1}}, tags)
+
+			tags, err = svc.ListTags(ctx, otherID)
+			require.NoError(t, err)
+			assert.Equal(t, []scrappertypes.TagResponse{{Name: "work", Links: 1}}, tags)
+		})
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	dbURL, err := startTestPostgres(t)
+	require.NoError(t, err)
+
+	db, err := pgxpool.Connect(ctx, dbURL)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS links (
+			id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+			url TEXT UNIQUE NOT NULL,
+			changed_at TIMESTAMP DEFAULT now()
+		);
+		CREATE TABLE IF NOT EXISTS outbox (
+			id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			payload JSONB NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT,
+			next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+			locked_until TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT now(),
+			delivered_at TIMESTAMP
+		);
+	`)
+
+	db.Close()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"SQL implementation", "sql"},
+		{"ORM implementation", "orm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := repository.NewLinkService(tt.typ, dbURL)
+			require.NoError(t, err)
+
+			db, err := pgxpool.Connect(ctx, dbURL)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.Exec(ctx, "TRUNCATE outbox, links")
+			require.NoError(t, err)
+
+			var linkID int64
+
+			err = db.QueryRow(ctx, `INSERT INTO links (url) VALUES ('https://example.com') RETURNING id`).Scan(&linkID)
+			require.NoError(t, err)
+
+			updTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			updates := []bottypes.LinkUpdate{
+				{ID: linkID, URL: "https://example.com", Description: "first", TgChatIDs: []int64{1}},
+				{ID: linkID, URL: "https://example.com", Description: "second", TgChatIDs: []int64{2, 3}},
+			}
+
+			err = svc.SaveUpdateWithNotifications(ctx, linkID, updTime, updates)
+			require.NoError(t, err)
+			assert.Equal(t, updTime, svc.GetPreviousUpdate(ctx, linkID).UTC())
+
+			notifications := svc.ClaimNotifications(ctx, 10, time.Minute)
+			require.Len(t, notifications, 2)
+			assert.Equal(t, updates[0], notifications[0].Update)
+			assert.Empty(t, svc.ClaimNotifications(ctx, 10, time.Minute), "claimed notifications must be leased")
+
+			err = svc.MarkNotificationDelivered(ctx, notifications[0].ID)
+			require.NoError(t, err)
+
+			err = svc.MarkNotificationFailed(ctx, notifications[1].ID, "bot is down", 0, false)
+			require.NoError(t, err)
+
+			retried := svc.ClaimNotifications(ctx, 10, time.Minute)
+			require.Len(t, retried, 1)
+			assert.Equal(t, notifications[1].ID, retried[0].ID)
+			assert.Equal(t, 1, retried[0].Attempts)
+
+			err = svc.MarkNotificationFailed(ctx, retried[0].ID, "bot is down", 0, true)
+			require.NoError(t, err)
+			assert.Empty(t, svc.ClaimNotifications(ctx, 10, time.Minute), "dead notifications must not be retried")
+
+			var status string
+
+			err = db.QueryRow(ctx, `SELECT status FROM outbox WHERE id = $1`, retried[0].ID).Scan(&status)
+			require.NoError(t, err)
+			assert.Equal(t, "dead", status)
+
+			var malformedID int64
+
+			err = db.QueryRow(ctx, `INSERT INTO outbox (payload) VALUES ('{"id":"not a number"}') RETURNING id`).Scan(&malformedID)
+			require.NoError(t, err)
+
+			assert.Empty(t, svc.ClaimNotifications(ctx, 10, time.Minute))
+
+			err = db.QueryRow(ctx, `SELECT status FROM outbox WHERE id = $1`, malformedID).Scan(&status)
+			require.NoError(t, err)
+			assert.Equal(t, "dead", status, "notifications that can't be decoded must not be claimed again")
+		})
+	}
+}
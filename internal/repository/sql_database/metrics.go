@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"go-progira/internal/tracing"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "scrapper_db_query_duration_seconds",
+	Help:    "Time spent on database queries, by statement and status. Queries are timed until their rows are closed.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"statement", "status"})
+
+var tracer = tracing.Tracer("go-progira/repository")
+
+// queryLogger turns the query log of pgx into latency metrics and spans. pgx v4 has no tracer hooks,
+// but it reports the duration of every query it logs, so the span is recorded after the fact.
+type queryLogger struct{}
+
+func (queryLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	duration, ok := data["time"].(time.Duration)
+	if !ok {
+		return
+	}
+
+	statement := strings.ToLower(msg)
+	if sql, ok := data["sql"].(string); ok {
+		statement = statementName(sql)
+	}
+
+	status := "ok"
+	if level <= pgx.LogLevelError {
+		status = "error"
+	}
+
+	queryDuration.WithLabelValues(statement, status).Observe(duration.Seconds())
+
+	end := time.Now()
+
+	_, span := tracer.Start(ctx, "db "+statement,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-duration)),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", statement)))
+
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
+
+// statementName returns the kind of the query, such as "select" or "update".
+func statementName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch name := strings.ToLower(fields[0]); name {
+	case "select", "insert", "update", "delete", "with":
+		return name
+	default:
+		return "other"
+	}
+}
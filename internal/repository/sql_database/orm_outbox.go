@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+func (s *ORMLinkService) SaveUpdateWithNotifications(ctx context.Context, linkID int64, updTime time.Time,
+	updates []bottypes.LinkUpdate) error {
+	sql, args, err := sq.Update("links").
+		Set("changed_at", updTime).
+		Where(sq.Eq{"id": linkID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	err = s.insertNotifications(ctx, tx, updates)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *ORMLinkService) AddNotifications(ctx context.Context, updates []bottypes.LinkUpdate) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = s.insertNotifications(ctx, tx, updates)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *ORMLinkService) insertNotifications(ctx context.Context, tx pgx.Tx, updates []bottypes.LinkUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	insert := sq.Insert("outbox").Columns("payload")
+
+	for _, update := range updates {
+		payload, err := json.Marshal(update)
+		if err != nil {
+			return err
+		}
+
+		insert = insert.Values(payload)
+	}
+
+	sql, args, err := insert.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		slog.Error("Unable to build INSERT query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+func (s *ORMLinkService) ClaimNotifications(ctx context.Context, batch int,
+	lease time.Duration) []scrappertypes.Notification {
+	if batch < 0 {
+		slog.Error("batch cannot be negative",
+			slog.Int("batch", batch))
+
+		return []scrappertypes.Notification{}
+	}
+
+	due := sq.Select("id").
+		From("outbox").
+		Where(sq.Eq{"status": "pending"}).
+		Where("next_attempt_at <= now()").
+		Where(sq.Or{sq.Eq{"locked_until": nil}, sq.Expr("locked_until < now()")}).
+		OrderBy("id").
+		Limit(uint64(batch)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, err := sq.Update("outbox o").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int64(lease.Seconds()))).
+		Where(sq.Expr("o.id IN (?)", due)).
+		Suffix("RETURNING o.id, o.payload, o.attempts").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		slog.Error("Unable to begin transaction",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	notifications, malformed := scanNotifications(rows)
+	rows.Close()
+
+	if err = buryNotifications(ctx, tx, malformed); err != nil {
+		return []scrappertypes.Notification{}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		slog.Error("Unable to commit transaction",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	return notifications
+}
+
+// buryNotifications marks the notifications dead with the errors of their payloads.
+func buryNotifications(ctx context.Context, tx pgx.Tx, malformed map[int64]string) error {
+	for id, decodeErr := range malformed {
+		sql, args, err := sq.Update("outbox").
+			Set("status", "dead").
+			Set("attempts", sq.Expr("attempts + 1")).
+			Set("last_error", decodeErr).
+			Set("locked_until", nil).
+			Where(sq.Eq{"id": id}).
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			slog.Error("Unable to build UPDATE query",
+				slog.String("error", err.Error()))
+
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			slog.Error("Query error",
+				slog.String("error", err.Error()))
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ORMLinkService) MarkNotificationDelivered(ctx context.Context, id int64) error {
+	sql, args, err := sq.Update("outbox").
+		Set("status", "delivered").
+		Set("delivered_at", sq.Expr("now()")).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+func (s *ORMLinkService) MarkNotificationFailed(ctx context.Context, id int64, deliveryErr string,
+	backoff time.Duration, dead bool) error {
+	status := "pending"
+	if dead {
+		status = "dead"
+	}
+
+	sql, args, err := sq.Update("outbox").
+		Set("status", status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", deliveryErr).
+		Set("next_attempt_at", sq.Expr("now() + ? * interval '1 second'", backoff.Seconds())).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
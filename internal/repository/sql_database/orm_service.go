@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"go-progira/internal/domain/types/scrappertypes"
+	repository "go-progira/internal/repository/dictionary_storage"
 	"go-progira/pkg/e"
 	"log/slog"
 	"time"
@@ -60,9 +61,7 @@ func (s *ORMLinkService) DeleteChat(_ context.Context, id int64) error {
 	return err
 }
 
-func (s *ORMLinkService) getLinkID(ctx context.Context, url string) (int64, error) {
-	tx, _ := s.db.Begin(ctx)
-
+func (s *ORMLinkService) getLinkID(ctx context.Context, tx pgx.Tx, url string) (int64, error) {
 	sql, args, errBuildQuery := sq.
 		Select("id").
 		From("links").
@@ -84,25 +83,32 @@ func (s *ORMLinkService) getLinkID(ctx context.Context, url string) (int64, erro
 		slog.Error("Failed to retrieve link ID after conflict",
 			slog.String("error", errQuery.Error()))
 
-		_ = tx.Rollback(ctx)
-
 		return 0, errQuery
 	}
 
 	return linkID, nil
 }
 
-func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags, filters []string) error {
+func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags, filters []string,
+	lastActivity time.Time) error {
+	if s.IsURLInAdded(ctx, id, url) {
+		return e.ErrLinkAlreadyExists
+	}
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	var linkID int64
 
 	sql, args, err := buildInsertQuery("links",
 		[]string{"url", "changed_at"},
-		[]interface{}{url, sq.Expr("NOW()")},
+		[]interface{}{url, lastActivity},
 		"ON CONFLICT DO NOTHING RETURNING id")
 
 	if err != nil {
@@ -112,7 +118,7 @@ func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags
 	errQuery := tx.QueryRow(ctx, sql, args...).Scan(&linkID)
 
 	if errors.Is(errQuery, pgx.ErrNoRows) {
-		linkID, errQuery = s.getLinkID(ctx, url)
+		linkID, errQuery = s.getLinkID(ctx, tx, url)
 		if errQuery != nil {
 			return errQuery
 		}
@@ -120,11 +126,6 @@ func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags
 		slog.Error("Query Exec error",
 			slog.String("error", errQuery.Error()))
 
-		errRollback := tx.Rollback(ctx)
-		if errRollback != nil {
-			return errRollback
-		}
-
 		return errQuery
 	}
 
@@ -134,9 +135,9 @@ func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags
 	}
 
 	sql, args, err = buildInsertQuery("link_users",
-		[]string{"user_id", "link_id"},
+		[]string{"user_id", "link_id", "subscribed_at"},
 		[]interface{}{sq.Expr("(SELECT id FROM users WHERE telegram_id = ?)", id),
-			linkID},
+			linkID, lastActivity},
 		"ON CONFLICT DO NOTHING")
 
 	if err != nil {
@@ -149,14 +150,14 @@ func (s *ORMLinkService) AddLink(ctx context.Context, id int64, url string, tags
 			slog.String("error", err.Error()))
 	}
 
-	err = s.addTags(ctx, id, linkID, tags)
+	err = s.addLabels(ctx, tx, "tag", id, linkID, tags)
 	if err != nil {
-		slog.Error("Error adding tags")
+		return err
 	}
 
-	err = s.addFilters(ctx, id, linkID, filters)
+	err = s.addLabels(ctx, tx, "filter", id, linkID, filters)
 	if err != nil {
-		slog.Error("Error adding filters")
+		return err
 	}
 
 	return tx.Commit(ctx)
@@ -214,6 +215,155 @@ func (s *ORMLinkService) DeleteTag(ctx context.Context, id int64, tag string) er
 	return err
 }
 
+// ListTags counts the links of the chat that have each of its tags.
+func (s *ORMLinkService) ListTags(ctx context.Context, id int64) ([]scrappertypes.TagResponse, error) {
+	sql, args, err := sq.
+		Select("t.name", "COUNT(*)").
+		From("link_tags lt").
+		Join("tags t ON t.id = lt.tag_id").
+		Join("users u ON u.id = lt.user_id").
+		Where(sq.Eq{"u.telegram_id": id}).
+		GroupBy("t.name").
+		OrderBy("COUNT(*) DESC", "t.name").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build SELECT query",
+			slog.String("error", err.Error()))
+
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error executing query",
+			slog.String("error", err.Error()))
+
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []scrappertypes.TagResponse{}
+
+	for rows.Next() {
+		var tag scrappertypes.TagResponse
+
+		if err := rows.Scan(&tag.Name, &tag.Links); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// RenameTag moves the links of the chat from tag to newName in one transaction.
+func (s *ORMLinkService) RenameTag(ctx context.Context, id int64, tag, newName string, merge bool) (
+	scrappertypes.TagResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	moved, err := s.countTagLinks(ctx, tx, id, tag)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	target, err := s.countTagLinks(ctx, tx, id, newName)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	if err = checkRename(moved, target, merge); err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	userID := sq.Expr("(SELECT id FROM users WHERE telegram_id = ?)", id)
+	tagID := func(name string) sq.Sqlizer {
+		return sq.Expr("(SELECT id FROM tags WHERE name = ?)", name)
+	}
+
+	for _, query := range []sq.Sqlizer{
+		sq.Insert("tags").Columns("name").Values(newName).Suffix("ON CONFLICT (name) DO NOTHING"),
+		sq.Insert("link_tags").
+			Columns("link_id", "tag_id", "user_id").
+			Select(sq.Select("link_id").
+				Column(tagID(newName)).
+				Column("user_id").
+				From("link_tags").
+				Where(sq.Expr("user_id = ?", userID)).
+				Where(sq.Expr("tag_id = ?", tagID(tag)))).
+			Suffix("ON CONFLICT DO NOTHING"),
+		sq.Delete("link_tags").
+			Where(sq.Expr("user_id = ?", userID)).
+			Where(sq.Expr("tag_id = ?", tagID(tag))),
+	} {
+		sql, args, errBuild := query.ToSql()
+		if errBuild != nil {
+			slog.Error("Unable to build query",
+				slog.String("error", errBuild.Error()))
+
+			return scrappertypes.TagResponse{}, errBuild
+		}
+
+		if sql, err = sq.Dollar.ReplacePlaceholders(sql); err != nil {
+			return scrappertypes.TagResponse{}, err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
+
+			return scrappertypes.TagResponse{}, err
+		}
+	}
+
+	links, err := s.countTagLinks(ctx, tx, id, newName)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	return scrappertypes.TagResponse{Name: newName, Links: links}, nil
+}
+
+// countTagLinks counts the links of the chat that have tag.
+func (s *ORMLinkService) countTagLinks(ctx context.Context, tx pgx.Tx, id int64, tag string) (int, error) {
+	sql, args, err := sq.
+		Select("COUNT(*)").
+		From("link_tags lt").
+		Join("tags t ON t.id = lt.tag_id").
+		Join("users u ON u.id = lt.user_id").
+		Where(sq.Eq{"u.telegram_id": id, "t.name": tag}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build SELECT query",
+			slog.String("error", err.Error()))
+
+		return 0, err
+	}
+
+	var links int
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&links); err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return links, err
+}
+
 func getItems(rows pgx.Rows) (map[int64][]string, error) {
 	itemsByID := make(map[int64][]string)
 
@@ -306,20 +456,33 @@ func (s *ORMLinkService) GetFilters(ctx context.Context, id int64) map[int64][]s
 	return filtersByID
 }
 
-func (s *ORMLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertypes.LinkResponse, error) {
-	sql, args, err := sq.
-		Select("l.id", "l.url", "l.changed_at").
+func (s *ORMLinkService) GetLinks(ctx context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error) {
+	expr, cast, operator, direction := pageOrder(query)
+
+	builder := sq.
+		Select("l.id", "l.url", "l.changed_at", "l.broken", "l.consecutive_failures", "l.last_error", "l.last_success_at",
+			"lu.added_at").
 		From("links l").
 		Join("link_users lu ON l.id = lu.link_id").
 		Join("users u ON u.id = lu.user_id").
 		Where(sq.Eq{"u.telegram_id": id}).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		OrderBy(expr+" "+direction, "l.id "+direction).
+		PlaceholderFormat(sq.Dollar)
+
+	if query.Cursor != nil {
+		builder = builder.Where(fmt.Sprintf("(%s, l.id) %s (?::%s, ?)", expr, operator, cast), query.Cursor.Key, query.Cursor.ID)
+	}
+
+	if query.Limit > 0 {
+		builder = builder.Limit(uint64(query.Limit + 1))
+	}
+
+	sql, args, err := builder.ToSql()
 	if err != nil {
 		slog.Error("Unable to build SELECT query",
 			slog.String("error", err.Error()))
 
-		return nil, err
+		return scrappertypes.LinksPage{}, err
 	}
 
 	slog.Info("SELECT query",
@@ -328,7 +491,7 @@ func (s *ORMLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertype
 
 	rows, err := s.db.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		return scrappertypes.LinksPage{}, err
 	}
 
 	defer rows.Close()
@@ -336,11 +499,9 @@ func (s *ORMLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertype
 	var links []scrappertypes.LinkResponse
 
 	for rows.Next() {
-		var link scrappertypes.LinkResponse
-
-		err := rows.Scan(&link.ID, &link.URL, &link.LastChecked)
+		link, err := scanLinkWithHealth(rows)
 		if err != nil {
-			return nil, err
+			return scrappertypes.LinksPage{}, err
 		}
 
 		links = append(links, link)
@@ -354,7 +515,7 @@ func (s *ORMLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertype
 		links[i].Filters = filters[links[i].ID]
 	}
 
-	return links, nil
+	return repository.NewLinksPage(links, query), nil
 }
 
 func (s *ORMLinkService) IsURLInAdded(ctx context.Context, id int64, u string) bool {
@@ -397,33 +558,37 @@ func (s *ORMLinkService) IsURLInAdded(ctx context.Context, id int64, u string) b
 	return exists
 }
 
-func (s *ORMLinkService) GetBatchOfLinks(ctx context.Context, batch int, lastID int64) (links []scrappertypes.LinkResponse,
-	lastReturnedID int64) {
+func (s *ORMLinkService) ClaimLinks(ctx context.Context, owner string, batch int,
+	lease time.Duration) []scrappertypes.LinkResponse {
 	if batch < 0 {
 		slog.Error("batch cannot be negative",
 			slog.Int("batch", batch))
 
-		return []scrappertypes.LinkResponse{}, lastID
+		return []scrappertypes.LinkResponse{}
 	}
 
-	sql, args, err := sq.
-		Select("id", "url").
+	due := sq.Select("id").
 		From("links").
-		Where(sq.Gt{"id": lastID}).
-		OrderBy("id").
+		Where("next_check_at <= now()").
+		Where("NOT broken").
+		Where(sq.Or{sq.Eq{"locked_until": nil}, sq.Expr("locked_until < now()")}).
+		OrderBy("next_check_at").
 		Limit(uint64(batch)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, err := sq.Update("links l").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int64(lease.Seconds()))).
+		Set("locked_by", owner).
+		Where(sq.Expr("l.id IN (?)", due)).
+		Suffix(`RETURNING l.id, l.url, l.check_interval_seconds,
+			COALESCE((SELECT MIN(lu.user_interval_seconds) FROM link_users lu WHERE lu.link_id = l.id), 0)`).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
-
-	slog.Info("SELECT query",
-		slog.String("sql", sql),
-		slog.Any("args", args))
-
 	if err != nil {
-		slog.Error("Unable to build SELECT query",
+		slog.Error("Unable to build UPDATE query",
 			slog.String("error", err.Error()))
 
-		return []scrappertypes.LinkResponse{}, lastID
+		return []scrappertypes.LinkResponse{}
 	}
 
 	rows, err := s.db.Query(ctx, sql, args...)
@@ -431,24 +596,276 @@ func (s *ORMLinkService) GetBatchOfLinks(ctx context.Context, batch int, lastID
 		slog.Error("Query error",
 			slog.String("error", err.Error()))
 
-		return []scrappertypes.LinkResponse{}, lastID
+		return []scrappertypes.LinkResponse{}
 	}
 
-	lastReturnedID = lastID
+	defer rows.Close()
+
+	links := []scrappertypes.LinkResponse{}
 
 	for rows.Next() {
 		var link scrappertypes.LinkResponse
 
-		err := rows.Scan(&link.ID, &link.URL)
+		var checkInterval, userInterval int64
+
+		err := rows.Scan(&link.ID, &link.URL, &checkInterval, &userInterval)
 		if err != nil {
-			return []scrappertypes.LinkResponse{}, lastID
+			slog.Error("Scan error",
+				slog.String("error", err.Error()))
+
+			return links
 		}
 
+		link.CheckInterval = time.Duration(checkInterval) * time.Second
+		link.UserInterval = time.Duration(userInterval) * time.Second
+
 		links = append(links, link)
-		lastReturnedID = link.ID
 	}
 
-	return links, lastReturnedID
+	return links
+}
+
+func (s *ORMLinkService) ClaimLink(ctx context.Context, owner string, linkID int64,
+	lease time.Duration) (scrappertypes.LinkResponse, bool) {
+	var link scrappertypes.LinkResponse
+
+	sql, args, err := sq.Update("links l").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int64(lease.Seconds()))).
+		Set("locked_by", owner).
+		Where(sq.Eq{"l.id": linkID}).
+		Where(sq.Or{sq.Eq{"l.locked_until": nil}, sq.Expr("l.locked_until < now()")}).
+		Suffix(`RETURNING l.id, l.url, l.check_interval_seconds,
+			COALESCE((SELECT MIN(lu.user_interval_seconds) FROM link_users lu WHERE lu.link_id = l.id), 0)`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return link, false
+	}
+
+	var checkInterval, userInterval int64
+
+	err = s.db.QueryRow(ctx, sql, args...).Scan(&link.ID, &link.URL, &checkInterval, &userInterval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return link, false
+	} else if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return link, false
+	}
+
+	link.CheckInterval = time.Duration(checkInterval) * time.Second
+	link.UserInterval = time.Duration(userInterval) * time.Second
+
+	return link, true
+}
+
+func (s *ORMLinkService) SaveCheckSuccess(ctx context.Context, linkID int64) error {
+	sql, args, err := sq.Update("links").
+		Set("consecutive_failures", 0).
+		Set("last_error", nil).
+		Set("last_success_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": linkID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+func (s *ORMLinkService) SaveCheckFailure(ctx context.Context, linkID int64, checkErr string, disable bool,
+	threshold int) (bool, error) {
+	sql, args, err := sq.Update("links").
+		Prefix("WITH prev AS (SELECT broken FROM links WHERE id = ?)", linkID).
+		Set("consecutive_failures", sq.Expr("consecutive_failures + 1")).
+		Set("last_error", checkErr).
+		Set("broken", sq.Expr("broken OR ? OR consecutive_failures + 1 >= ?", disable, threshold)).
+		Where(sq.Eq{"id": linkID}).
+		Suffix("RETURNING broken AND NOT (SELECT broken FROM prev)").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return false, err
+	}
+
+	var becameBroken bool
+
+	err = s.db.QueryRow(ctx, sql, args...).Scan(&becameBroken)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return false, err
+	}
+
+	return becameBroken, nil
+}
+
+func (s *ORMLinkService) EnableLink(ctx context.Context, id int64, link string) error {
+	subscribed := sq.Select("lu.link_id").
+		From("link_users lu").
+		Join("users u ON u.id = lu.user_id").
+		Where(sq.Eq{"u.telegram_id": id})
+
+	sql, args, err := sq.Update("links").
+		Set("broken", false).
+		Set("consecutive_failures", 0).
+		Set("next_check_at", sq.Expr("now()")).
+		Where(sq.Eq{"url": link}).
+		Where(sq.Expr("id IN (?)", subscribed)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	res, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+func (s *ORMLinkService) ReleaseLink(ctx context.Context, owner string, linkID int64, interval time.Duration) error {
+	seconds := int64(interval.Seconds())
+
+	sql, args, err := sq.Update("links").
+		Set("check_interval_seconds", seconds).
+		Set("next_check_at", sq.Expr("now() + ? * interval '1 second'", seconds)).
+		Set("locked_until", nil).
+		Set("locked_by", nil).
+		Where(sq.Eq{"id": linkID, "locked_by": owner}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	res, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (s *ORMLinkService) ExtendLease(ctx context.Context, owner string, linkID int64, lease time.Duration) error {
+	sql, args, err := sq.Update("links").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int64(lease.Seconds()))).
+		Where(sq.Eq{"id": linkID, "locked_by": owner}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	res, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (s *ORMLinkService) SetCheckInterval(ctx context.Context, id int64, link string, interval time.Duration) error {
+	seconds := int64(interval.Seconds())
+
+	sql, args, err := sq.Update("link_users").
+		Set("user_interval_seconds", sq.Expr("NULLIF(?, 0)", seconds)).
+		Where("user_id = (SELECT id FROM users WHERE telegram_id = ?)", id).
+		Where("link_id = (SELECT id FROM links WHERE url = ?)", link).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	res, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLinkNotFound
+	}
+
+	if seconds == 0 {
+		return nil
+	}
+
+	sql, args, err = sq.Update("links").
+		Set("next_check_at", sq.Expr("LEAST(next_check_at, now() + ? * interval '1 second')", seconds)).
+		Where(sq.Eq{"url": link}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build UPDATE query",
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+	}
+
+	return err
 }
 
 func (s *ORMLinkService) GetPreviousUpdate(ctx context.Context, id int64) time.Time {
@@ -542,147 +959,189 @@ func (s *ORMLinkService) GetTgChatIDsForLink(ctx context.Context, link string) [
 	return tgIDs
 }
 
-func (s *ORMLinkService) Close() {
-	s.db.Close()
+func (s *ORMLinkService) GetSubscribers(ctx context.Context, linkID int64) []scrappertypes.Subscriber {
+	sql, args, err := sq.
+		Select("u.telegram_id", "lu.subscribed_at").
+		From("users u").
+		Join("link_users lu ON u.id = lu.user_id").
+		Where(sq.Eq{"lu.link_id": linkID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build SELECT query",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Subscriber{}
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Subscriber{}
+	}
+
+	defer rows.Close()
+
+	var subscribers []scrappertypes.Subscriber
+
+	for rows.Next() {
+		var subscriber scrappertypes.Subscriber
+
+		err := rows.Scan(&subscriber.TgChatID, &subscriber.SubscribedAt)
+		if err != nil {
+			return []scrappertypes.Subscriber{}
+		}
+
+		subscribers = append(subscribers, subscriber)
+	}
+
+	return subscribers
 }
 
-func (s *ORMLinkService) addTags(ctx context.Context, tgID, linkID int64, tags []string) error {
-	tx, _ := s.db.Begin(ctx)
+// Ping checks that a connection to the database can be acquired.
+func (s *ORMLinkService) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
 
-	for _, tag := range tags {
-		var tagID int64
+func (s *ORMLinkService) Close() {
+	s.db.Close()
+}
 
-		sql, args, err := buildInsertQuery("tags",
+// addLabels attaches tags or filters, as kind tells, to the link of the chat in tx.
+// The ones that don't exist yet are created.
+func (s *ORMLinkService) addLabels(ctx context.Context, tx pgx.Tx, kind string, tgID, linkID int64, names []string) error {
+	for _, name := range names {
+		sql, args, err := buildInsertQuery(kind+"s",
 			[]string{"name"},
-			[]interface{}{tag},
+			[]interface{}{name},
 			"ON CONFLICT (name) DO NOTHING")
 		if err != nil {
 			return err
 		}
 
-		_, err = s.db.Exec(ctx, sql, args...)
+		_, err = tx.Exec(ctx, sql, args...)
 		if err != nil {
-			return err
-		}
-
-		sql, args, errBuildQuery := sq.
-			Select("id").
-			From("tags").
-			Where(sq.Eq{"name": tag}).
-			PlaceholderFormat(sq.Dollar).
-			ToSql()
-		if errBuildQuery != nil {
-			slog.Error("Unable to build SELECT query" + errBuildQuery.Error())
-
-			return errBuildQuery
-		}
-
-		errScan := tx.QueryRow(ctx, sql, args...).Scan(&tagID)
-		if errScan != nil {
-			slog.Error("Unable to execute query",
-				slog.String("error", errScan.Error()))
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
 
-			errRollback := tx.Rollback(ctx)
-			if errRollback != nil {
-				return errRollback
-			}
-
-			return errScan
+			return err
 		}
 
-		sql, args, err = buildInsertQuery("link_tags",
-			[]string{"link_id", "tag_id", "user_id"},
-			[]interface{}{linkID, tagID, tgID},
+		sql, args, err = buildInsertQuery("link_"+kind+"s",
+			[]string{"link_id", kind + "_id", "user_id"},
+			[]interface{}{linkID,
+				sq.Expr("(SELECT id FROM "+kind+"s WHERE name = ?)", name),
+				sq.Expr("(SELECT id FROM users WHERE telegram_id = ?)", tgID)},
 			"ON CONFLICT DO NOTHING")
-
 		if err != nil {
 			return err
 		}
 
 		_, err = tx.Exec(ctx, sql, args...)
-
 		if err != nil {
-			slog.Error(ErrExecQuery.Error() + err.Error())
-
-			errRollback := tx.Rollback(ctx)
-			if errRollback != nil {
-				return errRollback
-			}
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
 
 			return err
 		}
 	}
 
-	return tx.Commit(ctx)
+	return nil
 }
 
-func (s *ORMLinkService) addFilters(ctx context.Context, tgID, linkID int64, filters []string) error {
-	tx, _ := s.db.Begin(ctx)
+// removeLabels detaches tags or filters, as kind tells, from the link of the chat in tx.
+func (s *ORMLinkService) removeLabels(ctx context.Context, tx pgx.Tx, kind string, tgID, linkID int64, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
 
-	for _, filter := range filters {
-		var filterID int64
+	sql, args, err := sq.Delete("link_"+kind+"s").
+		Where(sq.Eq{"link_id": linkID}).
+		Where("user_id = (SELECT id FROM users WHERE telegram_id = ?)", tgID).
+		Where(kind+"_id IN (SELECT id FROM "+kind+"s WHERE name = ANY(?))", names).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build DELETE query",
+			slog.String("error", err.Error()))
 
-		sql, args, err := buildInsertQuery("filters",
-			[]string{"name"},
-			[]interface{}{filter},
-			"ON CONFLICT (name) DO NOTHING")
-		if err != nil {
-			return err
-		}
+		return err
+	}
 
-		_, err = s.db.Exec(ctx, sql, args...)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
 
-		sql, args, errBuildQuery := sq.
-			Select("id").
-			From("filters").
-			Where(sq.Eq{"name": filter}).
-			PlaceholderFormat(sq.Dollar).
-			ToSql()
-		if errBuildQuery != nil {
-			slog.Error("Unable to build SELECT query",
-				slog.String("error", errBuildQuery.Error()))
-
-			return errBuildQuery
-		}
+	return err
+}
 
-		errScan := tx.QueryRow(ctx, sql, args...).Scan(&filterID)
-		if errScan != nil {
-			slog.Error("Unable to execute query",
-				slog.String("error", errScan.Error()))
+// UpdateLink adds and removes tags and filters of the link the chat is subscribed to.
+func (s *ORMLinkService) UpdateLink(ctx context.Context, id int64, link string, change scrappertypes.LinkChange) (
+	scrappertypes.LinkResponse, error) {
+	sql, args, err := sq.Select("lu.link_id").
+		From("link_users lu").
+		Join("users u ON u.id = lu.user_id").
+		Join("links l ON l.id = lu.link_id").
+		Where(sq.Eq{"u.telegram_id": id, "l.url": link}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		slog.Error("Unable to build SELECT query",
+			slog.String("error", err.Error()))
 
-			errRollback := tx.Rollback(ctx)
-			if errRollback != nil {
-				return errRollback
-			}
+		return scrappertypes.LinkResponse{}, err
+	}
 
-			return errScan
-		}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
 
-		sql, args, err = buildInsertQuery("link_filters",
-			[]string{"link_id", "filter_id", "user_id"},
-			[]interface{}{linkID, filterID, tgID},
-			"ON CONFLICT DO NOTHING")
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-		if err != nil {
-			return err
-		}
+	var linkID int64
 
-		_, err = tx.Exec(ctx, sql, args...)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&linkID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scrappertypes.LinkResponse{}, e.ErrLinkNotFound
+	} else if err != nil {
+		slog.Error("Query error",
+			slog.String("error", err.Error()))
 
-		if err != nil {
-			slog.Error(ErrExecQuery.Error() + err.Error())
+		return scrappertypes.LinkResponse{}, err
+	}
 
-			errRollback := tx.Rollback(ctx)
-			if errRollback != nil {
-				return errRollback
-			}
+	for _, step := range []struct {
+		apply func(ctx context.Context, tx pgx.Tx, kind string, tgID, linkID int64, names []string) error
+		kind  string
+		names []string
+	}{
+		{s.removeLabels, "tag", change.RemoveTags},
+		{s.removeLabels, "filter", change.RemoveFilters},
+		{s.addLabels, "tag", change.AddTags},
+		{s.addLabels, "filter", change.AddFilters},
+	} {
+		if err = step.apply(ctx, tx, step.kind, id, linkID, step.names); err != nil {
+			return scrappertypes.LinkResponse{}, err
 		}
 	}
 
-	return tx.Commit(ctx)
+	if err = tx.Commit(ctx); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	return scrappertypes.LinkResponse{
+		ID:      linkID,
+		URL:     link,
+		Tags:    s.GetTags(ctx, id)[linkID],
+		Filters: s.GetFilters(ctx, id)[linkID],
+	}, nil
 }
 
 func buildInsertQuery(table string, columns []string, values []interface{}, suffix string) (sql string,
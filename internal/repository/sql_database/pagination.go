@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"go-progira/internal/domain/types/scrappertypes"
+	repository "go-progira/internal/repository/dictionary_storage"
+)
+
+// sortColumns are the expressions links are sorted by and the types their cursor keys are cast to.
+// The keys are made by repository.SortKey from the same values.
+var sortColumns = map[string]struct {
+	expr string
+	cast string
+}{
+	scrappertypes.SortAdded:    {"lu.added_at", "timestamp"},
+	scrappertypes.SortActivity: {"l.changed_at", "timestamp"},
+	scrappertypes.SortURL:      {"l.url", "text"},
+	scrappertypes.SortProvider: {"substring(l.url from '://([^/]+)')", "text"},
+}
+
+// pageOrder returns the expression the links of query are sorted by, the operator that selects
+// the links after the cursor and the direction of the sort.
+func pageOrder(query scrappertypes.LinksQuery) (expr, cast, operator, direction string) {
+	column, ok := sortColumns[query.Sort]
+	if !ok {
+		column = sortColumns[scrappertypes.SortAdded]
+	}
+
+	if repository.Ascending(query) {
+		return column.expr, column.cast, ">", "ASC"
+	}
+
+	return column.expr, column.cast, "<", "DESC"
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"go-progira/internal/domain/types/bottypes"
+	"go-progira/internal/domain/types/scrappertypes"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// SaveUpdateWithNotifications moves the cursor of the link and queues the notifications
+// about the events before it in one transaction, so neither of them is lost without the other.
+func (s *SQLLinkService) SaveUpdateWithNotifications(ctx context.Context, linkID int64, updTime time.Time,
+	updates []bottypes.LinkUpdate) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, `UPDATE links SET changed_at = $1 WHERE id = $2`, updTime, linkID)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	err = insertNotifications(ctx, tx, updates)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *SQLLinkService) AddNotifications(ctx context.Context, updates []bottypes.LinkUpdate) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = insertNotifications(ctx, tx, updates)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertNotifications(ctx context.Context, tx pgx.Tx, updates []bottypes.LinkUpdate) error {
+	for _, update := range updates {
+		payload, err := json.Marshal(update)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO outbox (payload) VALUES ($1)`, payload)
+		if err != nil {
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClaimNotifications leases up to batch pending notifications that are due for delivery.
+// The ones whose payload can't be decoded are marked dead in the same transaction, so they are not claimed again.
+func (s *SQLLinkService) ClaimNotifications(ctx context.Context, batch int,
+	lease time.Duration) []scrappertypes.Notification {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, `
+			UPDATE outbox o
+			SET locked_until = now() + $2 * interval '1 second'
+			WHERE o.id IN (
+				SELECT id FROM outbox
+				WHERE status = 'pending' AND next_attempt_at <= now()
+					AND (locked_until IS NULL OR locked_until < now())
+				ORDER BY id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING o.id, o.payload, o.attempts`, batch, int64(lease.Seconds()))
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	notifications, malformed := scanNotifications(rows)
+	rows.Close()
+
+	for id, decodeErr := range malformed {
+		_, err = tx.Exec(ctx, `
+            UPDATE outbox
+            SET status = 'dead', attempts = attempts + 1, last_error = $2, locked_until = NULL
+            WHERE id = $1`, id, decodeErr)
+		if err != nil {
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
+
+			return []scrappertypes.Notification{}
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Notification{}
+	}
+
+	return notifications
+}
+
+func (s *SQLLinkService) MarkNotificationDelivered(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, `
+        UPDATE outbox
+        SET status = 'delivered', delivered_at = now(), attempts = attempts + 1, locked_until = NULL
+        WHERE id = $1`, id)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+func (s *SQLLinkService) MarkNotificationFailed(ctx context.Context, id int64, deliveryErr string,
+	backoff time.Duration, dead bool) error {
+	status := "pending"
+	if dead {
+		status = "dead"
+	}
+
+	_, err := s.db.Exec(ctx, `
+        UPDATE outbox
+        SET status = $2, attempts = attempts + 1, last_error = $3,
+            next_attempt_at = now() + $4 * interval '1 second', locked_until = NULL
+        WHERE id = $1`, id, status, deliveryErr, backoff.Seconds())
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+// scanNotifications decodes the claimed notifications, the errors of the payloads that can't be decoded
+// are returned by the notification id.
+func scanNotifications(rows pgx.Rows) (notifications []scrappertypes.Notification, malformed map[int64]string) {
+	notifications = []scrappertypes.Notification{}
+	malformed = map[int64]string{}
+
+	for rows.Next() {
+		var notification scrappertypes.Notification
+
+		var payload []byte
+
+		err := rows.Scan(&notification.ID, &payload, &notification.Attempts)
+		if err != nil {
+			slog.Error(ErrScanRow.Error(),
+				slog.String("error", err.Error()))
+
+			return notifications, malformed
+		}
+
+		err = json.Unmarshal(payload, &notification.Update)
+		if err != nil {
+			slog.Error("Malformed notification in outbox",
+				slog.Int64("id", notification.ID),
+				slog.String("error", err.Error()))
+
+			malformed[notification.ID] = err.Error()
+
+			continue
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, malformed
+}
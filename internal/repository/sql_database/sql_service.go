@@ -20,7 +20,18 @@ func NewLinkService(typeOfService, dbURL string) (repository.LinkService, error)
 
 	var err error
 
-	pool, err = pgxpool.Connect(context.Background(), dbURL)
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		slog.Error(ErrPoolCreate.Error(),
+			slog.String("error", err.Error()))
+
+		return nil, err
+	}
+
+	poolConfig.ConnConfig.Logger = queryLogger{}
+	poolConfig.ConnConfig.LogLevel = pgx.LogLevelInfo
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), poolConfig)
 	if err != nil {
 		slog.Error(ErrPoolCreate.Error(),
 			slog.String("error", err.Error()),
@@ -63,7 +74,12 @@ func (s *SQLLinkService) DeleteChat(ctx context.Context, id int64) error {
 	return err
 }
 
-func (s *SQLLinkService) AddLink(ctx context.Context, id int64, url string, tags, filters []string) error {
+func (s *SQLLinkService) AddLink(ctx context.Context, id int64, url string, tags, filters []string,
+	lastActivity time.Time) error {
+	if s.IsURLInAdded(ctx, id, url) {
+		return e.ErrLinkAlreadyExists
+	}
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return err
@@ -71,13 +87,8 @@ func (s *SQLLinkService) AddLink(ctx context.Context, id int64, url string, tags
 
 	var linkID int64
 
-	errScan := tx.QueryRow(ctx, `SELECT id FROM links WHERE url = $1`, url).Scan(&linkID)
-	if !errors.Is(errScan, pgx.ErrNoRows) {
-		return e.ErrLinkAlreadyExists
-	}
-
 	_, errQuery := tx.Exec(ctx,
-		"INSERT INTO links (url, changed_at) VALUES ($1, NOW()) ON CONFLICT (url) DO NOTHING", url)
+		"INSERT INTO links (url, changed_at) VALUES ($1, $2) ON CONFLICT (url) DO NOTHING", url, lastActivity)
 	if errQuery != nil {
 		slog.Error("Query Exec error" + errQuery.Error())
 
@@ -95,10 +106,10 @@ func (s *SQLLinkService) AddLink(ctx context.Context, id int64, url string, tags
 	}
 
 	_, err = tx.Exec(ctx,
-		`INSERT INTO link_users (user_id, link_id) 
-				VALUES ((SELECT id FROM users WHERE telegram_id = $1), $2)
+		`INSERT INTO link_users (user_id, link_id, subscribed_at) 
+				VALUES ((SELECT id FROM users WHERE telegram_id = $1), $2, $3)
 				ON CONFLICT DO NOTHING`,
-		id, linkID)
+		id, linkID, lastActivity)
 	if err != nil {
 		slog.Error(ErrExecQuery.Error() + err.Error())
 	}
@@ -292,15 +303,31 @@ func (s *SQLLinkService) GetFilters(ctx context.Context, id int64) map[int64][]s
 	return filtersByID
 }
 
-func (s *SQLLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertypes.LinkResponse, error) {
-	rows, err := s.db.Query(ctx, `
-        SELECT l.id, l.url, l.changed_at
+func (s *SQLLinkService) GetLinks(ctx context.Context, id int64, query scrappertypes.LinksQuery) (scrappertypes.LinksPage, error) {
+	expr, cast, operator, direction := pageOrder(query)
+
+	sql := `
+        SELECT l.id, l.url, l.changed_at, l.broken, l.consecutive_failures, l.last_error, l.last_success_at, lu.added_at
         FROM links l
         JOIN link_users lu ON l.id = lu.link_id
         JOIN users u ON u.id = lu.user_id
-        WHERE u.telegram_id = $1`, id)
+        WHERE u.telegram_id = $1`
+	args := []any{id}
+
+	if query.Cursor != nil {
+		sql += fmt.Sprintf(" AND (%s, l.id) %s ($2::%s, $3)", expr, operator, cast)
+		args = append(args, query.Cursor.Key, query.Cursor.ID)
+	}
+
+	sql += fmt.Sprintf(" ORDER BY %s %s, l.id %s", expr, direction, direction)
+
+	if query.Limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", query.Limit+1)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		return scrappertypes.LinksPage{}, err
 	}
 
 	defer rows.Close()
@@ -308,11 +335,9 @@ func (s *SQLLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertype
 	var links []scrappertypes.LinkResponse
 
 	for rows.Next() {
-		var link scrappertypes.LinkResponse
-
-		err := rows.Scan(&link.ID, &link.URL, &link.LastChecked)
+		link, err := scanLinkWithHealth(rows)
 		if err != nil {
-			return nil, err
+			return scrappertypes.LinksPage{}, err
 		}
 
 		links = append(links, link)
@@ -326,7 +351,7 @@ func (s *SQLLinkService) GetLinks(ctx context.Context, id int64) ([]scrappertype
 		links[i].Filters = filters[links[i].ID]
 	}
 
-	return links, nil
+	return repository.NewLinksPage(links, query), nil
 }
 
 func (s *SQLLinkService) IsURLInAdded(ctx context.Context, id int64, u string) bool {
@@ -349,32 +374,290 @@ func (s *SQLLinkService) IsURLInAdded(ctx context.Context, id int64, u string) b
 	return exists
 }
 
-func (s *SQLLinkService) GetBatchOfLinks(ctx context.Context, batch int, lastID int64) (links []scrappertypes.LinkResponse,
-	lastReturnedID int64) {
+// ClaimLinks leases up to batch due links to owner, skipping links leased by other instances.
+// Links of an instance that died before releasing them are claimed again once their lease expires.
+func (s *SQLLinkService) ClaimLinks(ctx context.Context, owner string, batch int,
+	lease time.Duration) []scrappertypes.LinkResponse {
 	rows, err := s.db.Query(ctx, `
-			SELECT id, url FROM links
-			WHERE id > $1
-			ORDER BY id
-			LIMIT $2`, lastID, batch)
+			UPDATE links l
+			SET locked_until = now() + $3 * interval '1 second', locked_by = $2
+			WHERE l.id IN (
+				SELECT id FROM links
+				WHERE next_check_at <= now() AND NOT broken AND (locked_until IS NULL OR locked_until < now())
+				ORDER BY next_check_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING l.id, l.url, l.check_interval_seconds,
+				COALESCE((SELECT MIN(lu.user_interval_seconds) FROM link_users lu WHERE lu.link_id = l.id), 0)`,
+		batch, owner, int64(lease.Seconds()))
 	if err != nil {
-		return []scrappertypes.LinkResponse{}, lastID
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.LinkResponse{}
 	}
 
-	lastReturnedID = lastID
+	defer rows.Close()
+
+	links := []scrappertypes.LinkResponse{}
 
 	for rows.Next() {
 		var link scrappertypes.LinkResponse
 
-		err := rows.Scan(&link.ID, &link.URL)
+		var checkInterval, userInterval int64
+
+		err := rows.Scan(&link.ID, &link.URL, &checkInterval, &userInterval)
 		if err != nil {
-			return []scrappertypes.LinkResponse{}, lastID
+			slog.Error(ErrScanRow.Error(),
+				slog.String("error", err.Error()))
+
+			return links
 		}
 
+		link.CheckInterval = time.Duration(checkInterval) * time.Second
+		link.UserInterval = time.Duration(userInterval) * time.Second
+
 		links = append(links, link)
-		lastReturnedID = link.ID
 	}
 
-	return links, lastReturnedID
+	return links
+}
+
+// ClaimLink leases one link to owner regardless of its next check time.
+// It returns false if the link does not exist or is leased by another instance.
+func (s *SQLLinkService) ClaimLink(ctx context.Context, owner string, linkID int64,
+	lease time.Duration) (scrappertypes.LinkResponse, bool) {
+	var link scrappertypes.LinkResponse
+
+	var checkInterval, userInterval int64
+
+	err := s.db.QueryRow(ctx, `
+			UPDATE links l
+			SET locked_until = now() + $3 * interval '1 second', locked_by = $2
+			WHERE l.id = $1 AND (l.locked_until IS NULL OR l.locked_until < now())
+			RETURNING l.id, l.url, l.check_interval_seconds,
+				COALESCE((SELECT MIN(lu.user_interval_seconds) FROM link_users lu WHERE lu.link_id = l.id), 0)`,
+		linkID, owner, int64(lease.Seconds())).Scan(&link.ID, &link.URL, &checkInterval, &userInterval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return link, false
+	} else if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return link, false
+	}
+
+	link.CheckInterval = time.Duration(checkInterval) * time.Second
+	link.UserInterval = time.Duration(userInterval) * time.Second
+
+	return link, true
+}
+
+func (s *SQLLinkService) SaveCheckSuccess(ctx context.Context, linkID int64) error {
+	_, err := s.db.Exec(ctx, `
+        UPDATE links
+        SET consecutive_failures = 0, last_error = NULL, last_success_at = now()
+        WHERE id = $1`, linkID)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
+
+	return err
+}
+
+// SaveCheckFailure records a failed check. The link becomes broken when disable is set
+// or when it failed threshold times in a row. It reports whether the link has just become broken.
+func (s *SQLLinkService) SaveCheckFailure(ctx context.Context, linkID int64, checkErr string, disable bool,
+	threshold int) (bool, error) {
+	var becameBroken bool
+
+	err := s.db.QueryRow(ctx, `
+        WITH prev AS (SELECT broken FROM links WHERE id = $1)
+        UPDATE links
+        SET consecutive_failures = consecutive_failures + 1, last_error = $2,
+            broken = broken OR $3 OR consecutive_failures + 1 >= $4
+        WHERE id = $1
+        RETURNING broken AND NOT (SELECT broken FROM prev)`, linkID, checkErr, disable, threshold).Scan(&becameBroken)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return false, err
+	}
+
+	return becameBroken, nil
+}
+
+// EnableLink clears the broken state of a link the chat is subscribed to and makes it due for a check.
+func (s *SQLLinkService) EnableLink(ctx context.Context, id int64, link string) error {
+	res, err := s.db.Exec(ctx, `
+        UPDATE links
+        SET broken = false, consecutive_failures = 0, next_check_at = now()
+        WHERE url = $2 AND id IN (
+            SELECT lu.link_id FROM link_users lu
+            JOIN users u ON u.id = lu.user_id
+            WHERE u.telegram_id = $1
+        )`, id, link)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// UpdateLink adds and removes tags and filters of the link the chat is subscribed to.
+func (s *SQLLinkService) UpdateLink(ctx context.Context, id int64, link string, change scrappertypes.LinkChange) (
+	scrappertypes.LinkResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var linkID int64
+
+	err = tx.QueryRow(ctx, `
+        SELECT lu.link_id FROM link_users lu
+        JOIN users u ON u.id = lu.user_id
+        JOIN links l ON l.id = lu.link_id
+        WHERE u.telegram_id = $1 AND l.url = $2`, id, link).Scan(&linkID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scrappertypes.LinkResponse{}, e.ErrLinkNotFound
+	} else if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	for _, removal := range []struct {
+		query string
+		names []string
+	}{
+		{`DELETE FROM link_tags
+            WHERE link_id = $1 AND user_id = (SELECT id FROM users WHERE telegram_id = $2)
+            AND tag_id IN (SELECT id FROM tags WHERE name = ANY($3))`, change.RemoveTags},
+		{`DELETE FROM link_filters
+            WHERE link_id = $1 AND user_id = (SELECT id FROM users WHERE telegram_id = $2)
+            AND filter_id IN (SELECT id FROM filters WHERE name = ANY($3))`, change.RemoveFilters},
+	} {
+		if len(removal.names) == 0 {
+			continue
+		}
+
+		if _, err = tx.Exec(ctx, removal.query, linkID, id, removal.names); err != nil {
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
+
+			return scrappertypes.LinkResponse{}, err
+		}
+	}
+
+	if err = s.saveTags(ctx, tx, id, linkID, change.AddTags); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	if err = s.saveFilters(ctx, tx, id, linkID, change.AddFilters); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return scrappertypes.LinkResponse{}, err
+	}
+
+	return scrappertypes.LinkResponse{
+		ID:      linkID,
+		URL:     link,
+		Tags:    s.GetTags(ctx, id)[linkID],
+		Filters: s.GetFilters(ctx, id)[linkID],
+	}, nil
+}
+
+// ReleaseLink drops the lease of owner on the link and schedules its next check.
+func (s *SQLLinkService) ReleaseLink(ctx context.Context, owner string, linkID int64, interval time.Duration) error {
+	res, err := s.db.Exec(ctx, `
+        UPDATE links
+        SET check_interval_seconds = $1, next_check_at = now() + $1 * interval '1 second',
+            locked_until = NULL, locked_by = NULL
+        WHERE id = $2 AND locked_by = $3`, int64(interval.Seconds()), linkID, owner)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (s *SQLLinkService) ExtendLease(ctx context.Context, owner string, linkID int64, lease time.Duration) error {
+	res, err := s.db.Exec(ctx, `
+        UPDATE links
+        SET locked_until = now() + $1 * interval '1 second'
+        WHERE id = $2 AND locked_by = $3`, int64(lease.Seconds()), linkID, owner)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (s *SQLLinkService) SetCheckInterval(ctx context.Context, id int64, link string, interval time.Duration) error {
+	seconds := int64(interval.Seconds())
+
+	res, err := s.db.Exec(ctx, `
+        UPDATE link_users
+        SET user_interval_seconds = NULLIF($1, 0)
+        WHERE user_id = (SELECT id FROM users WHERE telegram_id = $2)
+        AND link_id = (SELECT id FROM links WHERE url = $3)`, seconds, id, link)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return e.ErrLinkNotFound
+	}
+
+	if seconds == 0 {
+		return nil
+	}
+
+	_, err = s.db.Exec(ctx, `
+        UPDATE links
+        SET next_check_at = LEAST(next_check_at, now() + $1 * interval '1 second')
+        WHERE url = $2`, seconds, link)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
+
+	return err
 }
 
 func (s *SQLLinkService) GetPreviousUpdate(ctx context.Context, id int64) time.Time {
@@ -429,6 +712,37 @@ func (s *SQLLinkService) GetTgChatIDsForLink(ctx context.Context, link string) [
 	return tgIDs
 }
 
+func (s *SQLLinkService) GetSubscribers(ctx context.Context, linkID int64) []scrappertypes.Subscriber {
+	rows, err := s.db.Query(ctx, `
+        SELECT u.telegram_id, lu.subscribed_at
+        FROM users u
+        JOIN link_users lu ON u.id = lu.user_id
+        WHERE lu.link_id = $1`, linkID)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return []scrappertypes.Subscriber{}
+	}
+
+	defer rows.Close()
+
+	var subscribers []scrappertypes.Subscriber
+
+	for rows.Next() {
+		var subscriber scrappertypes.Subscriber
+
+		err := rows.Scan(&subscriber.TgChatID, &subscriber.SubscribedAt)
+		if err != nil {
+			return []scrappertypes.Subscriber{}
+		}
+
+		subscribers = append(subscribers, subscriber)
+	}
+
+	return subscribers
+}
+
 func (s *SQLLinkService) DeleteTag(ctx context.Context, id int64, tag string) error {
 	res, err := s.db.Exec(ctx, `
         DELETE FROM link_tags
@@ -449,6 +763,143 @@ func (s *SQLLinkService) DeleteTag(ctx context.Context, id int64, tag string) er
 	return nil
 }
 
+// ListTags counts the links of the chat that have each of its tags.
+func (s *SQLLinkService) ListTags(ctx context.Context, id int64) ([]scrappertypes.TagResponse, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT t.name, COUNT(*)
+        FROM link_tags lt
+        JOIN tags t ON t.id = lt.tag_id
+        JOIN users u ON u.id = lt.user_id
+        WHERE u.telegram_id = $1
+        GROUP BY t.name
+        ORDER BY COUNT(*) DESC, t.name`, id)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []scrappertypes.TagResponse{}
+
+	for rows.Next() {
+		var tag scrappertypes.TagResponse
+
+		if err := rows.Scan(&tag.Name, &tag.Links); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// RenameTag moves the links of the chat from tag to newName in one transaction.
+func (s *SQLLinkService) RenameTag(ctx context.Context, id int64, tag, newName string, merge bool) (
+	scrappertypes.TagResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	moved, err := countTagLinks(ctx, tx, id, tag)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	target, err := countTagLinks(ctx, tx, id, newName)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	if err = checkRename(moved, target, merge); err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	for _, step := range []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, []any{newName}},
+		{`INSERT INTO link_tags (link_id, tag_id, user_id)
+            SELECT lt.link_id, (SELECT id FROM tags WHERE name = $3), lt.user_id
+            FROM link_tags lt
+            WHERE lt.user_id = (SELECT id FROM users WHERE telegram_id = $1)
+            AND lt.tag_id = (SELECT id FROM tags WHERE name = $2)
+            ON CONFLICT DO NOTHING`, []any{id, tag, newName}},
+		{`DELETE FROM link_tags
+            WHERE user_id = (SELECT id FROM users WHERE telegram_id = $1)
+            AND tag_id = (SELECT id FROM tags WHERE name = $2)`, []any{id, tag}},
+	} {
+		if _, err = tx.Exec(ctx, step.query, step.args...); err != nil {
+			slog.Error(ErrExecQuery.Error(),
+				slog.String("error", err.Error()))
+
+			return scrappertypes.TagResponse{}, err
+		}
+	}
+
+	links, err := countTagLinks(ctx, tx, id, newName)
+	if err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return scrappertypes.TagResponse{}, err
+	}
+
+	return scrappertypes.TagResponse{Name: newName, Links: links}, nil
+}
+
+// countTagLinks counts the links of the chat that have tag.
+func countTagLinks(ctx context.Context, tx pgx.Tx, id int64, tag string) (int, error) {
+	var links int
+
+	err := tx.QueryRow(ctx, `
+        SELECT COUNT(*)
+        FROM link_tags lt
+        JOIN tags t ON t.id = lt.tag_id
+        JOIN users u ON u.id = lt.user_id
+        WHERE u.telegram_id = $1 AND t.name = $2`, id, tag).Scan(&links)
+	if err != nil {
+		slog.Error(ErrExecQuery.Error(),
+			slog.String("error", err.Error()))
+	}
+
+	return links, err
+}
+
+// Ping checks that a connection to the database can be acquired.
+func (s *SQLLinkService) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
 func (s *SQLLinkService) Close() {
 	s.db.Close()
 }
+
+// scanLinkWithHealth scans id, url, changed_at, the health columns of a link and the time the chat added it at.
+func scanLinkWithHealth(rows pgx.Rows) (scrappertypes.LinkResponse, error) {
+	var link scrappertypes.LinkResponse
+
+	var lastError *string
+
+	err := rows.Scan(&link.ID, &link.URL, &link.LastChecked, &link.Broken, &link.ConsecutiveFailures,
+		&lastError, &link.LastSuccessAt, &link.AddedAt)
+	if err != nil {
+		return link, err
+	}
+
+	if lastError != nil {
+		link.LastError = *lastError
+	}
+
+	return link, nil
+}
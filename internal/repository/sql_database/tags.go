@@ -0,0 +1,18 @@
+package repository
+
+import "go-progira/pkg/e"
+
+// checkRename tells if the links of a tag can be moved to the target one, given how many links of the chat have them.
+// A rename needs a target the chat doesn't use, a merge needs one it does.
+func checkRename(moved, target int, merge bool) error {
+	switch {
+	case moved == 0:
+		return e.ErrTagNotFound
+	case merge && target == 0:
+		return e.ErrTagNotFound
+	case !merge && target != 0:
+		return e.ErrTagAlreadyExists
+	default:
+		return nil
+	}
+}
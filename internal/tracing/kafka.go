@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+)
+
+// messageCarrier lets the propagator read and write the headers of a Kafka message.
+type messageCarrier struct {
+	msg *kafka.Message
+}
+
+func (c messageCarrier) Get(key string) string {
+	for _, header := range c.msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+
+	return ""
+}
+
+func (c messageCarrier) Set(key, value string) {
+	for i, header := range c.msg.Headers {
+		if header.Key == key {
+			c.msg.Headers[i].Value = []byte(value)
+
+			return
+		}
+	}
+
+	c.msg.Headers = append(c.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c messageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+
+	for _, header := range c.msg.Headers {
+		keys = append(keys, header.Key)
+	}
+
+	return keys
+}
+
+// InjectMessage puts the trace context of ctx into the headers of the message.
+func InjectMessage(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, messageCarrier{msg: msg})
+}
+
+// ExtractMessage returns ctx carrying the trace context found in the headers of the message.
+func ExtractMessage(ctx context.Context, msg *kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, messageCarrier{msg: msg})
+}
@@ -0,0 +1,117 @@
+// Package tracing sets up OpenTelemetry for the scrapper and the bot.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs the W3C trace context propagator and, if endpoint is set, a tracer provider
+// that exports spans to it over OTLP/HTTP. Without an endpoint the global no-op tracer stays in place,
+// so spans cost next to nothing. The returned function flushes the spans that are left.
+func Setup(ctx context.Context, service, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(service)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the given package.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Middleware starts a server span for every request and continues the trace of the caller.
+// The span is named after the route the request matched.
+func Middleware(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		if r.Pattern != "" {
+			_, route, hasMethod := strings.Cut(r.Pattern, " ")
+			if !hasMethod {
+				route = r.Pattern
+			}
+
+			trace.SpanFromContext(r.Context()).SetName(r.Method + " " + route)
+		}
+	})
+
+	return otelhttp.NewHandler(named, "http.server")
+}
+
+// StartRequest starts a client span for the request and puts the trace context into its headers.
+// Only the method and the host are recorded, since paths and queries of provider
+// and Telegram requests hold API keys.
+func StartRequest(req *http.Request, name string) (*http.Request, trace.Span) {
+	ctx, span := Tracer("go-progira/http").Start(req.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return req, span
+}
+
+// FinishRequest records the outcome of the request and ends its span.
+// The URL is dropped from err for the same reason as in StartRequest.
+func FinishRequest(span trace.Span, resp *http.Response, err error) {
+	defer span.End()
+
+	if err != nil {
+		var errURL *url.Error
+		if errors.As(err, &errURL) {
+			err = fmt.Errorf("%s: %w", errURL.Op, errURL.Err)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+}
@@ -0,0 +1,129 @@
+package tracing_test
+
+import (
+	"context"
+	"go-progira/internal/tracing"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	_, err := tracing.Setup(context.Background(), "test", "")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	return recorder
+}
+
+func TestRequestPropagation(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/links/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(tracing.Middleware(mux))
+	defer server.Close()
+
+	ctx, parent := tracing.Tracer("test").Start(context.Background(), "command")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/links/1?key=secret", http.NoBody)
+	require.NoError(t, err)
+
+	req, span := tracing.StartRequest(req, "scrapper")
+	resp, err := http.DefaultClient.Do(req)
+	tracing.FinishRequest(span, resp, err)
+
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	require.Contains(t, spans, "GET /links/{id}", "server span must be named after the route")
+	require.Contains(t, spans, "scrapper")
+
+	traceID := parent.SpanContext().TraceID()
+	assert.Equal(t, traceID, spans["scrapper"].SpanContext().TraceID())
+	assert.Equal(t, traceID, spans["GET /links/{id}"].SpanContext().TraceID(), "server must continue the trace")
+	assert.Equal(t, spans["scrapper"].SpanContext().SpanID(), spans["GET /links/{id}"].Parent().SpanID())
+
+	for _, attr := range spans["scrapper"].Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "secret", "client span must not record the query")
+	}
+}
+
+func TestFinishRequest_RedactsURL(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		server.URL+"/botTOKEN/sendMessage?text=hi&key=secret", http.NoBody)
+	require.NoError(t, err)
+
+	req, span := tracing.StartRequest(req, "telegram")
+	resp, err := http.DefaultClient.Do(req)
+	tracing.FinishRequest(span, resp, err)
+
+	require.Error(t, err)
+	require.Len(t, recorder.Ended(), 1)
+
+	ended := recorder.Ended()[0]
+	recorded := []string{ended.Status().Description}
+
+	for _, event := range ended.Events() {
+		for _, attr := range event.Attributes {
+			recorded = append(recorded, attr.Value.Emit())
+		}
+	}
+
+	assert.Equal(t, codes.Error, ended.Status().Code)
+
+	for _, value := range recorded {
+		assert.NotContains(t, value, "botTOKEN", "span must not record the path")
+		assert.NotContains(t, value, "secret", "span must not record the query")
+	}
+}
+
+func TestMessagePropagation(t *testing.T) {
+	setupRecorder(t)
+
+	ctx, span := tracing.Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+
+	msg := kafka.Message{Value: []byte("{}")}
+	tracing.InjectMessage(ctx, &msg)
+
+	extracted := trace.SpanContextFromContext(tracing.ExtractMessage(context.Background(), &msg))
+
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+}
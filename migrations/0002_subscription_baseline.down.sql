@@ -0,0 +1 @@
+ALTER TABLE link_users DROP COLUMN IF EXISTS subscribed_at;
@@ -0,0 +1,3 @@
+-- Provider's cursor of the link at the moment the user subscribed to it.
+-- Only events created after it are sent to the subscriber.
+ALTER TABLE link_users ADD COLUMN subscribed_at TIMESTAMP NOT NULL DEFAULT now();
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_links_next_check_at;
+
+ALTER TABLE link_users DROP COLUMN IF EXISTS user_interval_seconds;
+
+ALTER TABLE links DROP COLUMN IF EXISTS next_check_at;
+ALTER TABLE links DROP COLUMN IF EXISTS check_interval_seconds;
@@ -0,0 +1,7 @@
+ALTER TABLE links ADD COLUMN check_interval_seconds INT NOT NULL DEFAULT 120;
+ALTER TABLE links ADD COLUMN next_check_at TIMESTAMP NOT NULL DEFAULT now();
+
+-- Interval explicitly requested by the subscriber, NULL means adaptive polling.
+ALTER TABLE link_users ADD COLUMN user_interval_seconds INT;
+
+CREATE INDEX IF NOT EXISTS idx_links_next_check_at ON links(next_check_at);
@@ -0,0 +1,2 @@
+ALTER TABLE links DROP COLUMN IF EXISTS locked_by;
+ALTER TABLE links DROP COLUMN IF EXISTS locked_until;
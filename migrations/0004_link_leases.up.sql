@@ -0,0 +1,3 @@
+-- Lease held by the scrapper instance that is checking the link, expired leases are claimed again.
+ALTER TABLE links ADD COLUMN locked_until TIMESTAMP;
+ALTER TABLE links ADD COLUMN locked_by TEXT;
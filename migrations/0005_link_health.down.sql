@@ -0,0 +1,4 @@
+ALTER TABLE links DROP COLUMN IF EXISTS broken;
+ALTER TABLE links DROP COLUMN IF EXISTS last_success_at;
+ALTER TABLE links DROP COLUMN IF EXISTS last_error;
+ALTER TABLE links DROP COLUMN IF EXISTS consecutive_failures;
@@ -0,0 +1,5 @@
+-- Health of the link: broken links are not polled until a subscriber enables them again.
+ALTER TABLE links ADD COLUMN consecutive_failures INT NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN last_error TEXT;
+ALTER TABLE links ADD COLUMN last_success_at TIMESTAMP;
+ALTER TABLE links ADD COLUMN broken BOOLEAN NOT NULL DEFAULT false;
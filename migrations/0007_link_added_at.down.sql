@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_link_users_user_added_at;
+
+ALTER TABLE link_users DROP COLUMN IF EXISTS added_at;
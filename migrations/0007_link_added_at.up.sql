@@ -0,0 +1,5 @@
+-- Moment the user started tracking the link, links of a chat are listed in this order by default.
+-- Links added before the migration get the time of the migration.
+ALTER TABLE link_users ADD COLUMN added_at TIMESTAMP NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_link_users_user_added_at ON link_users(user_id, added_at, link_id);
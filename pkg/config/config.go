@@ -8,10 +8,19 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Transports the scrapper can deliver link updates to the bot with.
+// The bot calls the scrapper over HTTP or gRPC.
+const (
+	TransportHTTP  = "http"
+	TransportKafka = "kafka"
+	TransportGRPC  = "grpc"
+)
+
 type Config struct {
 	TgAPIToken          string
 	StackoverflowAPIKey string
@@ -19,10 +28,51 @@ type Config struct {
 	TgBotHost           string
 	BotHost             string
 	ScrapperHost        string
+	// ScrapperGRPCHost is where the scrapper serves gRPC next to HTTP.
+	ScrapperGRPCHost string
+	// ScrapperAPI is the API the bot calls the scrapper with.
+	ScrapperAPI         string
 	DatabaseURL         string
 	LinkService         string
 	Batch               int
 	Workers             int
+	MinCheckInterval    time.Duration
+	MaxCheckInterval    time.Duration
+	CheckInterval       time.Duration
+	SchedulerInterval   time.Duration
+	SchedulerCron       string
+	InstanceID          string
+	LinkLease           time.Duration
+	HostConcurrency     map[string]int
+	ShutdownTimeout     time.Duration
+	RefreshCooldown     time.Duration
+	RefreshTimeout      time.Duration
+	ImportTimeout       time.Duration
+	BrokenLinkThreshold int
+	OutboxInterval      time.Duration
+	OutboxBaseBackoff   time.Duration
+	OutboxMaxBackoff    time.Duration
+	OutboxMaxAttempts   int
+	BotTransport        string
+	KafkaBrokers        []string
+	KafkaUpdatesTopic   string
+	KafkaDLQTopic       string
+	KafkaConsumerGroup  string
+	// BotFallbackTransport enables switching to another transport when the primary one keeps failing.
+	BotFallbackTransport     string
+	FallbackFailureThreshold int
+	FallbackProbeInterval    time.Duration
+	BotBatchSize             int
+	BotRequestTimeout        time.Duration
+	// OTLPEndpoint is where spans are exported to, tracing is off when it is empty.
+	OTLPEndpoint string
+	// ShutdownDrainDelay is how long a service keeps serving with failing readiness before it stops.
+	ShutdownDrainDelay time.Duration
+	// TgPollStaleAfter is how old the last successful getUpdates may be for the bot to stay ready.
+	TgPollStaleAfter time.Duration
+	// ServiceSecret signs the requests between the bot and the scrapper.
+	ServiceSecret   string
+	SignatureMaxAge time.Duration
 }
 
 func LoadConfig() (Config, error) {
@@ -79,15 +129,188 @@ func LoadConfig() (Config, error) {
 		TgBotHost:           get("TELEGRAM_BOT_HOST"),
 		BotHost:             get("BOT_HOST"),
 		ScrapperHost:        get("SCRAPPER_HOST"),
+		ScrapperGRPCHost:    getString("SCRAPPER_GRPC_HOST", "localhost:9090"),
+		ScrapperAPI:         getString("SCRAPPER_API", TransportHTTP),
 		DatabaseURL:         get("DATABASE_URL"),
 		LinkService:         get("LINK_SERVICE"),
 		Batch:               batch,
 		Workers:             numOfWorkers,
+		MinCheckInterval:    getDuration("MIN_CHECK_INTERVAL", time.Minute),
+		MaxCheckInterval:    getDuration("MAX_CHECK_INTERVAL", 24*time.Hour),
+		CheckInterval:       getDuration("CHECK_INTERVAL", 2*time.Minute),
+		SchedulerInterval:   getDuration("SCHEDULER_INTERVAL", 2*time.Minute),
+		SchedulerCron:       os.Getenv("SCHEDULER_CRON"),
+		InstanceID:          getInstanceID(),
+		LinkLease:           getDuration("LINK_LEASE", 5*time.Minute),
+		HostConcurrency:     getLimits("HOST_CONCURRENCY"),
+		ShutdownTimeout:     getDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		RefreshCooldown:     getDuration("REFRESH_COOLDOWN", time.Minute),
+		RefreshTimeout:      getDuration("REFRESH_TIMEOUT", 8*time.Second),
+		ImportTimeout:       getDuration("IMPORT_TIMEOUT", 8*time.Second),
+		BrokenLinkThreshold: getInt("BROKEN_LINK_THRESHOLD", 5),
+		OutboxInterval:      getDuration("OUTBOX_INTERVAL", 5*time.Second),
+		OutboxBaseBackoff:   getDuration("OUTBOX_BASE_BACKOFF", 5*time.Second),
+		OutboxMaxBackoff:    getDuration("OUTBOX_MAX_BACKOFF", time.Hour),
+		OutboxMaxAttempts:   getInt("OUTBOX_MAX_ATTEMPTS", 10),
+		BotTransport:        getString("BOT_TRANSPORT", TransportHTTP),
+		KafkaBrokers:        getList("KAFKA_BROKERS"),
+		KafkaUpdatesTopic:   getString("KAFKA_UPDATES_TOPIC", "link-updates"),
+		KafkaDLQTopic:       getString("KAFKA_DLQ_TOPIC", "link-updates-dlq"),
+		KafkaConsumerGroup:  getString("KAFKA_CONSUMER_GROUP", "bot"),
+
+		BotFallbackTransport:     os.Getenv("BOT_FALLBACK_TRANSPORT"),
+		FallbackFailureThreshold: getInt("FALLBACK_FAILURE_THRESHOLD", 3),
+		FallbackProbeInterval:    getDuration("FALLBACK_PROBE_INTERVAL", 30*time.Second),
+		BotBatchSize:             getInt("BOT_BATCH_SIZE", 50),
+		BotRequestTimeout:        getDuration("BOT_REQUEST_TIMEOUT", 10*time.Second),
+		OTLPEndpoint:             os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		ShutdownDrainDelay:       getDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		TgPollStaleAfter:         getDuration("TG_POLL_STALE_AFTER", time.Minute),
+		ServiceSecret:            get("SERVICE_SECRET"),
+		SignatureMaxAge:          getDuration("SIGNATURE_MAX_AGE", 5*time.Minute),
 	}
 
+	errs = append(errs, config.transportErrors()...)
+
 	if len(errs) > 0 {
 		return Config{}, fmt.Errorf("config errors:\n%s", strings.Join(errs, "\n"))
 	}
 
 	return config, nil
 }
+
+// transportErrors checks the transports the scrapper delivers updates to the bot with
+// and the API the bot calls the scrapper with.
+func (c *Config) transportErrors() []string {
+	var errs []string
+
+	switch c.BotTransport {
+	case TransportHTTP, TransportKafka, TransportGRPC:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown BOT_TRANSPORT: %s", c.BotTransport))
+	}
+
+	switch c.BotFallbackTransport {
+	case "":
+	case c.BotTransport:
+		errs = append(errs, "BOT_FALLBACK_TRANSPORT must differ from BOT_TRANSPORT")
+	case TransportHTTP, TransportKafka, TransportGRPC:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown BOT_FALLBACK_TRANSPORT: %s", c.BotFallbackTransport))
+	}
+
+	if c.ScrapperAPI != TransportHTTP && c.ScrapperAPI != TransportGRPC {
+		errs = append(errs, fmt.Sprintf("unknown SCRAPPER_API: %s", c.ScrapperAPI))
+	}
+
+	usesKafka := c.BotTransport == TransportKafka || c.BotFallbackTransport == TransportKafka
+	if usesKafka && len(c.KafkaBrokers) == 0 {
+		errs = append(errs, "missing env: KAFKA_BROKERS")
+	}
+
+	return errs
+}
+
+// getString reads an optional string, falling back to def.
+func getString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+
+	return def
+}
+
+// getList reads an optional comma-separated list such as "kafka-1:9092,kafka-2:9092".
+func getList(key string) []string {
+	var list []string
+
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// getDuration reads an optional duration such as "90s" or "10m", falling back to def.
+func getDuration(key string, def time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		slog.Error("Invalid duration in env, using default",
+			slog.String("key", key),
+			slog.String("value", val),
+			slog.String("default", def.String()))
+
+		return def
+	}
+
+	return d
+}
+
+// getInt reads an optional positive integer, falling back to def.
+func getInt(key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		slog.Error("Invalid number in env, using default",
+			slog.String("key", key),
+			slog.String("value", val),
+			slog.Int("default", def))
+
+		return def
+	}
+
+	return n
+}
+
+// getInstanceID returns INSTANCE_ID or, if it is not set, an id built from the host name and pid,
+// so that replicas of the scrapper can tell their link leases apart.
+func getInstanceID() string {
+	if id := os.Getenv("INSTANCE_ID"); id != "" {
+		return id
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "scrapper"
+	}
+
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// getLimits reads optional per-host limits such as "github.com=4,stackoverflow.com=2".
+func getLimits(key string) map[string]int {
+	limits := make(map[string]int)
+
+	val := os.Getenv(key)
+	if val == "" {
+		return limits
+	}
+
+	for _, pair := range strings.Split(val, ",") {
+		host, limitStr, ok := strings.Cut(strings.TrimSpace(pair), "=")
+
+		limit, err := strconv.Atoi(limitStr)
+		if !ok || err != nil || limit <= 0 {
+			slog.Error("Invalid limit in env, ignoring it",
+				slog.String("key", key),
+				slog.String("value", pair))
+
+			continue
+		}
+
+		limits[strings.ToLower(strings.TrimSpace(host))] = limit
+	}
+
+	return limits
+}
@@ -15,6 +15,7 @@ var (
 	ErrDoRequest        = errors.New("error doing request")
 	ErrWrongURLFormat   = errors.New("URL this form does not require")
 	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrInvalidRequest   = errors.New("invalid request")
 
 	ErrMarshalJSON    = errors.New("error marshaling json")
 	ErrDecodeJSONBody = errors.New("error decoding json body")
@@ -25,19 +26,54 @@ var (
 	ErrAPI                  = errors.New("API returned error")
 	ErrReadBody             = errors.New("read body error")
 	ErrCloseBody            = errors.New("close body error")
+	ErrDownloadFile         = errors.New("error downloading file")
+	ErrTelegramAPI          = errors.New("telegram API rejected the request")
 
 	ErrWrite        = errors.New("write error")
 	ErrServerFailed = errors.New("server failed")
 	ErrScheduler    = errors.New("scheduler error")
+	ErrNotRunning   = errors.New("scheduler is not running")
+	ErrUnhealthy    = errors.New("service is unhealthy")
 
 	ErrChatNotFound      = errors.New("chat not found")
-	ErrChatAlreadyExists = errors.New("link already exists")
+	ErrChatAlreadyExists = errors.New("chat already exists")
 	ErrLinkNotFound      = errors.New("link not found")
 	ErrLinkAlreadyExists = errors.New("link already exists")
 
-	ErrTagNotFound = errors.New("tag not found")
-	ErrDeleteTag   = errors.New("error deleting tag")
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagAlreadyExists = errors.New("tag already exists")
+	ErrDeleteTag        = errors.New("error deleting tag")
+	ErrGetTags          = errors.New("error getting tags")
+	ErrRenameTag        = errors.New("error renaming tag")
 
 	ErrAddLink    = errors.New("error adding link")
-	ErrDeleteLink = errors.New("error adding link")
+	ErrDeleteLink = errors.New("error deleting link")
+	ErrUpdateLink = errors.New("error updating link")
+	ErrExport     = errors.New("error exporting links")
+	ErrImport     = errors.New("error importing links")
+
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrResourcePrivate  = errors.New("resource is private or not accessible")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+
+	ErrProviderUnavailable = errors.New("provider is unavailable")
+
+	ErrLeaseLost = errors.New("lease on link expired and was taken by another instance")
+
+	ErrRefreshTooOften = errors.New("links are refreshed too often")
+	ErrRefresh         = errors.New("error refreshing links")
+	ErrEnableLink      = errors.New("error enabling link")
+
+	ErrPublish          = errors.New("error publishing message")
+	ErrConsume          = errors.New("error consuming message")
+	ErrMalformedMsg     = errors.New("malformed message")
+	ErrUnknownTransport = errors.New("unknown transport")
+	ErrFallbackDisabled = errors.New("fallback transport is disabled")
+	ErrNotConnected     = errors.New("bot is not connected to the updates stream")
+
+	ErrUnsigned     = errors.New("request is not signed")
+	ErrBadSignature = errors.New("request signature is invalid")
+	ErrStaleRequest = errors.New("request timestamp is too far from now")
+	ErrUnauthorized = errors.New("request was rejected as unauthorized")
+	ErrBodyTooLarge = errors.New("request body is too large")
 )